@@ -0,0 +1,11 @@
+package node_deployer
+
+import "embed"
+
+// Assets holds the start scripts and service definitions source, binary and
+// validator deployments copy to their hosts. Their paths are relative to the
+// root of the repository, programs run outside of it, like the node-deployer
+// command, write Assets to the directory the providers run in.
+//
+//go:embed scripts config
+var Assets embed.FS
@@ -0,0 +1,209 @@
+// Command node-deployer deploys the nodes described in a fleet spec file with
+// the Pulumi Automation API, so no Pulumi program has to be written.
+//
+// Usage:
+//
+//	node-deployer <preview|up|destroy|refresh|outputs> [flags]
+//
+// The stack state is kept in a local file backend. Stack secrets are encrypted
+// with the passphrase in PULUMI_CONFIG_PASSPHRASE.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	node_deployer "github.com/rswanson/node_deployer"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/auto"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optdestroy"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optpreview"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optrefresh"
+	"github.com/pulumi/pulumi/sdk/v3/go/auto/optup"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+const usage = `usage: node-deployer <command> [flags]
+
+commands:
+  preview   show the changes up would make
+  up        create or update the nodes in the fleet spec
+  destroy   delete every resource of the stack
+  refresh   update the stack state from the deployed resources
+  outputs   print the stack outputs as JSON
+
+flags:
+`
+
+var commands = map[string]bool{"preview": true, "up": true, "destroy": true, "refresh": true, "outputs": true}
+
+// options holds the parsed command line.
+type options struct {
+	command string
+	spec    string
+	stack   string
+	project string
+	backend string
+}
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "node-deployer:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	opts, err := parseArgs(args, stderr)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(opts.backend, 0o700); err != nil {
+		return err
+	}
+
+	// the providers resolve the scripts and service definitions copied to
+	// hosts against the work directory
+	workDir, err := writeAssets()
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	stack, err := auto.UpsertStackInlineSource(ctx, opts.stack, opts.project, program(opts.spec),
+		auto.WorkDir(workDir),
+		auto.Project(workspace.Project{
+			Name:    tokens.PackageName(opts.project),
+			Runtime: workspace.NewProjectRuntimeInfo("go", nil),
+			Backend: &workspace.ProjectBackend{URL: "file://" + opts.backend},
+		}),
+		auto.SecretsProvider("passphrase"),
+	)
+	if err != nil {
+		return err
+	}
+
+	switch opts.command {
+	case "preview":
+		_, err = stack.Preview(ctx, optpreview.ProgressStreams(stdout))
+	case "up":
+		_, err = stack.Up(ctx, optup.ProgressStreams(stdout))
+	case "destroy":
+		_, err = stack.Destroy(ctx, optdestroy.ProgressStreams(stdout))
+	case "refresh":
+		_, err = stack.Refresh(ctx, optrefresh.ProgressStreams(stdout))
+	case "outputs":
+		err = printOutputs(ctx, stack, stdout)
+	}
+	return err
+}
+
+// parseArgs parses the command and its flags.
+func parseArgs(args []string, stderr io.Writer) (*options, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	flags := flag.NewFlagSet("node-deployer", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+
+	opts := &options{}
+	flags.StringVar(&opts.spec, "spec", "fleet.yaml", "path of the fleet spec file")
+	flags.StringVar(&opts.stack, "stack", "dev", "name of the stack")
+	flags.StringVar(&opts.project, "project", "node-deployer", "name of the pulumi project")
+	flags.StringVar(&opts.backend, "backend", filepath.Join(home, ".node-deployer"), "directory of the local state backend")
+
+	if len(args) == 0 || !commands[args[0]] {
+		flags.Usage()
+		return nil, errors.New("missing or unknown command")
+	}
+	opts.command = args[0]
+	if err := flags.Parse(args[1:]); err != nil {
+		return nil, err
+	}
+
+	opts.backend, err = filepath.Abs(opts.backend)
+	if err != nil {
+		return nil, err
+	}
+	// the program does not run in the working directory of the command
+	opts.spec, err = filepath.Abs(opts.spec)
+	if err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// writeAssets writes the embedded scripts and service definitions to a new
+// temporary directory and returns it.
+func writeAssets() (string, error) {
+	dir, err := os.MkdirTemp("", "node-deployer-")
+	if err != nil {
+		return "", err
+	}
+	if err := os.CopyFS(dir, node_deployer.Assets); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// program returns the inline pulumi program deploying the fleet spec at specPath.
+// The spec is loaded when the program runs so it is validated by every command.
+func program(specPath string) pulumi.RunFunc {
+	return func(ctx *pulumi.Context) error {
+		spec, err := node_deployer.LoadFleetSpec(specPath)
+		if err != nil {
+			return err
+		}
+
+		fleets, err := node_deployer.DeployFleetSpec(ctx, spec)
+		if err != nil {
+			return err
+		}
+
+		for name, fleet := range fleets {
+			ctx.Export(name+":httpRpcUrls", fleet.HttpRpcUrls)
+			ctx.Export(name+":wsRpcUrls", fleet.WsRpcUrls)
+			ctx.Export(name+":engineApiUrls", fleet.EngineApiUrls)
+			ctx.Export(name+":enodes", fleet.Enodes)
+			ctx.Export(name+":beaconApiUrls", fleet.BeaconApiUrls)
+			ctx.Export(name+":enrs", fleet.Enrs)
+		}
+		return nil
+	}
+}
+
+// printOutputs writes the stack outputs as a JSON object, secrets are masked.
+func printOutputs(ctx context.Context, stack auto.Stack, stdout io.Writer) error {
+	outputs, err := stack.Outputs(ctx)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]any, len(outputs))
+	for key, output := range outputs {
+		values[key] = output.Value
+		if output.Secret {
+			values[key] = "[secret]"
+		}
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(values)
+}
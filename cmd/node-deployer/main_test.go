@@ -0,0 +1,78 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+)
+
+// mocks records the inputs of every resource by name.
+type mocks struct {
+	mu     sync.Mutex
+	inputs map[string]resource.PropertyMap
+}
+
+func (m *mocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inputs[args.Name] = args.Inputs
+	return args.Name + "_id", args.Inputs, nil
+}
+
+func (m *mocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	return args.Args, nil
+}
+
+func TestParseArgs(t *testing.T) {
+	opts, err := parseArgs([]string{"up", "-spec", "nodes.yaml", "-stack", "prod", "-backend", "state"}, io.Discard)
+	assert.NoError(t, err, "Expected to not receive an error")
+	assert.Equal(t, "up", opts.command)
+	assert.True(t, filepath.IsAbs(opts.spec), "Expected the spec path to be absolute")
+	assert.Equal(t, "nodes.yaml", filepath.Base(opts.spec))
+	assert.Equal(t, "prod", opts.stack)
+	assert.Equal(t, "node-deployer", opts.project)
+	assert.True(t, filepath.IsAbs(opts.backend), "Expected the backend directory to be absolute")
+
+	_, err = parseArgs([]string{"deploy"}, io.Discard)
+	assert.ErrorContains(t, err, "missing or unknown command")
+
+	_, err = parseArgs(nil, io.Discard)
+	assert.ErrorContains(t, err, "missing or unknown command")
+}
+
+func TestProgram(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "fleet.yaml")
+	assert.NoError(t, os.WriteFile(specPath, []byte(`
+nodes:
+  - name: holesky
+    network: holesky
+    host: {address: 10.0.0.1, user: root}
+    execution:
+      client: geth
+      deploymentType: source
+    consensus:
+      client: lighthouse
+      deploymentType: source
+`), 0o600))
+
+	m := &mocks{inputs: map[string]resource.PropertyMap{}}
+	err := pulumi.RunErr(program(specPath), pulumi.WithMocks("project", "stack", m))
+	assert.NoError(t, err, "Expected to not receive an error")
+
+	assert.Contains(t, m.inputs, "executionService-geth-0", "Expected the spec to be deployed")
+
+	// the start scripts copied to the host resolve against the work directory
+	workDir, err := writeAssets()
+	assert.NoError(t, err, "Expected to not receive an error")
+	defer os.RemoveAll(workDir)
+	localPath := m.inputs["copyStartScript-geth-0"]["localPath"].StringValue()
+	assert.Equal(t, "scripts/start_geth.sh", localPath)
+	assert.FileExists(t, filepath.Join(workDir, localPath))
+	assert.FileExists(t, filepath.Join(workDir, "config", "geth.service"))
+}
@@ -0,0 +1,80 @@
+package consensusClient
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// binaryReleaser is implemented by built-in drivers of clients with official
+// release downloads, they support binary deployments.
+type binaryReleaser interface {
+	// binaryRelease returns the download Url, Executable and Link of the
+	// release of version built for arch.
+	binaryRelease(version string, arch string) utils.BinaryRelease
+}
+
+// clientRelease returns the release args.BinaryVersion is installed from.
+func clientRelease(releaser binaryReleaser, args *ConsensusClientComponentArgs) utils.BinaryRelease {
+	arch := args.BinaryArch
+	if arch == "" {
+		arch = utils.DefaultArch
+	}
+	release := releaser.binaryRelease(args.BinaryVersion, arch)
+	release.Client = args.Client
+	release.Version = args.BinaryVersion
+	release.Sha256 = args.BinarySha256
+	release.PgpKey = args.BinaryPgpKey
+	if args.BinaryUrl != "" {
+		release.Url = args.BinaryUrl
+	}
+	return release
+}
+
+// deployBinary installs a verified release of a consensus client on the ssh
+// host and runs it as a systemd service with the start script of source
+// deployments. The service is restarted when a new release is installed.
+func deployBinary(ctx *pulumi.Context, component *ConsensusClientComponent, driver ConsensusClientDriver, releaser binaryReleaser, args *ConsensusClientComponentArgs) error {
+	serviceArgs := *args
+	if serviceArgs.DataDir == "" {
+		serviceArgs.DataDir = fmt.Sprintf("/data/%s/%s", args.Network, args.Client)
+	}
+	release := clientRelease(releaser, args)
+
+	_, err := remote.NewCommand(ctx, fmt.Sprintf("createDataDir-%s", resourcePrefix(driver, args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("mkdir -p %s", serviceArgs.DataDir),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error creating data directory", nil)
+		return err
+	}
+
+	// download, verify and link the release
+	install, err := utils.NewBinaryRelease(ctx, fmt.Sprintf("installRelease-%s", resourcePrefix(driver, args)), args.Connection, release, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error installing release", nil)
+		return err
+	}
+
+	// source builds and releases share the start script
+	startScript := fmt.Sprintf("start_%s.sh", args.Client)
+	if build := driver.SourceBuild(args); build != nil {
+		startScript = build.StartScript
+	}
+	serviceDefinition, err := deployService(ctx, component, driver, &serviceArgs, startScript, "", install)
+	if err != nil {
+		return err
+	}
+
+	// pick up new releases
+	_, err = utils.NewServiceRestart(ctx, fmt.Sprintf("restartService-%s", resourcePrefix(driver, args)), args.Connection, args.Client, args.Network, release, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{serviceDefinition}))
+	if err != nil {
+		ctx.Log.Error("Error restarting consensus service", nil)
+		return err
+	}
+
+	return setSourceEndpoints(ctx, component, driver, &serviceArgs)
+}
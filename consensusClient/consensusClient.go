@@ -2,13 +2,25 @@ package consensusClient
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
 )
 
 type ConsensusClientComponent struct {
 	pulumi.ResourceState
+
+	// BeaconApiUrl is the beacon node REST API endpoint.
+	BeaconApiUrl pulumi.StringOutput `pulumi:"beaconApiUrl"`
+	// MetricsUrl is the prometheus metrics endpoint.
+	MetricsUrl pulumi.StringOutput `pulumi:"metricsUrl"`
+	// P2pNodePort is the NodePort of the p2p service, or the p2p port on the host for source deployments.
+	P2pNodePort pulumi.IntOutput `pulumi:"p2pNodePort"`
+	// Enr is the node's ENR, only populated when QueryNodeIdentity is set.
+	Enr pulumi.StringOutput `pulumi:"enr"`
 }
 
 type ConsensusClientComponentArgs struct {
@@ -24,13 +36,67 @@ type ConsensusClientComponentArgs struct {
 	EnableRpcIngress                 bool
 	PodStorageClass                  string
 	PodStorageSize                   string
-	ExecutionJwt                     string
+	ExecutionJwt                     pulumi.StringInput
+	ExecutionEndpoint                pulumi.StringInput
 	Name                             string
 	SnapshotName                     string
 	CpuLimit                         string
 	MemoryLimit                      string
 	CpuRequest                       string
 	MemoryRequest                    string
+	QueryNodeIdentity                bool
+	// SourceRepoUrl and SourceRef select the git repository and the branch, tag
+	// or commit source deployments are built from. Empty fields fall back to the
+	// client defaults: the stable branch for lighthouse and nimbus, master for
+	// teku and grandine, develop for prysm and unstable for lodestar.
+	SourceRepoUrl string
+	SourceRef     string
+	// BuilderEndpoint is the builder api, e.g. the Url of a mev-boost
+	// component, blocks are requested from. Each client gets its own builder
+	// flags, like --builder for lighthouse or --payload-builder-url for nimbus.
+	BuilderEndpoint pulumi.StringInput
+	// BinaryVersion is the release binary deployments install, for nimbus
+	// including the commit, e.g. 24.10.0_c4f3b3b4. BinaryArch selects the
+	// amd64 (default) or arm64 build. The download is verified against
+	// BinarySha256 and, when BinaryPgpKey is set, against the release
+	// signature. BinaryUrl replaces the client's official download url.
+	BinaryVersion string
+	BinaryArch    string
+	BinarySha256  string
+	BinaryPgpKey  string
+	BinaryUrl     string
+	// RenderManifestsTo writes the kubernetes objects of the client as YAML
+	// to a directory named after the client below it instead of applying
+	// them, e.g. for a GitOps repository synced by Argo CD. RenderSecrets
+	// selects how secrets are written: "reference" (the default) leaves them
+	// out to be provisioned out of band under the same name, "sealed" writes
+	// SealedSecrets encrypted with kubeseal and SealedSecretsCert.
+	RenderManifestsTo string
+	RenderSecrets     string
+	SealedSecretsCert string
+	// Namespace is the kubernetes namespace the client's objects are created
+	// in, the provider's namespace when empty. Objects are named after Name,
+	// or the client without one, e.g. <Name>-data and <Name>-config, so
+	// clients sharing a namespace need different names.
+	Namespace string
+	// Kubeconfig and KubeContext select the cluster the QueryNodeIdentity
+	// query runs its curl pod in, set them to the kubeconfig path and context
+	// of the kubernetes provider the client is deployed with. The local
+	// kubectl's current context is used when empty.
+	Kubeconfig  string
+	KubeContext string
+	// IngressArgs describe how EnableRpcIngress exposes the beacon api on
+	// IngressHost.
+	utils.IngressArgs
+	// DisableProbes leaves the kubernetes container without probes. Otherwise
+	// they get the beacon api's /eth/v1/node/health: the client is live while
+	// the api answers, syncing or not, and ready once synced. The probes run
+	// every ProbePeriodSeconds, 10 by default, and ProbeFailureThreshold
+	// failures in a row, 6 by default, restart the client or take it out of
+	// its services. The startup probe allows StartupProbeFailureThreshold
+	// failures, 720 or two hours by default, for the client to initialize
+	// its beacon state before liveness applies.
+	utils.ProbeArgs
 }
 
 const (
@@ -39,15 +105,86 @@ const (
 	Lighthouse = "lighthouse"
 	Lodestar   = "lodestar"
 	Nimbus     = "nimbus"
+	Grandine   = "grandine"
 	Source     = "source"
 	Binary     = "binary"
 	Docker     = "docker"
 	Kubernetes = "kubernetes"
+	Mainnet    = "mainnet"
+	Sepolia    = "sepolia"
+	Holesky    = "holesky"
+	Hoodi      = "hoodi"
+)
+
+var (
+	networks        = []string{Mainnet, Sepolia, Holesky, Hoodi}
+	deploymentTypes = []string{Source, Binary, Docker, Kubernetes}
 )
 
+// Validate checks the args for unknown clients, networks and deployment types
+// as well as settings required by the chosen deployment type. All problems
+// found are returned together as utils.ValidationErrors.
+func (args *ConsensusClientComponentArgs) Validate() error {
+	var errs utils.ValidationErrors
+
+	driver, ok := LookupConsensusClient(args.Client)
+	if !ok {
+		errs.Add("Client", "unknown consensus client %q, expected one of %s", args.Client, strings.Join(ConsensusClients(), ", "))
+	}
+	if !slices.Contains(networks, args.Network) {
+		errs.Add("Network", "unknown network %q, expected one of %s", args.Network, strings.Join(networks, ", "))
+	}
+	if !slices.Contains(deploymentTypes, args.DeploymentType) {
+		errs.Add("DeploymentType", "unknown deployment type %q, expected one of %s", args.DeploymentType, strings.Join(deploymentTypes, ", "))
+	} else if ok && !supportsDeploymentType(driver, args) {
+		errs.Add("DeploymentType", "consensus client %q does not support deployment type %q", args.Client, args.DeploymentType)
+	}
+
+	switch args.DeploymentType {
+	case Source, Docker:
+		if args.Connection == nil {
+			errs.Add("Connection", "required for %s deployments", args.DeploymentType)
+		}
+	case Binary:
+		if args.Connection == nil {
+			errs.Add("Connection", "required for %s deployments", args.DeploymentType)
+		}
+		utils.ValidateRelease(&errs, "Binary", args.BinaryVersion, args.BinaryArch, args.BinarySha256)
+	case Kubernetes:
+		if args.ExecutionJwt == nil {
+			errs.Add("ExecutionJwt", "required for %s deployments", args.DeploymentType)
+		}
+		if args.ConsensusClientConfigPath == "" {
+			errs.Add("ConsensusClientConfigPath", "required for %s deployments", args.DeploymentType)
+		}
+		utils.ValidateKubernetesName(&errs, "Name", args.Name)
+		utils.ValidateKubernetesName(&errs, "Namespace", args.Namespace)
+		if args.CpuLimit == "" {
+			errs.Add("CpuLimit", "required for %s deployments", args.DeploymentType)
+		}
+		if args.MemoryLimit == "" {
+			errs.Add("MemoryLimit", "required for %s deployments", args.DeploymentType)
+		}
+	}
+	if args.Namespace != "" && args.DeploymentType != Kubernetes {
+		errs.Add("Namespace", "only supported for %s deployments", Kubernetes)
+	}
+	if args.Kubeconfig != "" && args.DeploymentType != Kubernetes {
+		errs.Add("Kubeconfig", "only supported for %s deployments", Kubernetes)
+	}
+	if args.KubeContext != "" && args.DeploymentType != Kubernetes {
+		errs.Add("KubeContext", "only supported for %s deployments", Kubernetes)
+	}
+	utils.ValidateManifestRendering(&errs, args.DeploymentType, Kubernetes, args.RenderManifestsTo, args.RenderSecrets, args.SealedSecretsCert, args.QueryNodeIdentity)
+	utils.ValidateIngress(&errs, "EnableRpcIngress", args.EnableRpcIngress, args.DeploymentType, Kubernetes, args.Ingress())
+	utils.ValidateProbes(&errs, args.DeploymentType, Kubernetes, args.Probes())
+
+	return errs.Err()
+}
+
 // NewConsensusClientComponent creates a new instance of the ConsensusClientComponent
-// and calls the appropriate component constructor based on the client
-// being requested.
+// and deploys it with the ConsensusClientDriver registered for the client being requested.
+// The args are validated before any resources are registered.
 // It returns a pointer to the ConsensusClientComponent and an error
 //
 // Example usage:
@@ -68,43 +205,35 @@ func NewConsensusClientComponent(ctx *pulumi.Context, name string, args *Consens
 		args = &ConsensusClientComponentArgs{}
 	}
 
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+
 	component := &ConsensusClientComponent{}
 	err := ctx.RegisterComponentResource(fmt.Sprintf("custom:component:ConsensusClient:%s:%s", args.Client, args.Network), name, component, opts...)
 	if err != nil {
 		return nil, err
 	}
+	initOutputs(component)
 
-	switch args.Client {
-	case Teku:
-		_, err = NewTekuComponent(ctx, "teku", args, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error creating teku component", nil)
-			return nil, err
-		}
-	case Prysm:
-		_, err = NewPrysmComponent(ctx, "prysm", args, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error creating prysm component", nil)
-			return nil, err
-		}
-	case Lighthouse:
-		_, err = NewLighthouseComponent(ctx, "lighthouse", args, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error creating lighthouse component", nil)
-			return nil, err
-		}
-	case Lodestar:
-		_, err = NewLodestarComponent(ctx, "lodestar", args, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error creating lodestar component", nil)
-			return nil, err
-		}
-	case Nimbus:
-		_, err = NewNimbusComponent(ctx, "nimbus", args, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error creating nimbus component", nil)
-			return nil, err
-		}
+	// look up the driver for the requested client and call its component constructor
+	driver, _ := LookupConsensusClient(args.Client)
+	// the client component is named after args.Name so several nodes can be
+	// deployed side by side, the alias keeps the name used before
+	clientName, clientOpts := driver.Client(), []pulumi.ResourceOption{pulumi.Parent(component)}
+	if args.Name != "" && args.Name != clientName {
+		clientOpts = append(clientOpts, pulumi.Aliases([]pulumi.Alias{{Name: pulumi.String(clientName)}}))
+		clientName = args.Name
+	}
+	client, err := newClientComponent(ctx, clientName, driver, args, clientOpts...)
+	if err != nil {
+		ctx.Log.Error(fmt.Sprintf("Error creating %s component", driver.Client()), nil)
+		return nil, err
+	}
+
+	copyOutputs(component, client)
+	if err := registerOutputs(ctx, component); err != nil {
+		return nil, err
 	}
 
 	return component, nil
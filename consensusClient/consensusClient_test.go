@@ -1,9 +1,13 @@
 package consensusClient_test
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/rswanson/node_deployer/consensusClient"
+	"github.com/rswanson/node_deployer/utils"
 
 	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
@@ -21,18 +25,29 @@ func (mocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
 	return args.Args, nil
 }
 
+// kubernetesArgs returns valid args to deploy client to kubernetes.
+func kubernetesArgs(t *testing.T, client string) *consensusClient.ConsensusClientComponentArgs {
+	configPath := filepath.Join(t.TempDir(), client+".toml")
+	assert.NoError(t, os.WriteFile(configPath, []byte("[test]"), 0o600))
+
+	return &consensusClient.ConsensusClientComponentArgs{
+		Client:                    client,
+		Network:                   "holesky",
+		DeploymentType:            "kubernetes",
+		ExecutionJwt:              pulumi.String("testJwt"),
+		ConsensusClientConfigPath: configPath,
+		CpuLimit:                  "1",
+		MemoryLimit:               "1Gi",
+		Name:                      client,
+	}
+}
+
 func TestConsensusClientComponent(t *testing.T) {
 	t.Run("TekuComponent", func(t *testing.T) {
 		mocks := mocks(0)
 		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
 			// Create a new instance of the ConsensusClientComponent
-			_, err := consensusClient.NewConsensusClientComponent(ctx, "testTekuConsensusClient", &consensusClient.ConsensusClientComponentArgs{
-				Connection:     &remote.ConnectionArgs{},
-				Client:         "teku",
-				Network:        "testNetwork",
-				DeploymentType: "testDeploymentType",
-				DataDir:        "testDataDir",
-			})
+			_, err := consensusClient.NewConsensusClientComponent(ctx, "testTekuConsensusClient", kubernetesArgs(t, "teku"))
 
 			// Test the NewTekuComponent function
 
@@ -47,13 +62,7 @@ func TestConsensusClientComponent(t *testing.T) {
 		mocks := mocks(0)
 		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
 			// Create a new instance of the ConsensusClientComponent
-			_, err := consensusClient.NewConsensusClientComponent(ctx, "testPrysmConsensusClient", &consensusClient.ConsensusClientComponentArgs{
-				Connection:     &remote.ConnectionArgs{},
-				Client:         "prysm",
-				Network:        "testNetwork",
-				DeploymentType: "testDeploymentType",
-				DataDir:        "testDataDir",
-			})
+			_, err := consensusClient.NewConsensusClientComponent(ctx, "testPrysmConsensusClient", kubernetesArgs(t, "prysm"))
 
 			assert.NoError(t, err, "Expected to not receive an error")
 
@@ -66,13 +75,7 @@ func TestConsensusClientComponent(t *testing.T) {
 		mocks := mocks(0)
 		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
 			// Create a new instance of the ConsensusClientComponent
-			_, err := consensusClient.NewConsensusClientComponent(ctx, "testLighthouseConsensusClient", &consensusClient.ConsensusClientComponentArgs{
-				Connection:     &remote.ConnectionArgs{},
-				Client:         "lighthouse",
-				Network:        "testNetwork",
-				DeploymentType: "testDeploymentType",
-				DataDir:        "testDataDir",
-			})
+			_, err := consensusClient.NewConsensusClientComponent(ctx, "testLighthouseConsensusClient", kubernetesArgs(t, "lighthouse"))
 
 			assert.NoError(t, err, "Expected to not receive an error")
 
@@ -85,13 +88,7 @@ func TestConsensusClientComponent(t *testing.T) {
 		mocks := mocks(0)
 		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
 			// Create a new instance of the ConsensusClientComponent
-			_, err := consensusClient.NewConsensusClientComponent(ctx, "testLodestarConsensusClient", &consensusClient.ConsensusClientComponentArgs{
-				Connection:     &remote.ConnectionArgs{},
-				Client:         "lodestar",
-				Network:        "testNetwork",
-				DeploymentType: "testDeploymentType",
-				DataDir:        "testDataDir",
-			})
+			_, err := consensusClient.NewConsensusClientComponent(ctx, "testLodestarConsensusClient", kubernetesArgs(t, "lodestar"))
 
 			assert.NoError(t, err, "Expected to not receive an error")
 
@@ -104,12 +101,40 @@ func TestConsensusClientComponent(t *testing.T) {
 		mocks := mocks(0)
 		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
 			// Create a new instance of the ConsensusClientComponent
+			_, err := consensusClient.NewConsensusClientComponent(ctx, "testNimbusConsensusClient", kubernetesArgs(t, "nimbus"))
+
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks))
+		assert.NoError(t, err, "Expected to not receive an error")
+	})
+
+	t.Run("GrandineComponent", func(t *testing.T) {
+		mocks := mocks(0)
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			// Create a new instance of the ConsensusClientComponent
+			_, err := consensusClient.NewConsensusClientComponent(ctx, "testGrandineConsensusClient", kubernetesArgs(t, "grandine"))
+
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks))
+		assert.NoError(t, err, "Expected to not receive an error")
+	})
+
+	t.Run("BinaryComponent", func(t *testing.T) {
+		mocks := mocks(0)
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
 			_, err := consensusClient.NewConsensusClientComponent(ctx, "testNimbusConsensusClient", &consensusClient.ConsensusClientComponentArgs{
-				Connection:     &remote.ConnectionArgs{},
-				Client:         "nimbus",
-				Network:        "testNetwork",
-				DeploymentType: "testDeploymentType",
-				DataDir:        "testDataDir",
+				Connection:        &remote.ConnectionArgs{Host: pulumi.String("10.0.0.1")},
+				Client:            "nimbus",
+				Network:           "holesky",
+				DeploymentType:    "binary",
+				ExecutionJwt:      pulumi.String("testJwt"),
+				ExecutionEndpoint: pulumi.String("http://127.0.0.1:8551"),
+				BinaryVersion:     "24.10.0_c4f3b3b4",
+				BinarySha256:      "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
 			})
 
 			assert.NoError(t, err, "Expected to not receive an error")
@@ -119,4 +144,165 @@ func TestConsensusClientComponent(t *testing.T) {
 		assert.NoError(t, err, "Expected to not receive an error")
 	})
 
+	t.Run("DockerComponent", func(t *testing.T) {
+		mocks := mocks(0)
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			args := kubernetesArgs(t, "lighthouse")
+			args.DeploymentType = "docker"
+			args.Connection = &remote.ConnectionArgs{Host: pulumi.String("10.0.0.1")}
+			args.ExecutionEndpoint = pulumi.String("http://geth:8551")
+			client, err := consensusClient.NewConsensusClientComponent(ctx, "testLighthouseConsensusClient", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			done := make(chan struct{})
+			client.BeaconApiUrl.ApplyT(func(url string) error {
+				assert.Equal(t, "http://10.0.0.1:5052", url)
+				close(done)
+				return nil
+			})
+			<-done
+
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks))
+		assert.NoError(t, err, "Expected to not receive an error")
+	})
+
+}
+
+func TestConsensusClientComponentOutputs(t *testing.T) {
+	mocks := mocks(0)
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		client, err := consensusClient.NewConsensusClientComponent(ctx, "testLighthouseConsensusClient", kubernetesArgs(t, "lighthouse"))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		done := make(chan struct{})
+		pulumi.All(client.BeaconApiUrl, client.MetricsUrl).ApplyT(func(urls []interface{}) error {
+			assert.Equal(t, "http://lighthouse-beacon-api-service.default.svc.cluster.local:5052", urls[0])
+			assert.Equal(t, "http://lighthouse-metrics-service.default.svc.cluster.local:5054", urls[1])
+			close(done)
+			return nil
+		})
+		<-done
+
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	assert.NoError(t, err, "Expected to not receive an error")
+}
+
+type testDriver struct{}
+
+func (testDriver) Client() string { return "test-client" }
+
+func (testDriver) SourceBuild(args *consensusClient.ConsensusClientComponentArgs) *consensusClient.ConsensusClientSourceBuild {
+	return nil
+}
+
+func (testDriver) Ports() consensusClient.ConsensusClientPorts {
+	return consensusClient.ConsensusClientPorts{P2P: 9000, QuicP2P: 9001, Metrics: 5054, BeaconApi: 5052}
+}
+
+func (testDriver) MountPaths(network string) consensusClient.ConsensusClientMountPaths {
+	return consensusClient.ConsensusClientMountPaths{Config: "/etc/test", Data: "/data", Jwt: "/secrets"}
+}
+
+func (testDriver) DefaultImage() string { return "test/client:latest" }
+
+func (testDriver) ConfigFileName() string { return "test.toml" }
+
+func (testDriver) Flags(settings consensusClient.ConsensusClientFlags) []string {
+	return []string{"--network", settings.Network}
+}
+
+func (testDriver) BuilderFlags(endpoint string) []string { return nil }
+
+func TestRegisterConsensusClient(t *testing.T) {
+	consensusClient.RegisterConsensusClient(testDriver{})
+
+	assert.Contains(t, consensusClient.ConsensusClients(), "test-client")
+	for _, client := range []string{consensusClient.Teku, consensusClient.Prysm, consensusClient.Lighthouse, consensusClient.Lodestar, consensusClient.Nimbus} {
+		_, ok := consensusClient.LookupConsensusClient(client)
+		assert.True(t, ok, "Expected built-in driver for %s", client)
+	}
+
+	assert.Panics(t, func() { consensusClient.RegisterConsensusClient(testDriver{}) }, "Expected duplicate registration to panic")
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := consensusClient.NewConsensusClientComponent(ctx, "testCustomConsensusClient", kubernetesArgs(t, "test-client"))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks(0)))
+	assert.NoError(t, err, "Expected to not receive an error")
+}
+
+func TestConsensusClientComponentArgsValidate(t *testing.T) {
+	assert.NoError(t, kubernetesArgs(t, "lighthouse").Validate(), "Expected valid args to pass validation")
+
+	err := (&consensusClient.ConsensusClientComponentArgs{
+		Client:         "not-a-client",
+		Network:        "testNetwork",
+		DeploymentType: "testDeploymentType",
+	}).Validate()
+
+	var validationErrors utils.ValidationErrors
+	assert.True(t, errors.As(err, &validationErrors), "Expected validation errors")
+	fields := make([]string, 0, len(validationErrors))
+	for _, validationError := range validationErrors {
+		fields = append(fields, validationError.Field)
+	}
+	assert.Equal(t, []string{"Client", "Network", "DeploymentType"}, fields)
+
+	args := kubernetesArgs(t, "teku")
+	args.ConsensusClientConfigPath = ""
+	args.MemoryLimit = ""
+	err = args.Validate()
+	assert.ErrorContains(t, err, "ConsensusClientConfigPath: required for kubernetes deployments")
+	assert.ErrorContains(t, err, "MemoryLimit: required for kubernetes deployments")
+
+	args = kubernetesArgs(t, "test-client")
+	args.DeploymentType = "source"
+	args.Connection = &remote.ConnectionArgs{}
+	assert.ErrorContains(t, args.Validate(), `consensus client "test-client" does not support deployment type "source"`)
+
+	args = kubernetesArgs(t, "prysm")
+	args.DeploymentType = "binary"
+	args.Connection = &remote.ConnectionArgs{}
+	err = args.Validate()
+	assert.ErrorContains(t, err, `consensus client "prysm" does not support deployment type "binary"`)
+	assert.ErrorContains(t, err, "BinaryVersion: required for binary deployments")
+	assert.ErrorContains(t, err, "BinarySha256: required for binary deployments")
+
+	args = kubernetesArgs(t, "lighthouse")
+	args.DeploymentType = "docker"
+	args.Connection = &remote.ConnectionArgs{}
+	args.Namespace = "holesky"
+	assert.ErrorContains(t, args.Validate(), "Namespace: only supported for kubernetes deployments")
+
+	args = kubernetesArgs(t, "lighthouse")
+	args.RenderManifestsTo = t.TempDir()
+	args.RenderSecrets = "plain"
+	assert.ErrorContains(t, args.Validate(), `RenderSecrets: unknown secrets mode "plain", expected one of reference, sealed`)
+
+	args = kubernetesArgs(t, "lighthouse")
+	args.DeploymentType = "docker"
+	args.Connection = &remote.ConnectionArgs{}
+	args.EnableRpcIngress = true
+	args.IngressHost = "beacon.example.com"
+	args.IngressKind = "loadbalancer"
+	err = args.Validate()
+	assert.ErrorContains(t, err, "EnableRpcIngress: only supported for kubernetes deployments")
+	assert.ErrorContains(t, err, `IngressKind: unknown ingress kind "loadbalancer", expected one of ingress, httproute`)
+}
+
+func TestSourceFromConfig(t *testing.T) {
+	t.Setenv("PULUMI_CONFIG", `{"project:lighthouseRepoURL": "https://example.com/lighthouse.git", "project:lighthouseBranch": "unstable"}`)
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		args := &consensusClient.ConsensusClientComponentArgs{Client: "lighthouse", SourceRef: "v5.3.0"}
+		consensusClient.SourceFromConfig(ctx, args)
+		assert.Equal(t, "https://example.com/lighthouse.git", args.SourceRepoUrl)
+		assert.Equal(t, "v5.3.0", args.SourceRef, "Expected the args to take precedence over the config")
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks(0)))
+	assert.NoError(t, err)
 }
@@ -0,0 +1,104 @@
+package consensusClient
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// deployDocker runs a consensus client as a docker container on the ssh host.
+// The container is attached to the docker network of args.Network, so an
+// execution client container on the same host is reached by its container
+// name. The data directory, the config file and the shared jwt are bind
+// mounted where the kubernetes deployment mounts them.
+func deployDocker(ctx *pulumi.Context, component *ConsensusClientComponent, driver ConsensusClientDriver, args *ConsensusClientComponentArgs) error {
+	name := resourcePrefix(driver, args)
+	ports := driver.Ports()
+	mounts := driver.MountPaths(args.Network)
+	dataDir := args.DataDir
+	if dataDir == "" {
+		dataDir = fmt.Sprintf("/data/%s/%s", args.Network, name)
+	}
+
+	network, err := utils.NewDockerNetwork(ctx, fmt.Sprintf("dockerNetwork-%s", name), args.Connection, utils.DockerNetworkName(args.Network), pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error creating docker network", nil)
+		return err
+	}
+	containerDeps := []pulumi.Resource{network}
+
+	volumes := []utils.DockerVolume{
+		{HostPath: dataDir, ContainerPath: mounts.Data},
+		{HostPath: path.Dir(utils.JwtSecretPath), ContainerPath: mounts.Jwt, ReadOnly: true},
+	}
+
+	// copy the client config next to the data directory
+	if args.ConsensusClientConfigPath != "" {
+		configDir := fmt.Sprintf("/data/config/%s", name)
+		createConfigDir, err := remote.NewCommand(ctx, fmt.Sprintf("createConfigDir-%s", name), &remote.CommandArgs{
+			Create:     pulumi.Sprintf("mkdir -p %s", configDir),
+			Connection: args.Connection,
+		}, pulumi.Parent(component))
+		if err != nil {
+			ctx.Log.Error("Error creating config directory", nil)
+			return err
+		}
+		config, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyConfig-%s", name), &remote.CopyFileArgs{
+			LocalPath:  pulumi.String(args.ConsensusClientConfigPath),
+			RemotePath: pulumi.String(path.Join(configDir, driver.ConfigFileName())),
+			Connection: args.Connection,
+		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{createConfigDir}))
+		if err != nil {
+			ctx.Log.Error("Error copying config", nil)
+			return err
+		}
+		volumes = append(volumes, utils.DockerVolume{HostPath: configDir, ContainerPath: mounts.Config, ReadOnly: true})
+		containerDeps = append(containerDeps, config)
+	}
+
+	// write the shared engine api jwt, without one the secret is expected to be provisioned already
+	if args.ExecutionJwt != nil {
+		jwtSecret, err := utils.NewJwtSecretFile(ctx, fmt.Sprintf("writeJwtSecret-%s", name), args.Connection, args.ExecutionJwt, pulumi.Parent(component))
+		if err != nil {
+			ctx.Log.Error("Error writing jwt secret", nil)
+			return err
+		}
+		containerDeps = append(containerDeps, jwtSecret)
+	}
+
+	published := []utils.DockerPort{
+		{Port: ports.P2P},
+		{Port: ports.P2P, Protocol: "udp"},
+		{Port: ports.Metrics},
+		{Port: ports.BeaconApi},
+	}
+	if ports.QuicP2P != 0 {
+		published = append(published, utils.DockerPort{Port: ports.QuicP2P, Protocol: "udp"})
+	}
+
+	// like in kubernetes, explicit commands can refer to the endpoints through the environment
+	container := &utils.DockerContainerArgs{
+		Connection:  args.Connection,
+		Name:        name,
+		Image:       image(driver, args),
+		Command:     args.ConsensusClientContainerCommands,
+		Network:     utils.DockerNetworkName(args.Network),
+		Groups:      []string{utils.JwtSecretGroup},
+		Volumes:     volumes,
+		Ports:       published,
+		Environment: endpointEnvironment(args),
+	}
+	if len(args.ConsensusClientContainerCommands) == 0 {
+		container.Args = containerArgs(driver, args)
+	}
+	_, err = utils.NewDockerContainer(ctx, fmt.Sprintf("dockerContainer-%s", name), container, pulumi.Parent(component), pulumi.DependsOn(containerDeps))
+	if err != nil {
+		ctx.Log.Error("Error creating docker container", nil)
+		return err
+	}
+
+	return setSourceEndpoints(ctx, component, driver, args)
+}
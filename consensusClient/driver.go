@@ -0,0 +1,266 @@
+package consensusClient
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// ConsensusClientPorts holds the ports a consensus client listens on.
+type ConsensusClientPorts struct {
+	P2P       int
+	QuicP2P   int
+	Metrics   int
+	BeaconApi int
+}
+
+// ConsensusClientMountPaths holds the paths the config, data and execution
+// jwt volumes are mounted at inside the client container.
+type ConsensusClientMountPaths struct {
+	Config string
+	Data   string
+	Jwt    string
+}
+
+// ConsensusClientFlags holds the settings a driver renders into client CLI flags.
+type ConsensusClientFlags struct {
+	Network           string
+	DataDir           string
+	JwtPath           string
+	ExecutionEndpoint string
+	Ports             ConsensusClientPorts
+}
+
+// ConsensusClientBuildStep is a single command run on the remote host while
+// building a consensus client from source.
+type ConsensusClientBuildStep struct {
+	// Name is used to name the step's resource, e.g. "installJava".
+	Name string
+	// Description is used in error logs, e.g. "installing java".
+	Description string
+	Command     pulumi.StringInput
+}
+
+// ConsensusClientSourceBuild describes how to build a consensus client from source.
+type ConsensusClientSourceBuild struct {
+	// RepoDir is the directory the client repository is cloned into.
+	RepoDir string
+	// Repo is the default repository and ref to build, SourceRepoUrl and
+	// SourceRef of the args take precedence.
+	Repo utils.SourceRepo
+	// Steps are run in order after the repository has been cloned.
+	Steps []ConsensusClientBuildStep
+	// StartScript is the name of the start script in scripts/ that is copied to /data/scripts.
+	StartScript string
+	// Binary is an installed binary owned by the client user, if any.
+	Binary string
+}
+
+// ConsensusClientDriver describes how to deploy a specific consensus client.
+// The source, docker and kubernetes deployments are shared between all clients, a
+// driver only provides what differs. Drivers for teku, prysm, lighthouse,
+// lodestar, nimbus and grandine are registered by default, additional clients
+// can be added with RegisterConsensusClient.
+type ConsensusClientDriver interface {
+	// Client returns the name used to select this driver in ConsensusClientComponentArgs.Client.
+	Client() string
+	// SourceBuild returns how to build the client from source, or nil if
+	// the client does not support source deployments.
+	SourceBuild(args *ConsensusClientComponentArgs) *ConsensusClientSourceBuild
+	// Ports returns the ports the client listens on.
+	Ports() ConsensusClientPorts
+	// MountPaths returns where volumes are mounted in the client container
+	// running network, the data volume usually at the client's default data
+	// directory of the network.
+	MountPaths(network string) ConsensusClientMountPaths
+	// DefaultImage returns the container image used when ConsensusClientImage is empty.
+	DefaultImage() string
+	// ConfigFileName returns the file name the client config is stored under.
+	ConfigFileName() string
+	// Flags renders the container arguments used when ConsensusClientContainerCommands is empty.
+	Flags(settings ConsensusClientFlags) []string
+	// BuilderFlags renders the flags connecting the client to a builder api
+	// like mev-boost, nil when the client has no builder support.
+	BuilderFlags(endpoint string) []string
+}
+
+// componentTyper is implemented by built-in drivers whose component type
+// predates the driver registry and has to stay stable to keep existing URNs.
+type componentTyper interface {
+	componentType(args *ConsensusClientComponentArgs) string
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]ConsensusClientDriver)
+)
+
+// RegisterConsensusClient makes a consensus client driver available to
+// NewConsensusClientComponent under the name returned by driver.Client().
+// It panics if driver is nil or a driver with the same name is already registered.
+func RegisterConsensusClient(driver ConsensusClientDriver) {
+	if driver == nil {
+		panic("consensusClient: RegisterConsensusClient driver is nil")
+	}
+
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if _, dup := drivers[driver.Client()]; dup {
+		panic("consensusClient: RegisterConsensusClient called twice for client " + driver.Client())
+	}
+	drivers[driver.Client()] = driver
+}
+
+// LookupConsensusClient returns the driver registered for client.
+func LookupConsensusClient(client string) (ConsensusClientDriver, bool) {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	driver, ok := drivers[client]
+	return driver, ok
+}
+
+// ConsensusClients returns the sorted names of all registered consensus clients.
+func ConsensusClients() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	clients := make([]string, 0, len(drivers))
+	for client := range drivers {
+		clients = append(clients, client)
+	}
+	sort.Strings(clients)
+	return clients
+}
+
+// newClientComponent registers the component for a single consensus client
+// and deploys it with the requested deployment type.
+func newClientComponent(ctx *pulumi.Context, name string, driver ConsensusClientDriver, args *ConsensusClientComponentArgs, opts ...pulumi.ResourceOption) (*ConsensusClientComponent, error) {
+	if args == nil {
+		args = &ConsensusClientComponentArgs{}
+	}
+
+	componentType := fmt.Sprintf("custom:component:ConsensusClient:%s", driver.Client())
+	if typer, ok := driver.(componentTyper); ok {
+		componentType = typer.componentType(args)
+	}
+
+	// kubernetes objects are written to the manifest directory instead of the cluster
+	if rendering := args.manifestRendering(); rendering != nil {
+		provider, err := utils.NewManifestProvider(ctx, fmt.Sprintf("%s-manifests", name), rendering, opts...)
+		if err != nil {
+			ctx.Log.Error("Error creating manifest provider", nil)
+			return nil, err
+		}
+		opts = append(append([]pulumi.ResourceOption{}, opts...), pulumi.Providers(provider))
+	}
+
+	component := &ConsensusClientComponent{}
+	err := ctx.RegisterComponentResource(componentType, name, component, opts...)
+	if err != nil {
+		return nil, err
+	}
+	initOutputs(component)
+
+	switch args.DeploymentType {
+	case Source:
+		if build := driver.SourceBuild(args); build != nil {
+			err = deploySource(ctx, component, driver, build, args)
+		}
+	case Binary:
+		if releaser, ok := driver.(binaryReleaser); ok {
+			err = deployBinary(ctx, component, driver, releaser, args)
+		}
+	case Docker:
+		err = deployDocker(ctx, component, driver, args)
+	case Kubernetes:
+		err = deployKubernetes(ctx, component, driver, args)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := registerOutputs(ctx, component); err != nil {
+		return nil, err
+	}
+
+	return component, nil
+}
+
+// supportsDeploymentType reports whether driver can deploy args.DeploymentType.
+func supportsDeploymentType(driver ConsensusClientDriver, args *ConsensusClientComponentArgs) bool {
+	switch args.DeploymentType {
+	case Binary:
+		_, ok := driver.(binaryReleaser)
+		return ok
+	case Docker, Kubernetes:
+		return true
+	case Source:
+		// source builds may depend on the connection, a missing connection
+		// is reported on its own
+		return args.Connection == nil || driver.SourceBuild(args) != nil
+	}
+	return false
+}
+
+// sourceConfigKeys holds the pulumi config keys the clients used to read
+// their repository and branch from.
+var sourceConfigKeys = map[string][2]string{
+	Lighthouse: {"lighthouseRepoURL", "lighthouseBranch"},
+	Teku:       {"tekuRepoUrl", "tekuBranch"},
+	Prysm:      {"prysmRepoUrl", "prysmBranch"},
+	Lodestar:   {"lodestarRepoUrl", "lodestarBranch"},
+	Nimbus:     {"nimbusRepoUrl", "nimbusBranch"},
+}
+
+// SourceFromConfig fills SourceRepoUrl and SourceRef of args that are not set
+// from the pulumi config keys the client used before they were args, e.g.
+// lighthouseRepoURL and lighthouseBranch. Keys missing from the config are
+// ignored so the client defaults apply.
+//
+// Example usage:
+//
+//	args := &consensusClient.ConsensusClientComponentArgs{Client: "lighthouse", DeploymentType: "source"}
+//	consensusClient.SourceFromConfig(ctx, args)
+func SourceFromConfig(ctx *pulumi.Context, args *ConsensusClientComponentArgs) {
+	keys, ok := sourceConfigKeys[args.Client]
+	if !ok {
+		return
+	}
+	repo := utils.SourceRepo{Url: args.SourceRepoUrl, Ref: args.SourceRef}.WithDefaults(utils.SourceRepoFromConfig(ctx, keys[0], keys[1]))
+	args.SourceRepoUrl, args.SourceRef = repo.Url, repo.Ref
+}
+
+// image returns the configured container image or the driver default.
+func image(driver ConsensusClientDriver, args *ConsensusClientComponentArgs) string {
+	if args.ConsensusClientImage != "" {
+		return args.ConsensusClientImage
+	}
+	return driver.DefaultImage()
+}
+
+// resourcePrefix returns the prefix used for the client's kubernetes objects
+// and the name of its docker container.
+func resourcePrefix(driver ConsensusClientDriver, args *ConsensusClientComponentArgs) string {
+	if args.Name != "" {
+		return args.Name
+	}
+	return driver.Client()
+}
+
+// kubernetesNames returns the names of the client's kubernetes objects, they
+// start with resourcePrefix.
+func kubernetesNames(driver ConsensusClientDriver, args *ConsensusClientComponentArgs) utils.KubernetesNames {
+	return utils.KubernetesNames{Name: resourcePrefix(driver, args), Namespace: args.Namespace, PartOf: driver.Client()}
+}
+
+// manifestRendering returns how the kubernetes objects of the client are
+// rendered, nil when they are applied to the cluster.
+func (args *ConsensusClientComponentArgs) manifestRendering() *utils.ManifestRendering {
+	name := args.Name
+	if name == "" {
+		name = args.Client
+	}
+	return utils.NewManifestRendering(args.RenderManifestsTo, name, args.RenderSecrets, args.SealedSecretsCert, args.Namespace)
+}
@@ -0,0 +1,111 @@
+package consensusClient
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+func init() {
+	RegisterConsensusClient(grandineDriver{})
+}
+
+// grandineDriver is the built-in ConsensusClientDriver for grandine.
+type grandineDriver struct{}
+
+func (grandineDriver) Client() string { return Grandine }
+
+func (grandineDriver) SourceBuild(args *ConsensusClientComponentArgs) *ConsensusClientSourceBuild {
+	repoDir := fmt.Sprintf("/data/repos/%s/%s", args.Network, args.Client)
+	return &ConsensusClientSourceBuild{
+		RepoDir: repoDir,
+		Repo:    utils.SourceRepo{Url: "https://github.com/grandinetech/grandine.git", Ref: "master"},
+		Steps: []ConsensusClientBuildStep{
+			{
+				Name:        "installRust",
+				Description: "installing rust toolchain",
+				Command:     pulumi.String("curl --proto '=https' --tlsv1.2 -sSf https://sh.rustup.rs | sh -s -- -y"),
+			},
+			{
+				Name:        "updateSubmodules",
+				Description: "updating submodules",
+				Command:     pulumi.Sprintf("cd %s && git submodule update --init --recursive", repoDir),
+			},
+			{
+				Name:        "buildConsensusClient",
+				Description: "building consensus client",
+				Command:     pulumi.Sprintf("/%s/.cargo/bin/cargo install --locked --path %s/grandine --bin grandine --features default-networks --root /data", args.Connection.User, repoDir),
+			},
+		},
+		StartScript: fmt.Sprintf("start_%s.sh", args.Client),
+		Binary:      fmt.Sprintf("/data/bin/%s", args.Client),
+	}
+}
+
+func (grandineDriver) Ports() ConsensusClientPorts {
+	return ConsensusClientPorts{P2P: 9000, QuicP2P: 9001, Metrics: 5054, BeaconApi: 5052}
+}
+
+func (grandineDriver) MountPaths(network string) ConsensusClientMountPaths {
+	return ConsensusClientMountPaths{Config: "/etc/grandine", Data: path.Join("/root/.grandine", network), Jwt: "/secrets"}
+}
+
+func (grandineDriver) DefaultImage() string { return "sifrai/grandine:stable" }
+
+func (grandineDriver) ConfigFileName() string { return "grandine.toml" }
+
+func (grandineDriver) Flags(settings ConsensusClientFlags) []string {
+	flags := []string{
+		"--network=" + settings.Network,
+		"--data-dir=" + settings.DataDir,
+		"--http-address=0.0.0.0",
+		"--http-port=" + strconv.Itoa(settings.Ports.BeaconApi),
+		"--metrics",
+		"--metrics-address=0.0.0.0",
+		"--metrics-port=" + strconv.Itoa(settings.Ports.Metrics),
+		"--libp2p-port=" + strconv.Itoa(settings.Ports.P2P),
+		"--discovery-port=" + strconv.Itoa(settings.Ports.P2P),
+		"--quic-port=" + strconv.Itoa(settings.Ports.QuicP2P),
+		"--jwt-secret=" + settings.JwtPath,
+	}
+	if settings.ExecutionEndpoint != "" {
+		flags = append(flags, "--eth1-rpc-urls="+settings.ExecutionEndpoint)
+	}
+	return flags
+}
+
+func (grandineDriver) BuilderFlags(endpoint string) []string {
+	return []string{"--builder-url=" + endpoint}
+}
+
+// binaryRelease returns the grandine release published on github, the
+// download is the executable itself.
+func (grandineDriver) binaryRelease(version string, arch string) utils.BinaryRelease {
+	return utils.BinaryRelease{
+		Url:  fmt.Sprintf("https://github.com/grandinetech/grandine/releases/download/%s/grandine-%s-linux-%s", version, version, arch),
+		Link: "/data/bin/grandine",
+	}
+}
+
+// NewGrandineComponent creates a new consensus client component for grandine
+// and returns a pointer to the component
+//
+// Example usage:
+//
+//	client, err := consensusClient.NewGrandineComponent(ctx, "testGrandineConsensusClient", &consensusClient.ConsensusClientComponentArgs{
+//		Connection:     &remote.ConnectionArgs{
+//			User:       cfg.Require("sshUser"),             // username for the ssh connection
+//			Host:       cfg.Require("sshHost"),             // ip address of the host
+//			PrivateKey: cfg.RequireSecret("sshPrivateKey"), // must be a secret, RequireSecret is critical for security
+//		},
+//		Client:         "grandine",             // must be "grandine"
+//		Network:        "mainnet",              // mainnet, sepolia, holesky or hoodi
+//		DeploymentType: "source",               // source or kubernetes
+//		DataDir:        "/data/mainnet/grandine", // path to the data directory
+//	})
+func NewGrandineComponent(ctx *pulumi.Context, name string, args *ConsensusClientComponentArgs, opts ...pulumi.ResourceOption) (*ConsensusClientComponent, error) {
+	return newClientComponent(ctx, name, grandineDriver{}, args, opts...)
+}
@@ -0,0 +1,308 @@
+package consensusClient
+
+import (
+	"os"
+	"path"
+
+	appsv1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/apps/v1"
+	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
+	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// deployKubernetes runs a consensus client as a StatefulSet with a config
+// map, a data volume, the execution jwt secret, its p2p, metrics and beacon
+// api services and the rpc ingress, all named after args.Name in
+// args.Namespace.
+func deployKubernetes(ctx *pulumi.Context, component *ConsensusClientComponent, driver ConsensusClientDriver, args *ConsensusClientComponentArgs) error {
+	names := kubernetesNames(driver, args)
+	ports := driver.Ports()
+	mounts := driver.MountPaths(args.Network)
+	storageSize := pulumi.String(args.PodStorageSize)
+
+	pvcSpec := &corev1.PersistentVolumeClaimSpecArgs{
+		AccessModes: pulumi.StringArray{pulumi.String("ReadWriteOnce")}, // This should match your requirements
+		Resources: &corev1.VolumeResourceRequirementsArgs{
+			Requests: pulumi.StringMap{
+				"storage": storageSize,
+			},
+		},
+		StorageClassName: pulumi.String(args.PodStorageClass),
+	}
+	if args.SnapshotName != "" {
+		pvcSpec.DataSource = &corev1.TypedLocalObjectReferenceArgs{
+			Kind:     pulumi.String("VolumeSnapshot"),
+			Name:     pulumi.String(args.SnapshotName),
+			ApiGroup: pulumi.String("snapshot.storage.k8s.io"),
+		}
+	}
+	_, err := corev1.NewPersistentVolumeClaim(ctx, names.Resource("data"), &corev1.PersistentVolumeClaimArgs{
+		Metadata: names.Metadata("data"),
+		Spec:     pvcSpec,
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	// Create a secret for the execution jwt
+	secretName, err := utils.NewKubernetesSecret(ctx, names.Resource("execution-jwt"), &utils.KubernetesSecretArgs{
+		Name:      names.Object("execution-jwt"),
+		Namespace: names.Namespace,
+		Labels:    names.Labels("execution-jwt"),
+		StringData: pulumi.StringMap{
+			"jwt.hex": args.ExecutionJwt,
+		},
+		Rendering: args.manifestRendering(),
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	// Create a ConfigMap with the content of the client config file
+	configData, err := os.ReadFile(args.ConsensusClientConfigPath)
+	if err != nil {
+		return err
+	}
+	configMap, err := corev1.NewConfigMap(ctx, names.Resource("config"), &corev1.ConfigMapArgs{
+		Data: pulumi.StringMap{
+			driver.ConfigFileName(): pulumi.String(string(configData)),
+		},
+		Metadata: names.Metadata("config"),
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	container := corev1.ContainerArgs{
+		Name:    pulumi.String(names.Name),
+		Image:   pulumi.String(image(driver, args)),
+		Command: pulumi.ToStringArray(args.ConsensusClientContainerCommands),
+		Ports: corev1.ContainerPortArray{
+			corev1.ContainerPortArgs{
+				ContainerPort: pulumi.Int(ports.P2P),
+			},
+			corev1.ContainerPortArgs{
+				ContainerPort: pulumi.Int(ports.P2P),
+				Protocol:      pulumi.String("UDP"),
+			},
+			corev1.ContainerPortArgs{
+				ContainerPort: pulumi.Int(ports.QuicP2P),
+				Protocol:      pulumi.String("UDP"),
+			},
+			corev1.ContainerPortArgs{
+				ContainerPort: pulumi.Int(ports.Metrics),
+			},
+			corev1.ContainerPortArgs{
+				ContainerPort: pulumi.Int(ports.BeaconApi),
+			},
+		},
+		VolumeMounts: corev1.VolumeMountArray{
+			corev1.VolumeMountArgs{
+				Name:      pulumi.String("config"),
+				MountPath: pulumi.String(mounts.Config),
+			},
+			corev1.VolumeMountArgs{
+				Name:      pulumi.String("data"),
+				MountPath: pulumi.String(mounts.Data),
+			},
+			corev1.VolumeMountArgs{
+				Name:      pulumi.String("execution-jwt"),
+				MountPath: pulumi.String(mounts.Jwt),
+			},
+		},
+		Resources: &corev1.ResourceRequirementsArgs{
+			Limits: pulumi.StringMap{
+				"cpu":    pulumi.String(args.CpuLimit),
+				"memory": pulumi.String(args.MemoryLimit),
+			},
+			Requests: pulumi.StringMap{
+				"cpu":    pulumi.String(args.CpuRequest),
+				"memory": pulumi.String(args.MemoryRequest),
+			},
+		},
+	}
+	// explicit commands can refer to the endpoints as $(EXECUTION_ENDPOINT) and $(BUILDER_ENDPOINT)
+	var env corev1.EnvVarArray
+	endpoints := endpointEnvironment(args)
+	for _, name := range []string{"EXECUTION_ENDPOINT", "BUILDER_ENDPOINT"} {
+		if value, ok := endpoints[name]; ok {
+			env = append(env, corev1.EnvVarArgs{
+				Name:  pulumi.String(name),
+				Value: value,
+			})
+		}
+	}
+	if len(env) > 0 {
+		container.Env = env
+	}
+	// without explicit commands the image entrypoint is run with the driver's default flags
+	if len(args.ConsensusClientContainerCommands) == 0 {
+		container.Args = containerArgs(driver, args)
+	}
+
+	// the beacon api answers 206 while syncing, readiness asks for 503 instead
+	if probes := args.Probes(); !probes.Disabled {
+		checks := probes.HttpProbes(ports.BeaconApi, "/eth/v1/node/health", "/eth/v1/node/health?syncing_status=503")
+		container.StartupProbe = checks.Startup
+		container.LivenessProbe = checks.Liveness
+		container.ReadinessProbe = checks.Readiness
+	}
+
+	// Create a stateful set to run the client with a configmap volume and a data persistent volume
+	podLabels := names.Labels("")
+	podLabels["app"] = pulumi.String(names.Name)
+	_, err = appsv1.NewStatefulSet(ctx, names.Resource("set"), &appsv1.StatefulSetArgs{
+		Metadata: names.Metadata(""),
+		Spec: &appsv1.StatefulSetSpecArgs{
+			Replicas: pulumi.Int(1),
+			Selector: &metav1.LabelSelectorArgs{
+				MatchLabels: names.Selector(),
+			},
+			Template: &corev1.PodTemplateSpecArgs{
+				Metadata: &metav1.ObjectMetaArgs{
+					Labels: podLabels,
+				},
+				Spec: &corev1.PodSpecArgs{
+					Containers: corev1.ContainerArray{container},
+					DnsPolicy:  pulumi.String("ClusterFirst"),
+					Volumes: corev1.VolumeArray{
+						corev1.VolumeArgs{
+							Name: pulumi.String("config"),
+							ConfigMap: &corev1.ConfigMapVolumeSourceArgs{
+								Name: configMap.Metadata.Name(),
+							},
+						},
+						corev1.VolumeArgs{
+							Name: pulumi.String("data"),
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSourceArgs{
+								ClaimName: pulumi.String(names.Object("data")),
+							},
+						},
+						corev1.VolumeArgs{
+							Name: pulumi.String("execution-jwt"),
+							Secret: &corev1.SecretVolumeSourceArgs{
+								SecretName: secretName,
+							},
+						},
+					},
+				},
+			},
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	// Create ingress for p2p traffic
+	p2pService, err := corev1.NewService(ctx, names.Resource("p2p-service"), &corev1.ServiceArgs{
+		Spec: &corev1.ServiceSpecArgs{
+			Selector: names.Selector(),
+			Type:     pulumi.String("NodePort"),
+			Ports: corev1.ServicePortArray{
+				corev1.ServicePortArgs{
+					Port: pulumi.Int(ports.P2P),
+					Name: pulumi.String("p2p-tcp"),
+				},
+				corev1.ServicePortArgs{
+					Port:     pulumi.Int(ports.P2P),
+					Protocol: pulumi.String("UDP"),
+					Name:     pulumi.String("p2p-udp"),
+				},
+			},
+		},
+		Metadata: names.Metadata("p2p-service"),
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	// create the metrics service
+	metricsService, err := corev1.NewService(ctx, names.Resource("metrics-service"), &corev1.ServiceArgs{
+		Spec: &corev1.ServiceSpecArgs{
+			Selector: names.Selector(),
+			Type:     pulumi.String("ClusterIP"),
+			Ports: corev1.ServicePortArray{
+				corev1.ServicePortArgs{
+					Port: pulumi.Int(ports.Metrics),
+					Name: pulumi.String("metrics"),
+				},
+			},
+		},
+		Metadata: names.Metadata("metrics-service"),
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	// create the beacon api service
+	beaconApiService, err := corev1.NewService(ctx, names.Resource("beacon-api-service"), &corev1.ServiceArgs{
+		Spec: &corev1.ServiceSpecArgs{
+			Selector: names.Selector(),
+			Type:     pulumi.String("ClusterIP"),
+			Ports: corev1.ServicePortArray{
+				corev1.ServicePortArgs{
+					Port: pulumi.Int(ports.BeaconApi),
+					Name: pulumi.String("beacon-api"),
+				},
+			},
+		},
+		Metadata: names.Metadata("beacon-api-service"),
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	// Expose the beacon api on the ingress host
+	if ingress := utils.EnabledIngress(args.EnableRpcIngress, args.Ingress()); ingress != nil {
+		backends := []utils.IngressBackend{
+			{Name: "rpc", Path: "/", Service: names.Object("beacon-api-service"), Port: ports.BeaconApi},
+		}
+		err = utils.NewIngress(ctx, names, ingress, backends, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{beaconApiService}))
+		if err != nil {
+			return err
+		}
+	}
+
+	return setKubernetesEndpoints(ctx, component, names, ports, args, beaconApiService, metricsService, p2pService)
+}
+
+// optionalString returns input, or an empty string when input is nil.
+func optionalString(input pulumi.StringInput) pulumi.StringInput {
+	if input == nil {
+		return pulumi.String("")
+	}
+	return input
+}
+
+// containerArgs renders the driver's default flags for the client container,
+// pointing it at the data and jwt mounts and the endpoints of args.
+func containerArgs(driver ConsensusClientDriver, args *ConsensusClientComponentArgs) pulumi.StringArrayOutput {
+	mounts := driver.MountPaths(args.Network)
+	flags := ConsensusClientFlags{
+		Network: args.Network,
+		DataDir: mounts.Data,
+		JwtPath: path.Join(mounts.Jwt, "jwt.hex"),
+		Ports:   driver.Ports(),
+	}
+	return pulumi.All(optionalString(args.ExecutionEndpoint), optionalString(args.BuilderEndpoint)).ApplyT(func(endpoints []interface{}) []string {
+		flags.ExecutionEndpoint = endpoints[0].(string)
+		if builder := endpoints[1].(string); builder != "" {
+			return append(driver.Flags(flags), driver.BuilderFlags(builder)...)
+		}
+		return driver.Flags(flags)
+	}).(pulumi.StringArrayOutput)
+}
+
+// endpointEnvironment returns the EXECUTION_ENDPOINT and BUILDER_ENDPOINT
+// variables of the client container for the endpoints set in args.
+func endpointEnvironment(args *ConsensusClientComponentArgs) pulumi.StringMap {
+	env := pulumi.StringMap{}
+	if args.ExecutionEndpoint != nil {
+		env["EXECUTION_ENDPOINT"] = args.ExecutionEndpoint
+	}
+	if args.BuilderEndpoint != nil {
+		env["BUILDER_ENDPOINT"] = args.BuilderEndpoint
+	}
+	return env
+}
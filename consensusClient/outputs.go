@@ -0,0 +1,86 @@
+package consensusClient
+
+import (
+	"fmt"
+
+	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// nodeIdentityPath is the beacon API path reporting a node's ENR.
+const nodeIdentityPath = "/eth/v1/node/identity"
+
+// initOutputs sets every output of component to an empty value so deployments
+// only have to populate the endpoints they know about.
+func initOutputs(component *ConsensusClientComponent) {
+	component.BeaconApiUrl = pulumi.String("").ToStringOutput()
+	component.MetricsUrl = pulumi.String("").ToStringOutput()
+	component.P2pNodePort = pulumi.Int(0).ToIntOutput()
+	component.Enr = pulumi.String("").ToStringOutput()
+}
+
+// copyOutputs copies the outputs of a client component onto its parent.
+func copyOutputs(dst *ConsensusClientComponent, src *ConsensusClientComponent) {
+	dst.BeaconApiUrl = src.BeaconApiUrl
+	dst.MetricsUrl = src.MetricsUrl
+	dst.P2pNodePort = src.P2pNodePort
+	dst.Enr = src.Enr
+}
+
+// registerOutputs registers the outputs of component with the engine.
+func registerOutputs(ctx *pulumi.Context, component *ConsensusClientComponent) error {
+	return ctx.RegisterResourceOutputs(component, pulumi.Map{
+		"beaconApiUrl": component.BeaconApiUrl,
+		"metricsUrl":   component.MetricsUrl,
+		"p2pNodePort":  component.P2pNodePort,
+		"enr":          component.Enr,
+	})
+}
+
+// setSourceEndpoints populates the outputs of a client running directly on the ssh host.
+func setSourceEndpoints(ctx *pulumi.Context, component *ConsensusClientComponent, driver ConsensusClientDriver, args *ConsensusClientComponentArgs) error {
+	ports := driver.Ports()
+	host := args.Connection.Host
+	component.BeaconApiUrl = pulumi.Sprintf("http://%s:%d", host, ports.BeaconApi)
+	component.MetricsUrl = pulumi.Sprintf("http://%s:%d", host, ports.Metrics)
+	component.P2pNodePort = pulumi.Int(ports.P2P).ToIntOutput()
+
+	if args.QueryNodeIdentity {
+		enr, err := utils.NewNodeIdentityQuery(ctx, fmt.Sprintf("queryEnr-%s", resourcePrefix(driver, args)), &utils.NodeIdentityQueryArgs{
+			Connection: args.Connection,
+			Url:        pulumi.Sprintf("http://127.0.0.1:%d%s", ports.BeaconApi, nodeIdentityPath),
+			Field:      "enr",
+		}, pulumi.Parent(component))
+		if err != nil {
+			return err
+		}
+		component.Enr = enr
+	}
+
+	return nil
+}
+
+// setKubernetesEndpoints populates the outputs of a client running in kubernetes
+// from the in-cluster DNS names of its services.
+func setKubernetesEndpoints(ctx *pulumi.Context, component *ConsensusClientComponent, names utils.KubernetesNames, ports ConsensusClientPorts, args *ConsensusClientComponentArgs, beaconApiService *corev1.Service, metricsService *corev1.Service, p2pService *corev1.Service) error {
+	component.BeaconApiUrl = pulumi.Sprintf("http://%s:%d", utils.ClusterServiceHost(beaconApiService), ports.BeaconApi)
+	component.MetricsUrl = pulumi.Sprintf("http://%s:%d", utils.ClusterServiceHost(metricsService), ports.Metrics)
+	component.P2pNodePort = p2pService.Spec.Ports().Index(pulumi.Int(0)).NodePort().Elem()
+
+	if args.QueryNodeIdentity {
+		enr, err := utils.NewNodeIdentityQuery(ctx, names.Resource("enr-query"), &utils.NodeIdentityQueryArgs{
+			Namespace:  beaconApiService.Metadata.Namespace().Elem(),
+			Url:        pulumi.Sprintf("%s%s", component.BeaconApiUrl, nodeIdentityPath),
+			Field:      "enr",
+			Kubeconfig: args.Kubeconfig,
+			Context:    args.KubeContext,
+		}, pulumi.Parent(component))
+		if err != nil {
+			return err
+		}
+		component.Enr = enr
+	}
+
+	return nil
+}
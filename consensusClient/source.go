@@ -0,0 +1,132 @@
+package consensusClient
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// deploySource clones and builds a consensus client on the remote host and
+// runs it as a systemd service.
+func deploySource(ctx *pulumi.Context, component *ConsensusClientComponent, driver ConsensusClientDriver, build *ConsensusClientSourceBuild, args *ConsensusClientComponentArgs) error {
+	// Execute a sequence of commands on the remote server
+	_, err := remote.NewCommand(ctx, fmt.Sprintf("createDataDir-%s", resourcePrefix(driver, args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("mkdir -p %s", args.DataDir),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error creating data directory", nil)
+		return err
+	}
+
+	// clone repo
+	repo, err := remote.NewCommand(ctx, fmt.Sprintf("cloneRepo-%s", resourcePrefix(driver, args)), &remote.CommandArgs{
+		Create:     pulumi.String(utils.SourceRepo{Url: args.SourceRepoUrl, Ref: args.SourceRef}.WithDefaults(build.Repo).CloneCommand(build.RepoDir)),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error cloning repo", nil)
+		return err
+	}
+
+	// run the client specific build steps in order
+	var buildClient pulumi.Resource = repo
+	for _, step := range build.Steps {
+		buildClient, err = remote.NewCommand(ctx, fmt.Sprintf("%s-%s", step.Name, resourcePrefix(driver, args)), &remote.CommandArgs{
+			Create:     step.Command,
+			Connection: args.Connection,
+		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{repo, buildClient}))
+		if err != nil {
+			ctx.Log.Error("Error "+step.Description, nil)
+			return err
+		}
+	}
+
+	if _, err := deployService(ctx, component, driver, args, build.StartScript, build.Binary, buildClient); err != nil {
+		return err
+	}
+
+	return setSourceEndpoints(ctx, component, driver, args)
+}
+
+// deployService runs an installed consensus client as a systemd service with
+// its start script, the client's binary is chowned to the client user unless
+// empty. installed is the resource installing the client.
+func deployService(ctx *pulumi.Context, component *ConsensusClientComponent, driver ConsensusClientDriver, args *ConsensusClientComponentArgs, startScriptName string, binary string, installed pulumi.Resource) (*utils.ServiceDefinitionComponent, error) {
+	// copy start script
+	startScript, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyStartScript-%s", resourcePrefix(driver, args)), &remote.CopyFileArgs{
+		LocalPath:  pulumi.Sprintf("scripts/%s", startScriptName),
+		RemotePath: pulumi.Sprintf("/data/scripts/%s", startScriptName),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error copying start script", nil)
+		return nil, err
+	}
+
+	// script permissions
+	scriptPerms, err := remote.NewCommand(ctx, fmt.Sprintf("scriptPermissions-%s", resourcePrefix(driver, args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chmod +x /data/scripts/%s", startScriptName),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{startScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting script permissions", nil)
+		return nil, err
+	}
+
+	// write the shared engine api jwt, without one the secret is expected to be provisioned already
+	serviceDeps := []pulumi.Resource{installed, scriptPerms}
+	if args.ExecutionJwt != nil {
+		jwtSecret, err := utils.NewJwtSecretFile(ctx, fmt.Sprintf("writeJwtSecret-%s", resourcePrefix(driver, args)), args.Connection, args.ExecutionJwt, pulumi.Parent(component))
+		if err != nil {
+			ctx.Log.Error("Error writing jwt secret", nil)
+			return nil, err
+		}
+		serviceDeps = append(serviceDeps, jwtSecret)
+	}
+
+	// the start scripts read the execution endpoint and jwt path from the service environment
+	environment := pulumi.StringMap{
+		"JWT_SECRET_FILE": pulumi.String(utils.JwtSecretPath),
+	}
+	if args.ExecutionEndpoint != nil {
+		environment["EXECUTION_ENDPOINT"] = args.ExecutionEndpoint
+	}
+	if args.BuilderEndpoint != nil {
+		environment["BUILDER_FLAGS"] = args.BuilderEndpoint.ToStringOutput().ApplyT(func(endpoint string) string {
+			return strings.Join(driver.BuilderFlags(endpoint), " ")
+		}).(pulumi.StringOutput)
+	}
+
+	// create service
+	serviceDefinition, err := utils.NewServiceDefinitionComponent(ctx, fmt.Sprintf("consensusService-%s", resourcePrefix(driver, args)), &utils.ServiceComponentArgs{
+		Connection:  args.Connection,
+		ServiceType: args.Client,
+		Network:     args.Network,
+		Environment: environment,
+	}, pulumi.Parent(component), pulumi.DependsOn(serviceDeps))
+	if err != nil {
+		ctx.Log.Error("Error creating consensus service", nil)
+		return nil, err
+	}
+
+	// group permissions
+	owned := []string{fmt.Sprintf("chown -R %s:%s %s", args.Client, args.Client, args.DataDir)}
+	if binary != "" {
+		owned = append(owned, fmt.Sprintf("chown %s:%s %s", args.Client, args.Client, binary))
+	}
+	owned = append(owned, fmt.Sprintf("chown %s:%s /data/scripts/%s", args.Client, args.Client, startScriptName))
+	_, err = remote.NewCommand(ctx, fmt.Sprintf("setDataDirGroupPermissions-%s", resourcePrefix(driver, args)), &remote.CommandArgs{
+		Create:     pulumi.String(strings.Join(owned, " && ")),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{serviceDefinition, scriptPerms, startScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting group permissions", nil)
+		return nil, err
+	}
+
+	return serviceDefinition, nil
+}
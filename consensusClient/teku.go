@@ -2,17 +2,95 @@ package consensusClient
 
 import (
 	"fmt"
-	"os"
+	"path"
+	"strconv"
 
-	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
-	appsv1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/apps/v1"
-	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
-	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
-	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
 	"github.com/rswanson/node_deployer/utils"
 )
 
+func init() {
+	RegisterConsensusClient(tekuDriver{})
+}
+
+// tekuDriver is the built-in ConsensusClientDriver for teku.
+type tekuDriver struct{}
+
+func (tekuDriver) Client() string { return Teku }
+
+func (tekuDriver) SourceBuild(args *ConsensusClientComponentArgs) *ConsensusClientSourceBuild {
+	return &ConsensusClientSourceBuild{
+		RepoDir: fmt.Sprintf("/data/repos/%s", args.Client),
+		Repo:    utils.SourceRepo{Url: "https://github.com/Consensys/teku.git", Ref: "master"},
+		Steps: []ConsensusClientBuildStep{
+			{
+				Name:        "installJava",
+				Description: "installing java",
+				Command:     pulumi.String("sudo apt update && sudo apt install -y openjdk-21-jre gradle"),
+			},
+			{
+				Name:        "setRepoPermissions",
+				Description: "setting repo permissions",
+				Command:     pulumi.Sprintf("chown -R %s:%s /data/repos/%s", args.Client, args.Client, args.Client),
+			},
+			{
+				Name:        "buildConsensusClient",
+				Description: "building consensus client",
+				Command:     pulumi.Sprintf("cd /data/repos/%s && ./gradlew distTar installDist", args.Client),
+			},
+		},
+		StartScript: fmt.Sprintf("start_%s.sh", args.Client),
+	}
+}
+
+func (tekuDriver) Ports() ConsensusClientPorts {
+	return ConsensusClientPorts{P2P: 9000, QuicP2P: 9001, Metrics: 5054, BeaconApi: 5052}
+}
+
+func (tekuDriver) MountPaths(network string) ConsensusClientMountPaths {
+	return ConsensusClientMountPaths{Config: "/etc/teku", Data: path.Join("/root/.local/share/teku", network), Jwt: "/secrets"}
+}
+
+func (tekuDriver) DefaultImage() string { return "consensys/teku:latest" }
+
+func (tekuDriver) ConfigFileName() string { return "teku.toml" }
+
+func (tekuDriver) Flags(settings ConsensusClientFlags) []string {
+	flags := []string{
+		"--network=" + settings.Network,
+		"--data-path=" + settings.DataDir,
+		"--rest-api-enabled=true",
+		"--rest-api-interface=0.0.0.0",
+		"--rest-api-port=" + strconv.Itoa(settings.Ports.BeaconApi),
+		"--rest-api-host-allowlist=*",
+		"--metrics-enabled=true",
+		"--metrics-interface=0.0.0.0",
+		"--metrics-port=" + strconv.Itoa(settings.Ports.Metrics),
+		"--metrics-host-allowlist=*",
+		"--p2p-port=" + strconv.Itoa(settings.Ports.P2P),
+		"--ee-jwt-secret-file=" + settings.JwtPath,
+	}
+	if settings.ExecutionEndpoint != "" {
+		flags = append(flags, "--ee-endpoint="+settings.ExecutionEndpoint)
+	}
+	return flags
+}
+
+func (tekuDriver) BuilderFlags(endpoint string) []string {
+	return []string{"--builder-endpoint=" + endpoint}
+}
+
+// binaryRelease returns the teku release published by consensys, the jvm
+// distribution runs on every architecture. start_teku.sh runs teku from the
+// gradle install of source deployments.
+func (tekuDriver) binaryRelease(version string, arch string) utils.BinaryRelease {
+	return utils.BinaryRelease{
+		Url:        fmt.Sprintf("https://artifacts.consensys.net/public/teku/raw/names/teku.tar.gz/versions/%s/teku-%s.tar.gz", version, version),
+		Executable: fmt.Sprintf("teku-%s/bin/teku", version),
+		Link:       "/data/repos/teku/build/install/teku/bin/teku",
+	}
+}
+
 // NewTekuComponent creates a new consensus client component for teku
 // and returns a pointer to the component
 //
@@ -30,307 +108,5 @@ import (
 //		DataDir:        "/data/teku",	  // path to the data directory
 //	})
 func NewTekuComponent(ctx *pulumi.Context, name string, args *ConsensusClientComponentArgs, opts ...pulumi.ResourceOption) (*ConsensusClientComponent, error) {
-	if args == nil {
-		args = &ConsensusClientComponentArgs{}
-	}
-
-	component := &ConsensusClientComponent{}
-	err := ctx.RegisterComponentResource(fmt.Sprintf("custom:component:ConsensusClient:%s", args.Client), name, component, opts...)
-	if err != nil {
-		return nil, err
-	}
-
-	// Execute a sequence of commands on the remote server
-	_, err = remote.NewCommand(ctx, fmt.Sprintf("createDataDir-%s", args.Client), &remote.CommandArgs{
-		Create:     pulumi.Sprintf("mkdir -p %s", args.DataDir),
-		Connection: args.Connection,
-	}, pulumi.Parent(component))
-	if err != nil {
-		ctx.Log.Error("Error creating data directory", nil)
-		return nil, err
-	}
-
-	if args.DeploymentType == Source {
-		// Load configuration
-		cfg := config.New(ctx, "")
-
-		// clone repo
-		repo, err := remote.NewCommand(ctx, fmt.Sprintf("cloneRepo-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("git clone -b %s %s /data/repos/%s", cfg.Require("tekuBranch"), cfg.Require("tekuRepoUrl"), args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error cloning repo", nil)
-			return nil, err
-		}
-
-		// install java and gradle
-		javaDeps, err := remote.NewCommand(ctx, fmt.Sprintf("installJava-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.String("sudo apt update && sudo apt install -y openjdk-21-jre gradle"),
-			Connection: args.Connection,
-		}, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error installing java", nil)
-			return nil, err
-		}
-
-		// set repo permissions
-		repoPerms, err := remote.NewCommand(ctx, fmt.Sprintf("setRepoPermissions-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("chown -R %s:%s /data/repos/%s", args.Client, args.Client, args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{repo}))
-		if err != nil {
-			ctx.Log.Error("Error setting repo permissions", nil)
-			return nil, err
-		}
-
-		// build consensus client
-		buildClient, err := remote.NewCommand(ctx, fmt.Sprintf("buildConsensusClient-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("cd /data/repos/%s && ./gradlew distTar installDist", args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{javaDeps, repoPerms}))
-		if err != nil {
-			ctx.Log.Error("Error building consensus client", nil)
-			return nil, err
-		}
-
-		// copy start script
-		startScript, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyStartScript-%s", args.Client), &remote.CopyFileArgs{
-			LocalPath:  pulumi.Sprintf("scripts/start_%s.sh", args.Client),
-			RemotePath: pulumi.Sprintf("/data/scripts/start_%s.sh", args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error copying start script", nil)
-			return nil, err
-		}
-
-		// script permissions
-		scriptPerms, err := remote.NewCommand(ctx, fmt.Sprintf("scriptPermissions-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("chmod +x /data/scripts/start_%s.sh", args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{startScript}))
-		if err != nil {
-			ctx.Log.Error("Error setting script permissions", nil)
-			return nil, err
-		}
-
-		// create service
-		serviceDefinition, err := utils.NewServiceDefinitionComponent(ctx, fmt.Sprintf("consensusService-%s", args.Client), &utils.ServiceComponentArgs{
-			Connection:  args.Connection,
-			ServiceType: args.Client,
-			Network:     args.Network,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{buildClient, scriptPerms}))
-		if err != nil {
-			ctx.Log.Error("Error creating consensus service", nil)
-			return nil, err
-		}
-
-		// group permissions
-		_, err = remote.NewCommand(ctx, fmt.Sprintf("setDataDirGroupPermissions-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("chown -R %s:%s %s && chown %s:%s /data/scripts/start_%s.sh", args.Client, args.Client, args.DataDir, args.Client, args.Client, args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{serviceDefinition, scriptPerms, startScript}))
-		if err != nil {
-			ctx.Log.Error("Error setting group permissions", nil)
-			return nil, err
-		}
-
-	} else if args.DeploymentType == Kubernetes {
-		storageSize := pulumi.String(args.PodStorageSize) // 30Gi size for holesky
-		_, err = corev1.NewPersistentVolumeClaim(ctx, "teku-data", &corev1.PersistentVolumeClaimArgs{
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("teku-data"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("teku-data"),
-					"app.kubernetes.io/part-of": pulumi.String("teku"),
-				},
-			},
-			Spec: &corev1.PersistentVolumeClaimSpecArgs{
-				AccessModes: pulumi.StringArray{pulumi.String("ReadWriteOnce")}, // This should match your requirements
-				Resources: &corev1.VolumeResourceRequirementsArgs{
-					Requests: pulumi.StringMap{
-						"storage": storageSize,
-					},
-				},
-				StorageClassName: pulumi.String(args.PodStorageClass),
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
-
-		// Create a secret for the execution jwt
-		secret, err := corev1.NewSecret(ctx, "execution-jwt", &corev1.SecretArgs{
-			StringData: pulumi.StringMap{
-				"jwt.hex": pulumi.String(args.ExecutionJwt),
-			},
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("execution-jwt"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name": pulumi.String("execution-jwt"),
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
-
-		// Create a ConfigMap with the content of teku.toml
-		tekuTomlData, err := os.ReadFile(args.ConsensusClientConfigPath)
-		if err != nil {
-			return nil, err
-		}
-		tekuConfigData, err := corev1.NewConfigMap(ctx, "teku-config", &corev1.ConfigMapArgs{
-			Data: pulumi.StringMap{
-				"teku.toml": pulumi.String(string(tekuTomlData)),
-			},
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("teku-config"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("teku-config"),
-					"app.kubernetes.io/part-of": pulumi.String("teku"),
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
-
-		// Create a stateful set to run a teku node with a configmap volume and a data persistent volume
-		_, err = appsv1.NewStatefulSet(ctx, "teku-set", &appsv1.StatefulSetArgs{
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("teku"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("teku-set"),
-					"app.kubernetes.io/part-of": pulumi.String("teku"),
-				},
-			},
-			Spec: &appsv1.StatefulSetSpecArgs{
-				Replicas: pulumi.Int(1),
-				Selector: &metav1.LabelSelectorArgs{
-					MatchLabels: pulumi.StringMap{
-						"app": pulumi.String("teku"),
-					},
-				},
-				Template: &corev1.PodTemplateSpecArgs{
-					Metadata: &metav1.ObjectMetaArgs{
-						Labels: pulumi.StringMap{
-							"app":                       pulumi.String("teku"),
-							"app.kubernetes.io/name":    pulumi.String("teku"),
-							"app.kubernetes.io/part-of": pulumi.String("teku"),
-						},
-					},
-					Spec: &corev1.PodSpecArgs{
-						Containers: corev1.ContainerArray{
-							corev1.ContainerArgs{
-								Name:    pulumi.String("teku"),
-								Image:   pulumi.String(args.ConsensusClientImage),
-								Command: pulumi.ToStringArray(args.ConsensusClientContainerCommands),
-								Ports: corev1.ContainerPortArray{
-									corev1.ContainerPortArgs{
-										ContainerPort: pulumi.Int(9000),
-									},
-									corev1.ContainerPortArgs{
-										ContainerPort: pulumi.Int(9000),
-										Protocol:      pulumi.String("UDP"),
-									},
-									corev1.ContainerPortArgs{
-										ContainerPort: pulumi.Int(9001),
-										Protocol:      pulumi.String("UDP"),
-									},
-									corev1.ContainerPortArgs{
-										ContainerPort: pulumi.Int(5054),
-									},
-									corev1.ContainerPortArgs{
-										ContainerPort: pulumi.Int(5052),
-									},
-								},
-								VolumeMounts: corev1.VolumeMountArray{
-									corev1.VolumeMountArgs{
-										Name:      pulumi.String("teku-config"),
-										MountPath: pulumi.String("/etc/teku"),
-									},
-									corev1.VolumeMountArgs{
-										Name:      pulumi.String("teku-data"),
-										MountPath: pulumi.String("/root/.local/share/teku/holesky"),
-									},
-									corev1.VolumeMountArgs{
-										Name:      pulumi.String("execution-jwt"),
-										MountPath: pulumi.String("/secrets"),
-									},
-								},
-								Resources: &corev1.ResourceRequirementsArgs{
-									Limits: pulumi.StringMap{
-										"cpu":    pulumi.String(args.CpuLimit),
-										"memory": pulumi.String(args.MemoryLimit),
-									},
-									Requests: pulumi.StringMap{
-										"cpu":    pulumi.String(args.CpuRequest),
-										"memory": pulumi.String(args.MemoryRequest),
-									},
-								},
-							},
-						},
-						DnsPolicy: pulumi.String("ClusterFirst"),
-						Volumes: corev1.VolumeArray{
-							corev1.VolumeArgs{
-								Name: pulumi.String("teku-config"),
-								ConfigMap: &corev1.ConfigMapVolumeSourceArgs{
-									Name: tekuConfigData.Metadata.Name(),
-								},
-							},
-							corev1.VolumeArgs{
-								Name: pulumi.String("teku-data"),
-								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSourceArgs{
-									ClaimName: pulumi.String("teku-data"),
-								},
-							},
-							corev1.VolumeArgs{
-								Name: pulumi.String("execution-jwt"),
-								Secret: &corev1.SecretVolumeSourceArgs{
-									SecretName: secret.Metadata.Name(),
-								},
-							},
-						},
-					},
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
-
-		// Create ingress for teku p2p traffic on port 9000
-		_, err = corev1.NewService(ctx, "teku-p2p-service", &corev1.ServiceArgs{
-			Spec: &corev1.ServiceSpecArgs{
-				Selector: pulumi.StringMap{"app": pulumi.String("teku")},
-				Type:     pulumi.String("NodePort"),
-				Ports: corev1.ServicePortArray{
-					corev1.ServicePortArgs{
-						Port: pulumi.Int(9000),
-						Name: pulumi.String("p2p-tcp"),
-					},
-					corev1.ServicePortArgs{
-						Port:     pulumi.Int(9000),
-						Protocol: pulumi.String("UDP"),
-						Name:     pulumi.String("p2p-udp"),
-					},
-				},
-			},
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("teku-p2p-service"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("teku-p2p-service"),
-					"app.kubernetes.io/part-of": pulumi.String("teku"),
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
-
-	}
-
-	return component, nil
+	return newClientComponent(ctx, name, tekuDriver{}, args, opts...)
 }
@@ -0,0 +1,229 @@
+package executionClient
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+func init() {
+	RegisterExecutionClient(besuDriver{})
+}
+
+// besuDriver is the built-in ExecutionClientDriver for besu.
+type besuDriver struct{}
+
+func (besuDriver) Client() string { return Besu }
+
+func (besuDriver) BuildHooks() map[string]ExecutionClientBuildHook {
+	return map[string]ExecutionClientBuildHook{
+		Source:     besuSource,
+		Binary:     binaryHook(besuDriver{}, besuRelease),
+		Docker:     dockerHook(besuDriver{}, besuContainer),
+		Kubernetes: kubernetesHook(besuDriver{}, besuContainer),
+	}
+}
+
+// besuRelease are the besu releases published on github, the jvm
+// distribution runs on every architecture.
+var besuRelease = binaryRelease{
+	url: func(version string, arch string) string {
+		return fmt.Sprintf("https://github.com/hyperledger/besu/releases/download/%s/besu-%s.tar.gz", version, version)
+	},
+	executable: func(version string, arch string) string {
+		return fmt.Sprintf("besu-%s/bin/besu", version)
+	},
+	// start_besu.sh runs besu from the gradle install of source deployments
+	link: "/data/repos/besu/build/install/besu/bin/besu",
+}
+
+// besuContainer is where the besu container mounts its volumes in kubernetes and docker.
+var besuContainer = containerSpec{
+	configDir: "/etc/besu",
+	dataDir:   "/opt/besu/data",
+	jwtDir:    "/etc/besu/execution-jwt",
+	args:      besuContainerArgs,
+}
+
+func (besuDriver) DefaultPorts() ExecutionClientPorts {
+	return ExecutionClientPorts{P2P: 30303, Metrics: 9545, Http: 8545, Ws: 8546, AuthRpc: 8551}
+}
+
+// besuSourceRepo is the repository besu is built from unless SourceRepoUrl and SourceRef are set.
+var besuSourceRepo = utils.SourceRepo{Url: "https://github.com/hyperledger/besu.git", Ref: "main"}
+
+func (besuDriver) DefaultImage() string { return "hyperledger/besu:latest" }
+
+func (besuDriver) ConfigFileName() string { return "besu.toml" }
+
+// NewBesuComponent creates a new ExecutionClientComponent resource that represents a besu client
+// and the necessary infrastructure to run it.
+//
+// Example usage:
+//
+//	client, err := executionClient.NewBesuComponent(ctx, "testBesuExecutionClient", &executionClient.ExecutionClientComponentArgs{
+//		Connection:     &remote.ConnectionArgs{
+//			User:       cfg.Require("sshUser"), // username for the ssh connection
+//			Host:       cfg.Require("sshHost"), // ip address of the host
+//			PrivateKey: cfg.RequireSecret("sshPrivateKey"), // must be a secret, RequireSecret is critical for security
+//		},
+//		Client:            "besu", // must be "besu"
+//		Network:           "mainnet", // mainnet, sepolia, holesky or hoodi
+//		DeploymentType:    "source", // source or kubernetes
+//		DataDir:           "/data/mainnet/besu", // path to the data directory
+//		BesuStorageFormat: "forest", // bonsai (default) or forest for archive nodes
+//	})
+func NewBesuComponent(ctx *pulumi.Context, name string, args *ExecutionClientComponentArgs, opts ...pulumi.ResourceOption) (*ExecutionClientComponent, error) {
+	return newClientComponent(ctx, name, besuDriver{}, args, opts...)
+}
+
+// besuStorageFormat returns the value of besu's --data-storage-format flag.
+func besuStorageFormat(args *ExecutionClientComponentArgs) string {
+	if args.BesuStorageFormat == "" {
+		return strings.ToUpper(Bonsai)
+	}
+	return strings.ToUpper(args.BesuStorageFormat)
+}
+
+// besuContainerArgs returns the flags the besu container runs with, matching its mounts.
+func besuContainerArgs(args *ExecutionClientComponentArgs, spec containerSpec) []string {
+	ports := besuDriver{}.DefaultPorts()
+	return []string{
+		"--config-file=" + path.Join(spec.configDir, besuDriver{}.ConfigFileName()),
+		"--network=" + args.Network,
+		"--data-path=" + spec.dataDir,
+		"--data-storage-format=" + besuStorageFormat(args),
+		"--engine-jwt-secret=" + path.Join(spec.jwtDir, "jwt.hex"),
+		fmt.Sprintf("--engine-rpc-port=%d", ports.AuthRpc),
+		"--engine-host-allowlist=*",
+		"--rpc-http-enabled",
+		"--rpc-http-host=0.0.0.0",
+		fmt.Sprintf("--rpc-http-port=%d", ports.Http),
+		"--rpc-http-api=" + strings.ToUpper(httpApi(args, "eth,net,web3")),
+		"--rpc-ws-enabled",
+		"--rpc-ws-host=0.0.0.0",
+		fmt.Sprintf("--rpc-ws-port=%d", ports.Ws),
+		"--host-allowlist=*",
+		"--metrics-enabled",
+		"--metrics-host=0.0.0.0",
+		fmt.Sprintf("--metrics-port=%d", ports.Metrics),
+		fmt.Sprintf("--p2p-port=%d", ports.P2P),
+	}
+}
+
+// besuSource builds besu from source on the remote host and runs it as a systemd service.
+func besuSource(ctx *pulumi.Context, component *ExecutionClientComponent, args *ExecutionClientComponentArgs) error {
+	// Execute a sequence of commands on the remote server
+	_, err := remote.NewCommand(ctx, fmt.Sprintf("createDataDir-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("mkdir -p %s", args.DataDir),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error creating data directory", nil)
+		return err
+	}
+
+	// clone repo
+	repo, err := remote.NewCommand(ctx, fmt.Sprintf("cloneRepo-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.String(sourceRepo(args, besuSourceRepo).CloneCommand(fmt.Sprintf("/data/repos/%s", args.Client))),
+		Update:     pulumi.String(sourceRepo(args, besuSourceRepo).CheckoutCommand(fmt.Sprintf("/data/repos/%s", args.Client))),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error cloning repo", nil)
+		return err
+	}
+
+	// install java, gradle is provided by the repository's wrapper
+	javaDeps, err := remote.NewCommand(ctx, fmt.Sprintf("installJava-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.String("sudo apt update && sudo apt install -y openjdk-21-jdk"),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error installing java", nil)
+		return err
+	}
+
+	// set repo permissions
+	repoPerms, err := remote.NewCommand(ctx, fmt.Sprintf("setRepoPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chown -R %s:%s /data/repos/%s", args.Client, args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{repo}))
+	if err != nil {
+		ctx.Log.Error("Error setting repo permissions", nil)
+		return err
+	}
+
+	// build execution client into /data/repos/besu/build/install/besu
+	buildClient, err := remote.NewCommand(ctx, fmt.Sprintf("buildExecutionClient-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("cd /data/repos/%s && sudo -u %s ./gradlew installDist", args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{javaDeps, repoPerms}))
+	if err != nil {
+		ctx.Log.Error("Error building execution client", nil)
+		return err
+	}
+
+	// copy start script
+	startScript, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyStartScript-%s", resourcePrefix(args)), &remote.CopyFileArgs{
+		LocalPath:  pulumi.Sprintf("scripts/start_%s.sh", args.Client),
+		RemotePath: pulumi.Sprintf("/data/scripts/start_%s.sh", args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error copying start script", nil)
+		return err
+	}
+
+	// script permissions
+	scriptPerms, err := remote.NewCommand(ctx, fmt.Sprintf("scriptPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chmod +x /data/scripts/start_%s.sh", args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{startScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting script permissions", nil)
+		return err
+	}
+
+	// write the shared engine api jwt
+	jwtSecret, err := sourceJwtSecret(ctx, component, args)
+	if err != nil {
+		return err
+	}
+
+	// the start script reads the network, data dir, storage format and jwt path from the service environment
+	environment := pulumi.StringMap{
+		"NETWORK":         pulumi.String(args.Network),
+		"DATA_DIR":        pulumi.String(args.DataDir),
+		"STORAGE_FORMAT":  pulumi.String(besuStorageFormat(args)),
+		"JWT_SECRET_FILE": pulumi.String(utils.JwtSecretPath),
+	}
+
+	// create service
+	serviceDefinition, err := utils.NewServiceDefinitionComponent(ctx, fmt.Sprintf("executionService-%s", resourcePrefix(args)), &utils.ServiceComponentArgs{
+		Connection:  args.Connection,
+		ServiceType: args.Client,
+		Network:     args.Network,
+		Environment: environment,
+	}, pulumi.Parent(component), pulumi.DependsOn(append([]pulumi.Resource{buildClient, scriptPerms}, jwtSecret...)))
+	if err != nil {
+		ctx.Log.Error("Error creating execution service", nil)
+		return err
+	}
+
+	// group permissions
+	_, err = remote.NewCommand(ctx, fmt.Sprintf("setDataDirGroupPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chown -R %s:%s %s && chown %s:%s /data/scripts/start_%s.sh", args.Client, args.Client, args.DataDir, args.Client, args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{serviceDefinition, scriptPerms, startScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting group permissions", nil)
+		return err
+	}
+
+	return setSourceEndpoints(ctx, component, besuDriver{}.DefaultPorts(), args)
+}
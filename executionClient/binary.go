@@ -0,0 +1,135 @@
+package executionClient
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// binaryRelease describes the official release downloads of an execution client.
+type binaryRelease struct {
+	// url returns the download url of a version built for arch.
+	url func(version string, arch string) string
+	// executable returns the path of the client executable inside the
+	// extracted release, nil when the download is the executable itself.
+	executable func(version string, arch string) string
+	// link is where the executable is linked, the path the client's start script runs.
+	link string
+}
+
+// release returns the release args.BinaryVersion is installed from.
+func (release binaryRelease) release(args *ExecutionClientComponentArgs) utils.BinaryRelease {
+	arch := args.BinaryArch
+	if arch == "" {
+		arch = utils.DefaultArch
+	}
+	url := args.BinaryUrl
+	if url == "" {
+		url = release.url(args.BinaryVersion, arch)
+	}
+	executable := ""
+	if release.executable != nil {
+		executable = release.executable(args.BinaryVersion, arch)
+	}
+	return utils.BinaryRelease{
+		Client:     args.Client,
+		Version:    args.BinaryVersion,
+		Url:        url,
+		Sha256:     args.BinarySha256,
+		PgpKey:     args.BinaryPgpKey,
+		Executable: executable,
+		Link:       release.link,
+	}
+}
+
+// binaryHook returns the build hook installing the client from release.
+func binaryHook(driver ExecutionClientDriver, release binaryRelease) ExecutionClientBuildHook {
+	return func(ctx *pulumi.Context, component *ExecutionClientComponent, args *ExecutionClientComponentArgs) error {
+		return deployBinary(ctx, component, driver, release.release(args), args)
+	}
+}
+
+// deployBinary installs a verified release of an execution client on the ssh
+// host and runs it as a systemd service with the start script of source
+// deployments. The service is restarted when a new release is installed.
+func deployBinary(ctx *pulumi.Context, component *ExecutionClientComponent, driver ExecutionClientDriver, release utils.BinaryRelease, args *ExecutionClientComponentArgs) error {
+	dataDir := args.DataDir
+	if dataDir == "" {
+		dataDir = fmt.Sprintf("/data/%s/%s", args.Network, args.Client)
+	}
+
+	_, err := remote.NewCommand(ctx, fmt.Sprintf("createDataDir-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("mkdir -p %s", dataDir),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error creating data directory", nil)
+		return err
+	}
+
+	// download, verify and link the release
+	install, err := utils.NewBinaryRelease(ctx, fmt.Sprintf("installRelease-%s", resourcePrefix(args)), args.Connection, release, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error installing release", nil)
+		return err
+	}
+
+	// copy start script
+	startScript, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyStartScript-%s", resourcePrefix(args)), &remote.CopyFileArgs{
+		LocalPath:  pulumi.Sprintf("scripts/start_%s.sh", args.Client),
+		RemotePath: pulumi.Sprintf("/data/scripts/start_%s.sh", args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error copying start script", nil)
+		return err
+	}
+
+	// script permissions
+	scriptPerms, err := remote.NewCommand(ctx, fmt.Sprintf("scriptPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chmod +x /data/scripts/start_%s.sh", args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{startScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting script permissions", nil)
+		return err
+	}
+
+	// write the shared engine api jwt
+	jwtSecret, err := sourceJwtSecret(ctx, component, args)
+	if err != nil {
+		return err
+	}
+
+	// create service
+	serviceDefinition, err := utils.NewServiceDefinitionComponent(ctx, fmt.Sprintf("executionService-%s", resourcePrefix(args)), &utils.ServiceComponentArgs{
+		Connection:  args.Connection,
+		ServiceType: args.Client,
+		Network:     args.Network,
+	}, pulumi.Parent(component), pulumi.DependsOn(append([]pulumi.Resource{install, scriptPerms}, jwtSecret...)))
+	if err != nil {
+		ctx.Log.Error("Error creating execution service", nil)
+		return err
+	}
+
+	// group permissions
+	_, err = remote.NewCommand(ctx, fmt.Sprintf("setDataDirGroupPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chown -R %s:%s %s && chown %s:%s /data/scripts/start_%s.sh", args.Client, args.Client, dataDir, args.Client, args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{serviceDefinition, scriptPerms}))
+	if err != nil {
+		ctx.Log.Error("Error setting group permissions", nil)
+		return err
+	}
+
+	// pick up new releases
+	_, err = utils.NewServiceRestart(ctx, fmt.Sprintf("restartService-%s", resourcePrefix(args)), args.Connection, args.Client, args.Network, release, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{serviceDefinition}))
+	if err != nil {
+		ctx.Log.Error("Error restarting execution service", nil)
+		return err
+	}
+
+	return setSourceEndpoints(ctx, component, driver.DefaultPorts(), args)
+}
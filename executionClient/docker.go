@@ -0,0 +1,185 @@
+package executionClient
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// containerSpec describes the container of an execution client, it is shared
+// by the kubernetes and docker deployments of the client.
+type containerSpec struct {
+	// configDir, dataDir and jwtDir are where the config, data and execution
+	// jwt volumes are mounted inside the container.
+	configDir string
+	dataDir   string
+	jwtDir    string
+	// args renders the arguments the image entrypoint runs with unless
+	// ExecutionClientContainerCommands is set, nil for images run without any.
+	args func(args *ExecutionClientComponentArgs, spec containerSpec) []string
+	// ports returns the ports opened in addition to the driver's ports.
+	ports func(args *ExecutionClientComponentArgs) []utils.DockerPort
+	// volumes are mounted in addition to the config, data and jwt volumes,
+	// their host paths are relative to the data directory.
+	volumes []utils.DockerVolume
+	// claims are the persistent volumes kubernetes deployments mount for volumes.
+	claims []volumeClaim
+	// servicePorts returns which of ports are exposed by the p2p and the
+	// internal service of kubernetes deployments.
+	servicePorts func(args *ExecutionClientComponentArgs) (p2p corev1.ServicePortArray, internal corev1.ServicePortArray)
+	// configFiles returns local files added to the config map of kubernetes
+	// deployments by key, next to the client config.
+	configFiles func(args *ExecutionClientComponentArgs) map[string]string
+	// initCommand returns a shell command run by an init container of
+	// kubernetes deployments before the client starts, empty for none.
+	initCommand func(args *ExecutionClientComponentArgs, spec containerSpec) string
+}
+
+// containerArgs returns the arguments of the client container.
+func (spec containerSpec) containerArgs(args *ExecutionClientComponentArgs) pulumi.StringArray {
+	if len(args.ExecutionClientContainerCommands) > 0 || spec.args == nil {
+		return pulumi.StringArray{}
+	}
+	return pulumi.ToStringArray(spec.args(args, spec))
+}
+
+// dockerHook returns the build hook running the client described by spec as a
+// docker container.
+func dockerHook(driver ExecutionClientDriver, spec containerSpec) ExecutionClientBuildHook {
+	return func(ctx *pulumi.Context, component *ExecutionClientComponent, args *ExecutionClientComponentArgs) error {
+		return deployDocker(ctx, component, driver, spec, args)
+	}
+}
+
+// deployDocker runs an execution client as a docker container on the ssh host.
+// The container is attached to the docker network of args.Network so consensus
+// clients on the same host reach its engine api by container name, the engine
+// api is only published on localhost. The data directory, the config file and
+// the shared jwt are bind mounted where the kubernetes deployment mounts them.
+func deployDocker(ctx *pulumi.Context, component *ExecutionClientComponent, driver ExecutionClientDriver, spec containerSpec, args *ExecutionClientComponentArgs) error {
+	ports := driver.DefaultPorts()
+	name := containerName(driver, args)
+	dataDir := args.DataDir
+	if dataDir == "" {
+		dataDir = fmt.Sprintf("/data/%s/%s", args.Network, name)
+	}
+
+	network, err := utils.NewDockerNetwork(ctx, fmt.Sprintf("dockerNetwork-%s", name), args.Connection, utils.DockerNetworkName(args.Network), pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error creating docker network", nil)
+		return err
+	}
+	containerDeps := []pulumi.Resource{network}
+
+	volumes := []utils.DockerVolume{
+		{HostPath: dataDir, ContainerPath: spec.dataDir},
+		{HostPath: path.Dir(utils.JwtSecretPath), ContainerPath: spec.jwtDir, ReadOnly: true},
+	}
+	for _, volume := range spec.volumes {
+		volume.HostPath = path.Join(dataDir, volume.HostPath)
+		volumes = append(volumes, volume)
+	}
+
+	// copy the client config next to the data directory
+	if args.ExecutionClientConfigPath != "" {
+		configDir := fmt.Sprintf("/data/config/%s", name)
+		// the jwt is mounted inside the read only config mount, docker cannot
+		// create its mountpoint there
+		dirs := []string{configDir}
+		if jwtDir, ok := strings.CutPrefix(spec.jwtDir, spec.configDir+"/"); ok {
+			dirs = append(dirs, path.Join(configDir, jwtDir))
+		}
+		createConfigDir, err := remote.NewCommand(ctx, fmt.Sprintf("createConfigDir-%s", name), &remote.CommandArgs{
+			Create:     pulumi.String("mkdir -p " + strings.Join(dirs, " ")),
+			Connection: args.Connection,
+		}, pulumi.Parent(component))
+		if err != nil {
+			ctx.Log.Error("Error creating config directory", nil)
+			return err
+		}
+		config, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyConfig-%s", name), &remote.CopyFileArgs{
+			LocalPath:  pulumi.String(args.ExecutionClientConfigPath),
+			RemotePath: pulumi.String(path.Join(configDir, driver.ConfigFileName())),
+			Connection: args.Connection,
+		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{createConfigDir}))
+		if err != nil {
+			ctx.Log.Error("Error copying config", nil)
+			return err
+		}
+		volumes = append(volumes, utils.DockerVolume{HostPath: configDir, ContainerPath: spec.configDir, ReadOnly: true})
+		containerDeps = append(containerDeps, config)
+	}
+
+	// write the shared engine api jwt
+	jwtSecret, err := sourceJwtSecret(ctx, component, args)
+	if err != nil {
+		return err
+	}
+	containerDeps = append(containerDeps, jwtSecret...)
+
+	published := []utils.DockerPort{
+		{Port: ports.P2P},
+		{Port: ports.P2P, Protocol: "udp"},
+		{Port: ports.Http},
+		{Port: ports.Metrics},
+		{Port: ports.AuthRpc, HostIp: "127.0.0.1"},
+	}
+	// clients serving websockets on the http port get no separate ws port
+	if ports.Ws != ports.Http {
+		published = append(published, utils.DockerPort{Port: ports.Ws})
+	}
+	if ports.Torrent != 0 {
+		published = append(published, utils.DockerPort{Port: ports.Torrent}, utils.DockerPort{Port: ports.Torrent, Protocol: "udp"})
+	}
+	if spec.ports != nil {
+		published = append(published, spec.ports(args)...)
+	}
+
+	environment := pulumi.StringMap{}
+	for key, value := range args.Environment {
+		environment[key] = pulumi.String(value)
+	}
+
+	_, err = utils.NewDockerContainer(ctx, fmt.Sprintf("dockerContainer-%s", name), &utils.DockerContainerArgs{
+		Connection:  args.Connection,
+		Name:        name,
+		Image:       image(driver, args),
+		Command:     args.ExecutionClientContainerCommands,
+		Args:        spec.containerArgs(args),
+		Network:     utils.DockerNetworkName(args.Network),
+		Groups:      []string{utils.JwtSecretGroup},
+		Volumes:     volumes,
+		Ports:       published,
+		Environment: environment,
+	}, pulumi.Parent(component), pulumi.DependsOn(containerDeps))
+	if err != nil {
+		ctx.Log.Error("Error creating docker container", nil)
+		return err
+	}
+
+	if err := setSourceEndpoints(ctx, component, ports, args); err != nil {
+		return err
+	}
+	// unlike the start scripts the container serves the rpc and metrics on
+	// every interface, they are published on the ssh host's address
+	host := args.Connection.Host
+	component.HttpRpcUrl = pulumi.Sprintf("http://%s:%d", host, ports.Http)
+	component.WsRpcUrl = pulumi.Sprintf("ws://%s:%d", host, ports.Ws)
+	component.MetricsUrl = pulumi.Sprintf("http://%s:%d", host, ports.Metrics)
+	// consensus clients in the docker network reach the engine api by container name
+	component.EngineApiUrl = pulumi.Sprintf("http://%s:%d", name, ports.AuthRpc)
+	return nil
+}
+
+// containerName returns the name of the client container, args.Name or the client.
+func containerName(driver ExecutionClientDriver, args *ExecutionClientComponentArgs) string {
+	if args.Name != "" {
+		return args.Name
+	}
+	return driver.Client()
+}
@@ -0,0 +1,223 @@
+package executionClient
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// ExecutionClientBuildHook creates the resources needed to run an execution
+// client for a single deployment type. All resources should be created with
+// pulumi.Parent(component) and the hook should set the endpoint outputs of
+// component it knows about, the others are left empty.
+type ExecutionClientBuildHook func(ctx *pulumi.Context, component *ExecutionClientComponent, args *ExecutionClientComponentArgs) error
+
+// ExecutionClientPorts holds the ports an execution client listens on.
+type ExecutionClientPorts struct {
+	P2P     int
+	Metrics int
+	Http    int
+	Ws      int
+	AuthRpc int
+	// Torrent is the port of the client's snapshot downloader, 0 for clients without one.
+	Torrent int
+}
+
+// ExecutionClientDriver describes how to deploy a specific execution client.
+// Drivers for geth, reth, reth-exex, nethermind, erigon, besu and op-geth are registered by default,
+// additional clients can be added with RegisterExecutionClient.
+//
+// Example usage:
+//
+//	type myGethDriver struct{}
+//
+//	func (myGethDriver) Client() string { return "my-geth" }
+//	func (myGethDriver) BuildHooks() map[string]executionClient.ExecutionClientBuildHook {
+//		return map[string]executionClient.ExecutionClientBuildHook{
+//			executionClient.Kubernetes: deployMyGethToKubernetes,
+//		}
+//	}
+//	...
+//
+//	func init() {
+//		executionClient.RegisterExecutionClient(myGethDriver{})
+//	}
+type ExecutionClientDriver interface {
+	// Client returns the name used to select this driver in ExecutionClientComponentArgs.Client.
+	Client() string
+	// BuildHooks returns the build hook for every deployment type the client supports.
+	BuildHooks() map[string]ExecutionClientBuildHook
+	// DefaultPorts returns the ports the client listens on unless configured otherwise.
+	DefaultPorts() ExecutionClientPorts
+	// DefaultImage returns the container image used when ExecutionClientImage is empty.
+	DefaultImage() string
+	// ConfigFileName returns the file name the client config is stored under.
+	ConfigFileName() string
+}
+
+// componentTyper is implemented by built-in drivers whose component type
+// predates the driver registry and has to stay stable to keep existing URNs.
+type componentTyper interface {
+	componentType(args *ExecutionClientComponentArgs) string
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]ExecutionClientDriver)
+)
+
+// RegisterExecutionClient makes an execution client driver available to
+// NewExecutionClientComponent under the name returned by driver.Client().
+// It panics if driver is nil or a driver with the same name is already registered.
+func RegisterExecutionClient(driver ExecutionClientDriver) {
+	if driver == nil {
+		panic("executionClient: RegisterExecutionClient driver is nil")
+	}
+
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if _, dup := drivers[driver.Client()]; dup {
+		panic("executionClient: RegisterExecutionClient called twice for client " + driver.Client())
+	}
+	drivers[driver.Client()] = driver
+}
+
+// LookupExecutionClient returns the driver registered for client.
+func LookupExecutionClient(client string) (ExecutionClientDriver, bool) {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	driver, ok := drivers[client]
+	return driver, ok
+}
+
+// ExecutionClients returns the sorted names of all registered execution clients.
+func ExecutionClients() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	clients := make([]string, 0, len(drivers))
+	for client := range drivers {
+		clients = append(clients, client)
+	}
+	sort.Strings(clients)
+	return clients
+}
+
+// newClientComponent registers the component for a single execution client
+// and runs the driver's build hook for the requested deployment type.
+func newClientComponent(ctx *pulumi.Context, name string, driver ExecutionClientDriver, args *ExecutionClientComponentArgs, opts ...pulumi.ResourceOption) (*ExecutionClientComponent, error) {
+	if args == nil {
+		args = &ExecutionClientComponentArgs{}
+	}
+
+	componentType := fmt.Sprintf("custom:component:ExecutionClient:%s", driver.Client())
+	if typer, ok := driver.(componentTyper); ok {
+		componentType = typer.componentType(args)
+	}
+
+	// kubernetes objects are written to the manifest directory instead of the cluster
+	if rendering := args.manifestRendering(); rendering != nil {
+		provider, err := utils.NewManifestProvider(ctx, fmt.Sprintf("%s-manifests", name), rendering, opts...)
+		if err != nil {
+			ctx.Log.Error("Error creating manifest provider", nil)
+			return nil, err
+		}
+		opts = append(append([]pulumi.ResourceOption{}, opts...), pulumi.Providers(provider))
+	}
+
+	component := &ExecutionClientComponent{}
+	err := ctx.RegisterComponentResource(componentType, name, component, opts...)
+	if err != nil {
+		return nil, err
+	}
+	initOutputs(component)
+
+	if hook, ok := driver.BuildHooks()[args.DeploymentType]; ok {
+		if err := hook(ctx, component, args); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := registerOutputs(ctx, component); err != nil {
+		return nil, err
+	}
+
+	return component, nil
+}
+
+// image returns the configured container image or the driver default, reth
+// runs base from the op-reth image since the mainline one lacks its chain spec.
+func image(driver ExecutionClientDriver, args *ExecutionClientComponentArgs) string {
+	if args.ExecutionClientImage != "" {
+		return args.ExecutionClientImage
+	}
+	if (args.Client == Reth || args.Client == RethExEx) && args.Network == Base {
+		return opRethImage
+	}
+	return driver.DefaultImage()
+}
+
+// sourceRepo returns the repository set in the args, empty fields are taken from defaults.
+func sourceRepo(args *ExecutionClientComponentArgs, defaults utils.SourceRepo) utils.SourceRepo {
+	return utils.SourceRepo{Url: args.SourceRepoUrl, Ref: args.SourceRef}.WithDefaults(defaults)
+}
+
+// sourceConfigKeys holds the pulumi config keys the clients used to read
+// their repository and branch from.
+var sourceConfigKeys = map[string][2]string{
+	Geth:       {"gethRepoUrl", "gethBranch"},
+	Nethermind: {"nethermindRepoUrl", "nethermindBranch"},
+	Reth:       {"rethRepoURL", "rethGitBranch"},
+	RethExEx:   {"rethRepoURL", "rethGitBranch"},
+}
+
+// SourceFromConfig fills SourceRepoUrl and SourceRef of args that are not set
+// from the pulumi config keys the client used before they were args, e.g.
+// gethRepoUrl and gethBranch. Keys missing from the config are ignored so the
+// client defaults apply.
+//
+// Example usage:
+//
+//	args := &executionClient.ExecutionClientComponentArgs{Client: "reth", DeploymentType: "source"}
+//	executionClient.SourceFromConfig(ctx, args)
+func SourceFromConfig(ctx *pulumi.Context, args *ExecutionClientComponentArgs) {
+	keys, ok := sourceConfigKeys[args.Client]
+	if !ok {
+		return
+	}
+	repo := utils.SourceRepo{Url: args.SourceRepoUrl, Ref: args.SourceRef}.WithDefaults(utils.SourceRepoFromConfig(ctx, keys[0], keys[1]))
+	args.SourceRepoUrl, args.SourceRef = repo.Url, repo.Ref
+}
+
+// sourceJwtSecret writes args.ExecutionJwt to the shared jwt path on the remote
+// host and returns the resources the client service has to wait for. Without
+// an ExecutionJwt the secret is expected to be provisioned already.
+func sourceJwtSecret(ctx *pulumi.Context, component *ExecutionClientComponent, args *ExecutionClientComponentArgs) ([]pulumi.Resource, error) {
+	if args.ExecutionJwt == nil {
+		return nil, nil
+	}
+
+	jwtSecret, err := utils.NewJwtSecretFile(ctx, fmt.Sprintf("writeJwtSecret-%s", resourcePrefix(args)), args.Connection, args.ExecutionJwt, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error writing jwt secret", nil)
+		return nil, err
+	}
+	return []pulumi.Resource{jwtSecret}, nil
+}
+
+// manifestRendering returns how the kubernetes objects of the client are
+// rendered, nil when they are applied to the cluster.
+func (args *ExecutionClientComponentArgs) manifestRendering() *utils.ManifestRendering {
+	return utils.NewManifestRendering(args.RenderManifestsTo, resourcePrefix(args), args.RenderSecrets, args.SealedSecretsCert, args.Namespace)
+}
+
+// resourcePrefix returns the prefix of the names of the client's resources,
+// args.Name or the client, so the replicas of a fleet get distinct names.
+func resourcePrefix(args *ExecutionClientComponentArgs) string {
+	if args.Name != "" {
+		return args.Name
+	}
+	return args.Client
+}
@@ -0,0 +1,287 @@
+package executionClient
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+func init() {
+	RegisterExecutionClient(erigonDriver{})
+}
+
+// Ports of erigon's built-in Caplin consensus client, only opened when EnableCaplin is set.
+const (
+	caplinDiscoveryPort = 4000
+	caplinP2pPort       = 4001
+	caplinBeaconApiPort = 5555
+)
+
+// erigonDriver is the built-in ExecutionClientDriver for erigon.
+type erigonDriver struct{}
+
+func (erigonDriver) Client() string { return Erigon }
+
+func (erigonDriver) BuildHooks() map[string]ExecutionClientBuildHook {
+	return map[string]ExecutionClientBuildHook{
+		Source:     erigonSource,
+		Binary:     binaryHook(erigonDriver{}, erigonRelease),
+		Docker:     dockerHook(erigonDriver{}, erigonContainer),
+		Kubernetes: kubernetesHook(erigonDriver{}, erigonContainer),
+	}
+}
+
+// erigonRelease are the erigon releases published on github.
+var erigonRelease = binaryRelease{
+	url: func(version string, arch string) string {
+		return fmt.Sprintf("https://github.com/erigontech/erigon/releases/download/v%s/erigon_%s_linux_%s.tar.gz", version, version, arch)
+	},
+	executable: func(version string, arch string) string { return "erigon" },
+	link:       "/usr/local/bin/erigon",
+}
+
+// erigonContainer is where the erigon container mounts its volumes in kubernetes and docker.
+var erigonContainer = containerSpec{
+	configDir:    "/etc/erigon",
+	dataDir:      "/home/erigon/.local/share/erigon",
+	jwtDir:       "/etc/erigon/execution-jwt",
+	args:         erigonContainerArgs,
+	ports:        erigonCaplinPorts,
+	servicePorts: erigonCaplinServicePorts,
+}
+
+// DefaultPorts returns erigon's ports, websockets are served on the http port.
+func (erigonDriver) DefaultPorts() ExecutionClientPorts {
+	return ExecutionClientPorts{P2P: 30303, Metrics: 6060, Http: 8545, Ws: 8545, AuthRpc: 8551, Torrent: 42069}
+}
+
+// erigonSourceRepo is the repository erigon is built from unless SourceRepoUrl and SourceRef are set.
+var erigonSourceRepo = utils.SourceRepo{Url: "https://github.com/erigontech/erigon.git", Ref: "main"}
+
+func (erigonDriver) DefaultImage() string { return "erigontech/erigon:latest" }
+
+func (erigonDriver) ConfigFileName() string { return "erigon.toml" }
+
+// NewErigonComponent creates a new ExecutionClientComponent resource that represents an erigon client
+// and the necessary infrastructure to run it. With EnableCaplin set erigon runs its built-in
+// Caplin consensus client and no separate consensus client is needed.
+//
+// Example usage:
+//
+//	client, err := executionClient.NewErigonComponent(ctx, "testErigonExecutionClient", &executionClient.ExecutionClientComponentArgs{
+//		Connection:     &remote.ConnectionArgs{
+//			User:       cfg.Require("sshUser"), // username for the ssh connection
+//			Host:       cfg.Require("sshHost"), // ip address of the host
+//			PrivateKey: cfg.RequireSecret("sshPrivateKey"), // must be a secret, RequireSecret is critical for security
+//		},
+//		Client:         "erigon", // must be "erigon"
+//		Network:        "mainnet", // mainnet, sepolia, holesky or hoodi
+//		DeploymentType: "source", // source or kubernetes
+//		DataDir:        "/data/mainnet/erigon", // path to the data directory
+//		EnableCaplin:   true, // run the built-in consensus client
+//	})
+func NewErigonComponent(ctx *pulumi.Context, name string, args *ExecutionClientComponentArgs, opts ...pulumi.ResourceOption) (*ExecutionClientComponent, error) {
+	return newClientComponent(ctx, name, erigonDriver{}, args, opts...)
+}
+
+// erigonFlags returns the erigon flags that depend on the args: the chain,
+// and either the Caplin beacon api or --externalcl when a separate consensus
+// client drives erigon.
+func erigonFlags(args *ExecutionClientComponentArgs, beaconApiAddr string) []string {
+	flags := []string{fmt.Sprintf("--chain=%s", args.Network), "--http.api=" + httpApi(args, "eth,erigon,engine")}
+	if !args.EnableCaplin {
+		return append(flags, "--externalcl")
+	}
+	return append(flags,
+		"--beacon.api=beacon,builder,config,debug,node,validator",
+		fmt.Sprintf("--beacon.api.addr=%s", beaconApiAddr),
+		fmt.Sprintf("--beacon.api.port=%d", caplinBeaconApiPort),
+		fmt.Sprintf("--caplin.discovery.port=%d", caplinDiscoveryPort),
+		fmt.Sprintf("--caplin.discovery.tcpport=%d", caplinP2pPort),
+	)
+}
+
+// erigonContainerArgs returns the flags the erigon container runs with, matching its mounts.
+func erigonContainerArgs(args *ExecutionClientComponentArgs, spec containerSpec) []string {
+	ports := erigonDriver{}.DefaultPorts()
+	return append([]string{
+		"--config=" + path.Join(spec.configDir, erigonDriver{}.ConfigFileName()),
+		"--datadir=" + spec.dataDir,
+		"--authrpc.jwtsecret=" + path.Join(spec.jwtDir, "jwt.hex"),
+		"--authrpc.addr=0.0.0.0",
+		"--authrpc.vhosts=*",
+		"--http.addr=0.0.0.0",
+		"--http.vhosts=*",
+		"--ws",
+		"--metrics",
+		"--metrics.addr=0.0.0.0",
+		fmt.Sprintf("--metrics.port=%d", ports.Metrics),
+		fmt.Sprintf("--port=%d", ports.P2P),
+		fmt.Sprintf("--torrent.port=%d", ports.Torrent),
+	}, erigonFlags(args, "0.0.0.0")...)
+}
+
+// erigonCaplinPorts returns the Caplin ports published by docker deployments.
+func erigonCaplinPorts(args *ExecutionClientComponentArgs) []utils.DockerPort {
+	if !args.EnableCaplin {
+		return nil
+	}
+	return []utils.DockerPort{
+		{Port: caplinDiscoveryPort, Protocol: "udp"},
+		{Port: caplinP2pPort},
+		{Port: caplinBeaconApiPort},
+	}
+}
+
+// erigonCaplinServicePorts exposes the Caplin p2p ports through the p2p service
+// and its beacon api through the internal service of kubernetes deployments.
+func erigonCaplinServicePorts(args *ExecutionClientComponentArgs) (corev1.ServicePortArray, corev1.ServicePortArray) {
+	if !args.EnableCaplin {
+		return nil, nil
+	}
+	p2p := corev1.ServicePortArray{
+		corev1.ServicePortArgs{
+			Port:     pulumi.Int(caplinDiscoveryPort),
+			Protocol: pulumi.String("UDP"),
+			Name:     pulumi.String("caplin-udp"),
+		},
+		corev1.ServicePortArgs{
+			Port: pulumi.Int(caplinP2pPort),
+			Name: pulumi.String("caplin-tcp"),
+		},
+	}
+	internal := corev1.ServicePortArray{
+		corev1.ServicePortArgs{
+			Port: pulumi.Int(caplinBeaconApiPort),
+			Name: pulumi.String("beacon-api"),
+		},
+	}
+	return p2p, internal
+}
+
+// erigonSource builds erigon from source on the remote host and runs it as a systemd service.
+func erigonSource(ctx *pulumi.Context, component *ExecutionClientComponent, args *ExecutionClientComponentArgs) error {
+	// Execute a sequence of commands on the remote server
+	_, err := remote.NewCommand(ctx, fmt.Sprintf("createDataDir-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("mkdir -p %s", args.DataDir),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error creating data directory", nil)
+		return err
+	}
+
+	// clone repo
+	repo, err := remote.NewCommand(ctx, fmt.Sprintf("cloneRepo-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.String(sourceRepo(args, erigonSourceRepo).CloneCommand(fmt.Sprintf("/data/repos/%s", args.Client))),
+		Update:     pulumi.String(sourceRepo(args, erigonSourceRepo).CheckoutCommand(fmt.Sprintf("/data/repos/%s", args.Client))),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error cloning repo", nil)
+		return err
+	}
+
+	// install go and the c toolchain erigon's cgo dependencies need
+	goDeps, err := remote.NewCommand(ctx, fmt.Sprintf("installGo-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.String("sudo apt update && sudo apt install -y golang-go build-essential"),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error installing go", nil)
+		return err
+	}
+
+	// set repo permissions
+	repoPerms, err := remote.NewCommand(ctx, fmt.Sprintf("setRepoPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chown -R %s:%s /data/repos/%s", args.Client, args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{repo}))
+	if err != nil {
+		ctx.Log.Error("Error setting repo permissions", nil)
+		return err
+	}
+
+	// build execution client
+	buildClient, err := remote.NewCommand(ctx, fmt.Sprintf("buildExecutionClient-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("cd /data/repos/%s && sudo -u %s make erigon", args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{goDeps, repoPerms}))
+	if err != nil {
+		ctx.Log.Error("Error building execution client", nil)
+		return err
+	}
+
+	// move client binary to /usr/local/bin
+	_, err = remote.NewCommand(ctx, fmt.Sprintf("moveClientBinary-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("mv /data/repos/%s/build/bin/erigon /usr/local/bin/erigon", args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{buildClient}))
+	if err != nil {
+		ctx.Log.Error("Error moving client binary", nil)
+		return err
+	}
+
+	// copy start script
+	startScript, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyStartScript-%s", resourcePrefix(args)), &remote.CopyFileArgs{
+		LocalPath:  pulumi.Sprintf("scripts/start_%s.sh", args.Client),
+		RemotePath: pulumi.Sprintf("/data/scripts/start_%s.sh", args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error copying start script", nil)
+		return err
+	}
+
+	// script permissions
+	scriptPerms, err := remote.NewCommand(ctx, fmt.Sprintf("scriptPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chmod +x /data/scripts/start_%s.sh", args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{startScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting script permissions", nil)
+		return err
+	}
+
+	// write the shared engine api jwt
+	jwtSecret, err := sourceJwtSecret(ctx, component, args)
+	if err != nil {
+		return err
+	}
+
+	// the start script reads the data dir, jwt path and network flags from the service environment
+	environment := pulumi.StringMap{
+		"DATA_DIR":        pulumi.String(args.DataDir),
+		"JWT_SECRET_FILE": pulumi.String(utils.JwtSecretPath),
+		"ERIGON_FLAGS":    pulumi.String(strings.Join(erigonFlags(args, "127.0.0.1"), " ")),
+	}
+
+	// create service
+	serviceDefinition, err := utils.NewServiceDefinitionComponent(ctx, fmt.Sprintf("executionService-%s", resourcePrefix(args)), &utils.ServiceComponentArgs{
+		Connection:  args.Connection,
+		ServiceType: args.Client,
+		Network:     args.Network,
+		Environment: environment,
+	}, pulumi.Parent(component), pulumi.DependsOn(append([]pulumi.Resource{buildClient, scriptPerms}, jwtSecret...)))
+	if err != nil {
+		ctx.Log.Error("Error creating execution service", nil)
+		return err
+	}
+
+	// group permissions
+	_, err = remote.NewCommand(ctx, fmt.Sprintf("setDataDirGroupPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chown -R %s:%s %s && chown %s:%s /data/scripts/start_%s.sh && chown %s:%s /usr/local/bin/%s", args.Client, args.Client, args.DataDir, args.Client, args.Client, args.Client, args.Client, args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{serviceDefinition, scriptPerms, startScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting group permissions", nil)
+		return err
+	}
+
+	return setSourceEndpoints(ctx, component, erigonDriver{}.DefaultPorts(), args)
+}
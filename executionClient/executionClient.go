@@ -2,13 +2,31 @@ package executionClient
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
 )
 
 type ExecutionClientComponent struct {
 	pulumi.ResourceState
+
+	// HttpRpcUrl is the JSON-RPC HTTP endpoint.
+	HttpRpcUrl pulumi.StringOutput `pulumi:"httpRpcUrl"`
+	// WsRpcUrl is the JSON-RPC websocket endpoint.
+	WsRpcUrl pulumi.StringOutput `pulumi:"wsRpcUrl"`
+	// EngineApiUrl is the jwt authenticated Engine API endpoint used by the consensus client.
+	EngineApiUrl pulumi.StringOutput `pulumi:"engineApiUrl"`
+	// MetricsUrl is the prometheus metrics endpoint.
+	MetricsUrl pulumi.StringOutput `pulumi:"metricsUrl"`
+	// P2pAddress is the host and port peers connect to.
+	P2pAddress pulumi.StringOutput `pulumi:"p2pAddress"`
+	// P2pNodePort is the NodePort of the p2p service, or the p2p port on the host for source deployments.
+	P2pNodePort pulumi.IntOutput `pulumi:"p2pNodePort"`
+	// Enode is the node's enode url, only populated when QueryNodeIdentity is set.
+	Enode pulumi.StringOutput `pulumi:"enode"`
 }
 
 type ExecutionClientComponentArgs struct {
@@ -17,7 +35,7 @@ type ExecutionClientComponentArgs struct {
 	Network                          string
 	DeploymentType                   string
 	DataDir                          string
-	ExecutionJwt                     string
+	ExecutionJwt                     pulumi.StringInput
 	ExecutionClientConfigPath        string
 	Environment                      map[string]string
 	PodStorageSize                   string
@@ -34,6 +52,75 @@ type ExecutionClientComponentArgs struct {
 	MemoryLimit                      string
 	CpuRequest                       string
 	MemoryRequest                    string
+	QueryNodeIdentity                bool
+	// SourceRepoUrl and SourceRef select the git repository and the branch, tag
+	// or commit source deployments are built from. Empty fields fall back to the
+	// client defaults: go-ethereum master for geth, nethermind master for
+	// nethermind and paradigmxyz/reth main for reth and reth-exex.
+	SourceRepoUrl string
+	SourceRef     string
+	// EnableCaplin runs erigon's built-in Caplin consensus client, so the node
+	// needs no separate consensus client. Only supported by erigon.
+	EnableCaplin bool
+	// BesuStorageFormat selects besu's database layout, Bonsai (the default)
+	// or Forest, which keeps the full state history for archive nodes.
+	BesuStorageFormat string
+	// SequencerHttp is the sequencer op-geth forwards transactions to, the
+	// network's public sequencer by default. HistoricalRpc serves requests for
+	// blocks before the bedrock upgrade, e.g. from a legacy l2geth for the
+	// full history of optimism. Both are only supported by op-geth.
+	SequencerHttp string
+	HistoricalRpc string
+	// OpGenesisPath is a genesis file op-geth is initialized with for OP Stack
+	// chains missing from the superchain registry, the registry config of
+	// Network is used otherwise. Only supported by op-geth.
+	OpGenesisPath string
+	// BinaryVersion is the release binary deployments install, for geth and
+	// nethermind including the commit, e.g. 1.14.11-f3c696fa. BinaryArch
+	// selects the amd64 (default) or arm64 build. The download is verified
+	// against BinarySha256 and, when BinaryPgpKey is set, against the release
+	// signature. BinaryUrl replaces the client's official download url.
+	BinaryVersion string
+	BinaryArch    string
+	BinarySha256  string
+	BinaryPgpKey  string
+	BinaryUrl     string
+	// RenderManifestsTo writes the kubernetes objects of the client as YAML
+	// to a directory named after the client below it instead of applying
+	// them, e.g. for a GitOps repository synced by Argo CD. RenderSecrets
+	// selects how secrets are written: "reference" (the default) leaves them
+	// out to be provisioned out of band under the same name, "sealed" writes
+	// SealedSecrets encrypted with kubeseal and SealedSecretsCert.
+	RenderManifestsTo string
+	RenderSecrets     string
+	SealedSecretsCert string
+	// Namespace is the kubernetes namespace the client's objects are created
+	// in, the provider's namespace when empty. Objects are named after Name,
+	// or the client without one, e.g. <Name>-data and <Name>-config, so
+	// clients sharing a namespace need different names.
+	Namespace string
+	// Kubeconfig and KubeContext select the cluster the QueryNodeIdentity
+	// query runs its curl pod in, set them to the kubeconfig path and context
+	// of the kubernetes provider the client is deployed with. The local
+	// kubectl's current context is used when empty.
+	Kubeconfig  string
+	KubeContext string
+	// IngressArgs describe how EnableIngress exposes the json-rpc on
+	// IngressHost, with websockets below /ws.
+	utils.IngressArgs
+	// DisableProbes leaves the kubernetes container without probes. Otherwise
+	// they are answered by a busybox sidecar running ProbeImage,
+	// "busybox:1.36" by default: the client is live while the json-rpc
+	// answers and ready once eth_syncing is false and the latest block is at
+	// most ProbeMaxBlockAge seconds old, 120 by default. The probes run every
+	// ProbePeriodSeconds, 10 by default, and ProbeFailureThreshold failures
+	// in a row, 6 by default, restart the client or take it out of its
+	// services. The startup probe allows StartupProbeFailureThreshold
+	// failures, 720 or two hours by default, for the client to open its
+	// database before liveness applies.
+	utils.ProbeArgs
+	ProbeMaxBlockAge int
+	ProbeImage       string
 }
 
 const (
@@ -41,15 +128,146 @@ const (
 	RethExEx   = "reth-exex"
 	Nethermind = "nethermind"
 	Geth       = "geth"
+	Erigon     = "erigon"
+	Besu       = "besu"
+	OpGeth     = "op-geth"
 	Source     = "source"
 	Binary     = "binary"
 	Docker     = "docker"
 	Kubernetes = "kubernetes"
+	Mainnet    = "mainnet"
+	Sepolia    = "sepolia"
+	Holesky    = "holesky"
+	Hoodi      = "hoodi"
+	Base       = "base"
+	Bonsai     = "bonsai"
+	Forest     = "forest"
+
+	BaseSepolia     = "base-sepolia"
+	Optimism        = "optimism"
+	OptimismSepolia = "optimism-sepolia"
+)
+
+var (
+	networks        = []string{Mainnet, Sepolia, Holesky, Hoodi, Base, BaseSepolia, Optimism, OptimismSepolia}
+	opStackNetworks = []string{Base, BaseSepolia, Optimism, OptimismSepolia}
+	deploymentTypes = []string{Source, Binary, Docker, Kubernetes}
 )
 
+// Validate checks the args for unknown clients, networks and deployment types
+// as well as settings required by the chosen deployment type. All problems
+// found are returned together as utils.ValidationErrors.
+func (args *ExecutionClientComponentArgs) Validate() error {
+	var errs utils.ValidationErrors
+
+	driver, ok := LookupExecutionClient(args.Client)
+	if !ok {
+		errs.Add("Client", "unknown execution client %q, expected one of %s", args.Client, strings.Join(ExecutionClients(), ", "))
+	}
+	if !slices.Contains(networks, args.Network) {
+		errs.Add("Network", "unknown network %q, expected one of %s", args.Network, strings.Join(networks, ", "))
+	}
+	if !slices.Contains(deploymentTypes, args.DeploymentType) {
+		errs.Add("DeploymentType", "unknown deployment type %q, expected one of %s", args.DeploymentType, strings.Join(deploymentTypes, ", "))
+	} else if ok {
+		if _, supported := driver.BuildHooks()[args.DeploymentType]; !supported {
+			errs.Add("DeploymentType", "execution client %q does not support deployment type %q", args.Client, args.DeploymentType)
+		}
+	}
+
+	if args.EnableCaplin && args.Client != Erigon {
+		errs.Add("EnableCaplin", "only supported by %s", Erigon)
+	}
+	if args.BesuStorageFormat != "" {
+		if args.Client != Besu {
+			errs.Add("BesuStorageFormat", "only supported by %s", Besu)
+		} else if !slices.Contains([]string{Bonsai, Forest}, args.BesuStorageFormat) {
+			errs.Add("BesuStorageFormat", "unknown storage format %q, expected one of %s, %s", args.BesuStorageFormat, Bonsai, Forest)
+		}
+	}
+	if args.Client != OpGeth {
+		if args.SequencerHttp != "" {
+			errs.Add("SequencerHttp", "only supported by %s", OpGeth)
+		}
+		if args.HistoricalRpc != "" {
+			errs.Add("HistoricalRpc", "only supported by %s", OpGeth)
+		}
+		if args.OpGenesisPath != "" {
+			errs.Add("OpGenesisPath", "only supported by %s", OpGeth)
+		}
+	}
+	// op-reth only runs base, the other OP Stack networks need op-geth
+	switch {
+	case args.Client == OpGeth && slices.Contains(networks, args.Network) && !slices.Contains(opStackNetworks, args.Network):
+		errs.Add("Network", "execution client %q only supports the OP Stack networks %s", args.Client, strings.Join(opStackNetworks, ", "))
+	case args.Client != OpGeth && args.Network != Base && slices.Contains(opStackNetworks, args.Network),
+		(args.Client == Erigon || args.Client == Besu) && args.Network == Base:
+		errs.Add("Network", "execution client %q does not support network %q", args.Client, args.Network)
+	}
+
+	switch args.DeploymentType {
+	case Source:
+		if args.Connection == nil {
+			errs.Add("Connection", "required for %s deployments", args.DeploymentType)
+		}
+	case Binary:
+		if args.Connection == nil {
+			errs.Add("Connection", "required for %s deployments", args.DeploymentType)
+		}
+		utils.ValidateRelease(&errs, "Binary", args.BinaryVersion, args.BinaryArch, args.BinarySha256)
+		if args.Client == Reth && args.Network == Base {
+			errs.Add("Network", "%s deployments of %s do not support network %q", args.DeploymentType, args.Client, args.Network)
+		}
+	case Docker:
+		if args.Connection == nil {
+			errs.Add("Connection", "required for %s deployments", args.DeploymentType)
+		}
+		if args.OpGenesisPath != "" {
+			errs.Add("OpGenesisPath", "not supported for %s deployments", args.DeploymentType)
+		}
+	case Kubernetes:
+		if args.ExecutionJwt == nil {
+			errs.Add("ExecutionJwt", "required for %s deployments", args.DeploymentType)
+		}
+		if args.ExecutionClientConfigPath == "" {
+			errs.Add("ExecutionClientConfigPath", "required for %s deployments", args.DeploymentType)
+		}
+		utils.ValidateKubernetesName(&errs, "Name", args.Name)
+		utils.ValidateKubernetesName(&errs, "Namespace", args.Namespace)
+		if args.CpuLimit == "" {
+			errs.Add("CpuLimit", "required for %s deployments", args.DeploymentType)
+		}
+		if args.MemoryLimit == "" {
+			errs.Add("MemoryLimit", "required for %s deployments", args.DeploymentType)
+		}
+	}
+	if args.Namespace != "" && args.DeploymentType != Kubernetes {
+		errs.Add("Namespace", "only supported for %s deployments", Kubernetes)
+	}
+	if args.Kubeconfig != "" && args.DeploymentType != Kubernetes {
+		errs.Add("Kubeconfig", "only supported for %s deployments", Kubernetes)
+	}
+	if args.KubeContext != "" && args.DeploymentType != Kubernetes {
+		errs.Add("KubeContext", "only supported for %s deployments", Kubernetes)
+	}
+	utils.ValidateManifestRendering(&errs, args.DeploymentType, Kubernetes, args.RenderManifestsTo, args.RenderSecrets, args.SealedSecretsCert, args.QueryNodeIdentity)
+	utils.ValidateIngress(&errs, "EnableIngress", args.EnableIngress, args.DeploymentType, Kubernetes, args.Ingress())
+	utils.ValidateProbes(&errs, args.DeploymentType, Kubernetes, args.Probes())
+	if args.ProbeMaxBlockAge < 0 {
+		errs.Add("ProbeMaxBlockAge", "must not be negative")
+	} else if args.ProbeMaxBlockAge > 0 && args.DeploymentType != Kubernetes {
+		errs.Add("ProbeMaxBlockAge", "only supported for %s deployments", Kubernetes)
+	}
+	if args.ProbeImage != "" && args.DeploymentType != Kubernetes {
+		errs.Add("ProbeImage", "only supported for %s deployments", Kubernetes)
+	}
+
+	return errs.Err()
+}
+
 // NewExecutionClientComponent creates a new instance of the ExecutionClientComponent
-// and calls the appropriate component constructor based on the client
-// being requested.
+// and runs the ExecutionClientDriver registered for the client being requested.
+// The args are validated before any resources are registered.
 // It returns a pointer to the ExecutionClientComponent and an error
 //
 // Example usage:
@@ -70,38 +288,35 @@ func NewExecutionClientComponent(ctx *pulumi.Context, name string, args *Executi
 		args = &ExecutionClientComponentArgs{}
 	}
 
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+
 	component := &ExecutionClientComponent{}
 	err := ctx.RegisterComponentResource(fmt.Sprintf("custom:component:ExecutionClient:%s:%s", args.Client, args.Network), name, component, opts...)
 	if err != nil {
 		return nil, err
 	}
+	initOutputs(component)
 
-	// check what client is being requested and call the appropriate component constructor
-	switch args.Client {
-	case Reth:
-		_, err = NewRethComponent(ctx, "reth", args, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error creating reth component", nil)
-			return nil, err
-		}
-	case Nethermind:
-		_, err = NewNethermindComponent(ctx, "nethermind", args, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error creating nethermind component", nil)
-			return nil, err
-		}
-	case Geth:
-		_, err = NewGethComponent(ctx, "geth", args, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error creating geth component", nil)
-			return nil, err
-		}
-	case RethExEx:
-		_, err = NewRethExExComponent(ctx, "reth-exex", args, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error creating reth-exex component", nil)
-			return nil, err
-		}
+	// look up the driver for the requested client and call its component constructor
+	driver, _ := LookupExecutionClient(args.Client)
+	// the client component is named after args.Name so several nodes can be
+	// deployed side by side, the alias keeps the name used before
+	clientName, clientOpts := driver.Client(), []pulumi.ResourceOption{pulumi.Parent(component)}
+	if args.Name != "" && args.Name != clientName {
+		clientOpts = append(clientOpts, pulumi.Aliases([]pulumi.Alias{{Name: pulumi.String(clientName)}}))
+		clientName = args.Name
+	}
+	client, err := newClientComponent(ctx, clientName, driver, args, clientOpts...)
+	if err != nil {
+		ctx.Log.Error(fmt.Sprintf("Error creating %s component", driver.Client()), nil)
+		return nil, err
+	}
+
+	copyOutputs(component, client)
+	if err := registerOutputs(ctx, component); err != nil {
+		return nil, err
 	}
 
 	return component, nil
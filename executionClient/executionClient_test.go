@@ -1,9 +1,15 @@
 package executionClient_test
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	el "github.com/rswanson/node_deployer/executionClient"
+	"github.com/rswanson/node_deployer/utils"
 
 	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
@@ -22,18 +28,43 @@ func (mocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
 	return args.Args, nil
 }
 
+// recordingMocks records the inputs of every resource by name.
+type recordingMocks struct {
+	mocks
+	mu     sync.Mutex
+	inputs map[string]resource.PropertyMap
+}
+
+func (m *recordingMocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inputs[args.Name] = args.Inputs
+	return args.Name + "_id", args.Inputs, nil
+}
+
+// kubernetesArgs returns valid args to deploy client to kubernetes.
+func kubernetesArgs(t *testing.T, client string) *el.ExecutionClientComponentArgs {
+	configPath := filepath.Join(t.TempDir(), client+".toml")
+	assert.NoError(t, os.WriteFile(configPath, []byte("[test]"), 0o600))
+
+	return &el.ExecutionClientComponentArgs{
+		Client:                    client,
+		Network:                   "holesky",
+		DeploymentType:            "kubernetes",
+		ExecutionJwt:              pulumi.String("testJwt"),
+		ExecutionClientConfigPath: configPath,
+		CpuLimit:                  "1",
+		MemoryLimit:               "1Gi",
+		Name:                      client,
+	}
+}
+
 func TestExecutionClientComponent(t *testing.T) {
 	t.Run("RethComponent", func(t *testing.T) {
 		mocks := mocks(0)
 		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
 			// Create a new instance of the ExecutionClientComponent
-			_, err := el.NewExecutionClientComponent(ctx, "testRethExecutionClient", &el.ExecutionClientComponentArgs{
-				Connection:     &remote.ConnectionArgs{},
-				Client:         "reth",
-				Network:        "testNetwork",
-				DeploymentType: "testDeploymentType",
-				DataDir:        "testDataDir",
-			})
+			_, err := el.NewExecutionClientComponent(ctx, "testRethExecutionClient", kubernetesArgs(t, "reth"))
 
 			// Test the NewRethComponent function
 
@@ -48,13 +79,7 @@ func TestExecutionClientComponent(t *testing.T) {
 		mocks := mocks(0)
 		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
 			// Create a new instance of the ExecutionClientComponent
-			_, err := el.NewExecutionClientComponent(ctx, "testNethermindExecutionClient", &el.ExecutionClientComponentArgs{
-				Connection:     &remote.ConnectionArgs{},
-				Client:         "nethermind",
-				Network:        "testNetwork",
-				DeploymentType: "testDeploymentType",
-				DataDir:        "testDataDir",
-			})
+			_, err := el.NewExecutionClientComponent(ctx, "testNethermindExecutionClient", kubernetesArgs(t, "nethermind"))
 
 			// Test the NewNethermindComponent function
 
@@ -69,13 +94,7 @@ func TestExecutionClientComponent(t *testing.T) {
 		mocks := mocks(0)
 		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
 			// Create a new instance of the ExecutionClientComponent
-			_, err := el.NewExecutionClientComponent(ctx, "testGethExecutionClient", &el.ExecutionClientComponentArgs{
-				Connection:     &remote.ConnectionArgs{},
-				Client:         "geth",
-				Network:        "testNetwork",
-				DeploymentType: "testDeploymentType",
-				DataDir:        "testDataDir",
-			})
+			_, err := el.NewExecutionClientComponent(ctx, "testGethExecutionClient", kubernetesArgs(t, "geth"))
 
 			// Test the NewGethComponent function
 
@@ -86,6 +105,397 @@ func TestExecutionClientComponent(t *testing.T) {
 		assert.NoError(t, err, "Expected to not receive an error")
 	})
 
+	t.Run("BesuComponent", func(t *testing.T) {
+		mocks := mocks(0)
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			args := kubernetesArgs(t, "besu")
+			args.BesuStorageFormat = "forest"
+			_, err := el.NewExecutionClientComponent(ctx, "testBesuExecutionClient", args)
+
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks))
+		assert.NoError(t, err, "Expected to not receive an error")
+	})
+
+	t.Run("OpGethComponent", func(t *testing.T) {
+		mocks := mocks(0)
+		genesisPath := filepath.Join(t.TempDir(), "genesis.json")
+		assert.NoError(t, os.WriteFile(genesisPath, []byte("{}"), 0o600))
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			args := kubernetesArgs(t, "op-geth")
+			args.Network = "optimism"
+			args.HistoricalRpc = "http://l2geth:8545"
+			args.OpGenesisPath = genesisPath
+			_, err := el.NewExecutionClientComponent(ctx, "testOpGethExecutionClient", args)
+
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks))
+		assert.NoError(t, err, "Expected to not receive an error")
+	})
+
+	t.Run("BinaryComponent", func(t *testing.T) {
+		mocks := mocks(0)
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			_, err := el.NewExecutionClientComponent(ctx, "testRethExecutionClient", &el.ExecutionClientComponentArgs{
+				Connection:     &remote.ConnectionArgs{Host: pulumi.String("10.0.0.1")},
+				Client:         "reth",
+				Network:        "holesky",
+				DeploymentType: "binary",
+				ExecutionJwt:   pulumi.String("testJwt"),
+				BinaryVersion:  "1.1.0",
+				BinaryArch:     "arm64",
+				BinarySha256:   "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			})
+
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks))
+		assert.NoError(t, err, "Expected to not receive an error")
+	})
+
+	t.Run("DockerComponent", func(t *testing.T) {
+		mocks := mocks(0)
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			args := kubernetesArgs(t, "geth")
+			args.DeploymentType = "docker"
+			args.Connection = &remote.ConnectionArgs{Host: pulumi.String("10.0.0.1")}
+			client, err := el.NewExecutionClientComponent(ctx, "testGethExecutionClient", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			// consensus client containers reach the engine api by container name
+			done := make(chan struct{})
+			client.EngineApiUrl.ApplyT(func(url string) error {
+				assert.Equal(t, "http://geth:8551", url)
+				close(done)
+				return nil
+			})
+			<-done
+
+			return nil
+		}, pulumi.WithMocks("project", "stack", mocks))
+		assert.NoError(t, err, "Expected to not receive an error")
+	})
+
+	t.Run("DockerConfigComponent", func(t *testing.T) {
+		m := &recordingMocks{inputs: map[string]resource.PropertyMap{}}
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			args := kubernetesArgs(t, "geth")
+			args.DeploymentType = "docker"
+			args.Connection = &remote.ConnectionArgs{Host: pulumi.String("10.0.0.1")}
+			_, err := el.NewExecutionClientComponent(ctx, "testGethExecutionClient", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		// the jwt mountpoint inside the read only config mount exists on the host
+		assert.Equal(t, "mkdir -p /data/config/geth /data/config/geth/execution-jwt", m.inputs["createConfigDir-geth"]["create"].StringValue())
+		run := m.inputs["dockerContainer-geth"]["create"].StringValue()
+		assert.Contains(t, run, "/data/config/geth:/etc/geth:ro")
+		assert.Contains(t, run, "/data/shared:/etc/geth/execution-jwt:ro")
+	})
+
+	t.Run("ErigonDockerComponent", func(t *testing.T) {
+		m := &recordingMocks{inputs: map[string]resource.PropertyMap{}}
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			args := kubernetesArgs(t, "erigon")
+			args.DeploymentType = "docker"
+			args.Connection = &remote.ConnectionArgs{Host: pulumi.String("10.0.0.1")}
+			_, err := el.NewExecutionClientComponent(ctx, "testErigonExecutionClient", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		// erigon serves websockets on its http port, which is published once
+		run := m.inputs["dockerContainer-erigon"]["create"].StringValue()
+		assert.Equal(t, 1, strings.Count(run, "-p 8545:8545"), "Expected the rpc port to be published once: %s", run)
+		// the jwt is only readable by its group
+		assert.Contains(t, run, "$(getent group jwt | cut -d: -f3 | sed 's/^/--group-add /')")
+		assert.Contains(t, m.inputs["writeJwtSecret-erigon"]["create"].StringValue(), "chmod 640")
+	})
+
+	t.Run("QueryNodeIdentity", func(t *testing.T) {
+		m := &recordingMocks{inputs: map[string]resource.PropertyMap{}}
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			args := kubernetesArgs(t, "geth")
+			args.QueryNodeIdentity = true
+			args.Kubeconfig = "/home/deployer/.kube/config"
+			args.KubeContext = "staging"
+			_, err := el.NewExecutionClientComponent(ctx, "testGethExecutionClient", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		// admin_nodeInfo is only served with the admin api enabled
+		container := m.inputs["geth-set"]["spec"].ObjectValue()["template"].ObjectValue()["spec"].ObjectValue()["containers"].ArrayValue()[0].ObjectValue()
+		var flags []string
+		for _, flag := range container["args"].ArrayValue() {
+			flags = append(flags, flag.StringValue())
+		}
+		assert.Contains(t, flags, "--http.api=eth,net,web3,admin")
+
+		// a node that never reports its enode fails the update
+		query := m.inputs["geth-enode-query"]["create"].StringValue()
+		assert.Contains(t, query, "exit 1")
+		// the curl pod runs in the cluster the client is deployed to
+		assert.Contains(t, query, "kubectl run geth-enode-query --rm -i --quiet --restart=Never --image=curlimages/curl --kubeconfig '/home/deployer/.kube/config' --context 'staging'")
+	})
+
+	t.Run("LegacyKubernetesNames", func(t *testing.T) {
+		m := &recordingMocks{inputs: map[string]resource.PropertyMap{}}
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			unnamed := kubernetesArgs(t, "geth")
+			unnamed.Name = ""
+			_, err := el.NewExecutionClientComponent(ctx, "testGethExecutionClient", unnamed)
+			assert.NoError(t, err, "Expected to not receive an error")
+			_, err = el.NewExecutionClientComponent(ctx, "testRethExecutionClient", kubernetesArgs(t, "reth"))
+			assert.NoError(t, err, "Expected to not receive an error")
+			named := kubernetesArgs(t, "besu")
+			named.Name = "holesky-besu"
+			_, err = el.NewExecutionClientComponent(ctx, "testBesuExecutionClient", named)
+			assert.NoError(t, err, "Expected to not receive an error")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		objectName := func(resource string) string {
+			return m.inputs[resource]["metadata"].ObjectValue()["name"].StringValue()
+		}
+		// clients named after themselves keep the objects of existing stacks
+		assert.Equal(t, "geth-config-data", objectName("geth-data"))
+		assert.Equal(t, "geth-p2pnet-service", objectName("geth-p2p-service"))
+		assert.Equal(t, "execution-jwt", objectName("geth-execution-jwt"))
+		assert.Equal(t, "reth-config-data", objectName("reth-data"))
+		volumes := m.inputs["geth-set"]["spec"].ObjectValue()["template"].ObjectValue()["spec"].ObjectValue()["volumes"].ArrayValue()
+		assert.Equal(t, "geth-config-data", volumes[0].ObjectValue()["persistentVolumeClaim"].ObjectValue()["claimName"].StringValue())
+
+		// other names derive every object name
+		assert.Equal(t, "holesky-besu-data", objectName("holesky-besu-data"))
+		assert.Equal(t, "holesky-besu-p2p-service", objectName("holesky-besu-p2p-service"))
+		assert.Equal(t, "holesky-besu-execution-jwt", objectName("holesky-besu-execution-jwt"))
+	})
+}
+
+func TestExecutionClientComponentOutputs(t *testing.T) {
+	mocks := mocks(0)
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		client, err := el.NewExecutionClientComponent(ctx, "testGethExecutionClient", kubernetesArgs(t, "geth"))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		done := make(chan struct{})
+		pulumi.All(client.HttpRpcUrl, client.WsRpcUrl, client.EngineApiUrl, client.MetricsUrl, client.P2pAddress).ApplyT(func(urls []interface{}) error {
+			assert.Equal(t, "http://geth-rpc-service.default.svc.cluster.local:8545", urls[0])
+			assert.Equal(t, "ws://geth-rpc-service.default.svc.cluster.local:8546", urls[1])
+			assert.Equal(t, "http://geth-internal-service.default.svc.cluster.local:8551", urls[2])
+			assert.Equal(t, "http://geth-internal-service.default.svc.cluster.local:9001", urls[3])
+			assert.Equal(t, "geth-p2pnet-service.default.svc.cluster.local:30303", urls[4])
+			close(done)
+			return nil
+		})
+		<-done
+
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	assert.NoError(t, err, "Expected to not receive an error")
+
+	// the start scripts only serve the rpc, engine api and metrics on localhost
+	err = pulumi.RunErr(func(ctx *pulumi.Context) error {
+		args := kubernetesArgs(t, "geth")
+		args.DeploymentType = "source"
+		args.Connection = &remote.ConnectionArgs{Host: pulumi.String("10.0.0.1")}
+		client, err := el.NewExecutionClientComponent(ctx, "testGethExecutionClient", args)
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		done := make(chan struct{})
+		pulumi.All(client.HttpRpcUrl, client.WsRpcUrl, client.EngineApiUrl, client.MetricsUrl, client.P2pAddress).ApplyT(func(urls []interface{}) error {
+			assert.Equal(t, "http://127.0.0.1:8545", urls[0])
+			assert.Equal(t, "ws://127.0.0.1:8546", urls[1])
+			assert.Equal(t, "http://127.0.0.1:8551", urls[2])
+			assert.Equal(t, "http://127.0.0.1:9001", urls[3])
+			assert.Equal(t, "10.0.0.1:30303", urls[4])
+			close(done)
+			return nil
+		})
+		<-done
+
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks))
+	assert.NoError(t, err, "Expected to not receive an error")
+}
+
+type testDriver struct {
+	built *bool
+}
+
+func (testDriver) Client() string { return "test-client" }
+
+func (d testDriver) BuildHooks() map[string]el.ExecutionClientBuildHook {
+	return map[string]el.ExecutionClientBuildHook{
+		el.Kubernetes: func(ctx *pulumi.Context, component *el.ExecutionClientComponent, args *el.ExecutionClientComponentArgs) error {
+			*d.built = true
+			return nil
+		},
+	}
+}
+
+func (testDriver) DefaultPorts() el.ExecutionClientPorts {
+	return el.ExecutionClientPorts{P2P: 30303, Metrics: 9001, Http: 8545, Ws: 8546, AuthRpc: 8551}
+}
+
+func (testDriver) DefaultImage() string { return "test/client:latest" }
+
+func (testDriver) ConfigFileName() string { return "test.toml" }
+
+func TestRegisterExecutionClient(t *testing.T) {
+	built := false
+	el.RegisterExecutionClient(testDriver{built: &built})
+
+	assert.Contains(t, el.ExecutionClients(), "test-client")
+	for _, client := range []string{el.Geth, el.Reth, el.RethExEx, el.Nethermind} {
+		_, ok := el.LookupExecutionClient(client)
+		assert.True(t, ok, "Expected built-in driver for %s", client)
+	}
+
+	assert.Panics(t, func() { el.RegisterExecutionClient(testDriver{built: &built}) }, "Expected duplicate registration to panic")
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := el.NewExecutionClientComponent(ctx, "testCustomExecutionClient", kubernetesArgs(t, "test-client"))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks(0)))
+	assert.NoError(t, err, "Expected to not receive an error")
+	assert.True(t, built, "Expected the registered build hook to run")
+}
+
+func TestExecutionClientComponentArgsValidate(t *testing.T) {
+	assert.NoError(t, kubernetesArgs(t, "reth").Validate(), "Expected valid args to pass validation")
+
+	err := (&el.ExecutionClientComponentArgs{
+		Client:         "not-a-client",
+		Network:        "testNetwork",
+		DeploymentType: "testDeploymentType",
+	}).Validate()
+
+	var validationErrors utils.ValidationErrors
+	assert.True(t, errors.As(err, &validationErrors), "Expected validation errors")
+	fields := make([]string, 0, len(validationErrors))
+	for _, validationError := range validationErrors {
+		fields = append(fields, validationError.Field)
+	}
+	assert.Equal(t, []string{"Client", "Network", "DeploymentType"}, fields)
+
+	args := kubernetesArgs(t, "reth-exex")
+	args.ExecutionJwt = nil
+	args.CpuLimit = ""
+	args.DeploymentType = "binary"
+	err = args.Validate()
+	assert.ErrorContains(t, err, `DeploymentType: execution client "reth-exex" does not support deployment type "binary"`)
+
+	args.DeploymentType = "kubernetes"
+	err = args.Validate()
+	assert.ErrorContains(t, err, "ExecutionJwt: required for kubernetes deployments")
+	assert.ErrorContains(t, err, "CpuLimit: required for kubernetes deployments")
+
+	args = kubernetesArgs(t, "geth")
+	args.DeploymentType = "binary"
+	args.Connection = &remote.ConnectionArgs{}
+	args.BinaryArch = "riscv64"
+	args.BinarySha256 = "not-a-digest"
+	err = args.Validate()
+	assert.ErrorContains(t, err, "BinaryVersion: required for binary deployments")
+	assert.ErrorContains(t, err, `BinaryArch: unknown architecture "riscv64"`)
+	assert.ErrorContains(t, err, "BinarySha256: expected a hex encoded sha256 digest")
+
+	args = kubernetesArgs(t, "reth")
+	args.RenderSecrets = utils.SecretsSealed
+	err = args.Validate()
+	assert.ErrorContains(t, err, "RenderSecrets: only supported when RenderManifestsTo is set")
+	args.RenderManifestsTo = t.TempDir()
+	args.QueryNodeIdentity = true
+	err = args.Validate()
+	assert.ErrorContains(t, err, "SealedSecretsCert: required for sealed secrets")
+	assert.ErrorContains(t, err, "QueryNodeIdentity: not supported when RenderManifestsTo is set")
+	args.DeploymentType = "source"
+	args.Connection = &remote.ConnectionArgs{}
+	assert.ErrorContains(t, args.Validate(), "RenderManifestsTo: only supported for kubernetes deployments")
+
+	args = kubernetesArgs(t, "reth")
+	args.Name = "Reth_1"
+	args.Namespace = "-holesky"
+	err = args.Validate()
+	assert.ErrorContains(t, err, `Name: "Reth_1" is not a valid kubernetes name`)
+	assert.ErrorContains(t, err, `Namespace: "-holesky" is not a valid kubernetes name`)
+
+	args = kubernetesArgs(t, "reth")
+	args.EnableIngress = true
+	args.IngressKind = "httproute"
+	args.IngressTlsIssuer = "letsencrypt"
+	args.IngressAllowedSourceRanges = []string{"10.0.0.1"}
+	err = args.Validate()
+	assert.ErrorContains(t, err, "IngressHost: required when EnableIngress is set")
+	assert.ErrorContains(t, err, "IngressGateway: required for httproute ingresses")
+	assert.ErrorContains(t, err, "IngressTlsIssuer: not supported for httproute ingresses")
+	assert.ErrorContains(t, err, `IngressAllowedSourceRanges: "10.0.0.1" is not a CIDR`)
+
+	args = kubernetesArgs(t, "reth")
+	args.IngressHost = "rpc.example.com"
+	assert.ErrorContains(t, args.Validate(), "Ingress: settings are only supported when EnableIngress is set")
+
+	args = kubernetesArgs(t, "reth")
+	args.DeploymentType = "docker"
+	args.Connection = &remote.ConnectionArgs{}
+	args.ProbeFailureThreshold = -1
+	args.ProbeMaxBlockAge = 60
+	args.ProbeImage = "busybox:1.37"
+	err = args.Validate()
+	assert.ErrorContains(t, err, "ProbeFailureThreshold: must not be negative")
+	assert.ErrorContains(t, err, "ProbeMaxBlockAge: only supported for kubernetes deployments")
+	assert.ErrorContains(t, err, "ProbeImage: only supported for kubernetes deployments")
+
+	args = kubernetesArgs(t, "reth")
+	args.DeploymentType = "docker"
+	args.Connection = &remote.ConnectionArgs{}
+	args.ProbeImage = "busybox:1.37"
+	err = args.Validate()
+	assert.ErrorContains(t, err, "ProbeImage: only supported for kubernetes deployments")
+	assert.NotContains(t, err.Error(), "ProbeMaxBlockAge")
+
+	args = kubernetesArgs(t, "reth")
+	args.EnableCaplin = true
+	assert.ErrorContains(t, args.Validate(), "EnableCaplin: only supported by erigon")
+
+	args = kubernetesArgs(t, "besu")
+	args.BesuStorageFormat = "archive"
+	assert.ErrorContains(t, args.Validate(), `BesuStorageFormat: unknown storage format "archive"`)
+
+	args = kubernetesArgs(t, "op-geth")
+	assert.ErrorContains(t, args.Validate(), `Network: execution client "op-geth" only supports the OP Stack networks`)
+
+	args = kubernetesArgs(t, "geth")
+	args.Network = "optimism"
+	args.SequencerHttp = "https://sequencer.example.com"
+	err = args.Validate()
+	assert.ErrorContains(t, err, `Network: execution client "geth" does not support network "optimism"`)
+	assert.ErrorContains(t, err, "SequencerHttp: only supported by op-geth")
+
+	err = pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := el.NewExecutionClientComponent(ctx, "testInvalidExecutionClient", &el.ExecutionClientComponentArgs{
+			Client:         "not-a-client",
+			Network:        "mainnet",
+			DeploymentType: "source",
+			Connection:     &remote.ConnectionArgs{},
+		})
+		return err
+	}, pulumi.WithMocks("project", "stack", mocks(0)))
+	assert.ErrorContains(t, err, `unknown execution client "not-a-client"`)
 }
 
 func TestExecutionClientComponentArgs(t *testing.T) {
@@ -129,3 +539,21 @@ func TestExecutionClientComponentArgs(t *testing.T) {
 		t.Errorf("Expected DataDir to be %s, but got %s", dataDir, args.DataDir)
 	}
 }
+
+func TestSourceFromConfig(t *testing.T) {
+	t.Setenv("PULUMI_CONFIG", `{"project:rethRepoURL": "https://example.com/reth.git", "project:rethGitBranch": "v1.1.0"}`)
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		args := &el.ExecutionClientComponentArgs{Client: "reth", SourceRef: "main"}
+		el.SourceFromConfig(ctx, args)
+		assert.Equal(t, "https://example.com/reth.git", args.SourceRepoUrl)
+		assert.Equal(t, "main", args.SourceRef, "Expected the args to take precedence over the config")
+
+		args = &el.ExecutionClientComponentArgs{Client: "geth"}
+		el.SourceFromConfig(ctx, args)
+		assert.Empty(t, args.SourceRepoUrl, "Expected unset keys to leave the client default")
+		assert.Empty(t, args.SourceRef)
+		return nil
+	}, pulumi.WithMocks("project", "stack", mocks(0)))
+	assert.NoError(t, err)
+}
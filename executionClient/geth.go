@@ -2,17 +2,90 @@ package executionClient
 
 import (
 	"fmt"
-	"os"
+	"path"
 
 	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
-	appsv1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/apps/v1"
-	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
-	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
-	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
 	"github.com/rswanson/node_deployer/utils"
 )
 
+func init() {
+	RegisterExecutionClient(gethDriver{})
+}
+
+// gethDriver is the built-in ExecutionClientDriver for geth.
+type gethDriver struct{}
+
+func (gethDriver) Client() string { return Geth }
+
+func (gethDriver) BuildHooks() map[string]ExecutionClientBuildHook {
+	return map[string]ExecutionClientBuildHook{
+		Source:     gethSource,
+		Binary:     binaryHook(gethDriver{}, gethRelease),
+		Docker:     dockerHook(gethDriver{}, gethContainer),
+		Kubernetes: kubernetesHook(gethDriver{}, gethContainer),
+	}
+}
+
+// gethRelease are the geth releases published on gethstore, their version
+// includes the commit, e.g. 1.14.11-f3c696fa.
+var gethRelease = binaryRelease{
+	url: func(version string, arch string) string {
+		return fmt.Sprintf("https://gethstore.blob.core.windows.net/builds/geth-linux-%s-%s.tar.gz", arch, version)
+	},
+	executable: func(version string, arch string) string {
+		return fmt.Sprintf("geth-linux-%s-%s/geth", arch, version)
+	},
+	link: "/usr/local/bin/geth",
+}
+
+// gethContainer is where the geth container mounts its volumes in kubernetes and docker.
+var gethContainer = containerSpec{
+	configDir: "/etc/geth",
+	dataDir:   "/root/.local/share/geth",
+	jwtDir:    "/etc/geth/execution-jwt",
+	args:      gethContainerArgs,
+}
+
+// gethContainerArgs returns the flags the geth container runs with, matching
+// its mounts, the network is selected with its flag, e.g. --holesky.
+func gethContainerArgs(args *ExecutionClientComponentArgs, spec containerSpec) []string {
+	ports := gethDriver{}.DefaultPorts()
+	return []string{
+		"--" + args.Network,
+		"--config=" + path.Join(spec.configDir, gethDriver{}.ConfigFileName()),
+		"--datadir=" + spec.dataDir,
+		"--authrpc.jwtsecret=" + path.Join(spec.jwtDir, "jwt.hex"),
+		"--authrpc.addr=0.0.0.0",
+		fmt.Sprintf("--authrpc.port=%d", ports.AuthRpc),
+		"--authrpc.vhosts=*",
+		"--http",
+		"--http.addr=0.0.0.0",
+		fmt.Sprintf("--http.port=%d", ports.Http),
+		"--http.vhosts=*",
+		"--http.api=" + httpApi(args, "eth,net,web3"),
+		"--ws",
+		"--ws.addr=0.0.0.0",
+		fmt.Sprintf("--ws.port=%d", ports.Ws),
+		"--ws.origins=*",
+		"--metrics",
+		"--metrics.addr=0.0.0.0",
+		fmt.Sprintf("--metrics.port=%d", ports.Metrics),
+		fmt.Sprintf("--port=%d", ports.P2P),
+	}
+}
+
+func (gethDriver) DefaultPorts() ExecutionClientPorts {
+	return ExecutionClientPorts{P2P: 30303, Metrics: 9001, Http: 8545, Ws: 8546, AuthRpc: 8551}
+}
+
+// gethSourceRepo is the repository geth is built from unless SourceRepoUrl and SourceRef are set.
+var gethSourceRepo = utils.SourceRepo{Url: "https://github.com/ethereum/go-ethereum.git", Ref: "master"}
+
+func (gethDriver) DefaultImage() string { return "ethereum/client-go:stable" }
+
+func (gethDriver) ConfigFileName() string { return "geth.toml" }
+
 // NewGethComponent creates a new ExecutionClientComponent resource that represents a geth client
 // and the necessary infrastructure to run it.
 //
@@ -30,373 +103,118 @@ import (
 //		DataDir:        "/data/mainnet/geth", // path to the data directory
 //	})
 func NewGethComponent(ctx *pulumi.Context, name string, args *ExecutionClientComponentArgs, opts ...pulumi.ResourceOption) (*ExecutionClientComponent, error) {
-	if args == nil {
-		args = &ExecutionClientComponentArgs{}
-	}
-
-	component := &ExecutionClientComponent{}
-	err := ctx.RegisterComponentResource(fmt.Sprintf("custom:component:ExecutionClient:%s", args.Client), name, component, opts...)
-	if err != nil {
-		return nil, err
-	}
+	return newClientComponent(ctx, name, gethDriver{}, args, opts...)
+}
 
+// gethSource builds geth from source on the remote host and runs it as a systemd service.
+func gethSource(ctx *pulumi.Context, component *ExecutionClientComponent, args *ExecutionClientComponentArgs) error {
 	// Execute a sequence of commands on the remote server
-	_, err = remote.NewCommand(ctx, fmt.Sprintf("createDataDir-%s", args.Client), &remote.CommandArgs{
+	_, err := remote.NewCommand(ctx, fmt.Sprintf("createDataDir-%s", resourcePrefix(args)), &remote.CommandArgs{
 		Create:     pulumi.Sprintf("mkdir -p %s", args.DataDir),
 		Connection: args.Connection,
 	}, pulumi.Parent(component))
 	if err != nil {
 		ctx.Log.Error("Error creating data directory", nil)
-		return nil, err
+		return err
 	}
 
-	if args.DeploymentType == Source {
-		// Load configuration
-		cfg := config.New(ctx, "")
-
-		// clone repo
-		repo, err := remote.NewCommand(ctx, fmt.Sprintf("cloneRepo-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("git clone -b %s %s /data/repos/%s", cfg.Require("gethBranch"), cfg.Require("gethRepoUrl"), args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error cloning repo", nil)
-			return nil, err
-		}
-
-		// install go
-		goDeps, err := remote.NewCommand(ctx, fmt.Sprintf("installGo-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.String("sudo apt update && sudo apt install -y golang-go"),
-			Connection: args.Connection,
-		}, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error installing go", nil)
-			return nil, err
-		}
-
-		// set repo permissions
-		repoPerms, err := remote.NewCommand(ctx, fmt.Sprintf("setRepoPermissions-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("chown -R %s:%s /data/repos/%s", args.Client, args.Client, args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{repo}))
-		if err != nil {
-			ctx.Log.Error("Error setting repo permissions", nil)
-			return nil, err
-		}
-
-		// build execution client
-		buildClient, err := remote.NewCommand(ctx, fmt.Sprintf("buildExecutionClient-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("cd /data/repos/%s && sudo -u %s make geth", args.Client, args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{goDeps, repoPerms}))
-		if err != nil {
-			ctx.Log.Error("Error building execution client", nil)
-			return nil, err
-		}
-
-		// move client binary to /usr/local/bin
-		_, err = remote.NewCommand(ctx, fmt.Sprintf("moveClientBinary-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("mv /data/repos/%s/build/bin/geth /usr/local/bin/geth", args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{buildClient}))
-		if err != nil {
-			ctx.Log.Error("Error moving client binary", nil)
-			return nil, err
-		}
-
-		// copy start script
-		startScript, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyStartScript-%s", args.Client), &remote.CopyFileArgs{
-			LocalPath:  pulumi.Sprintf("scripts/start_%s.sh", args.Client),
-			RemotePath: pulumi.Sprintf("/data/scripts/start_%s.sh", args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error copying start script", nil)
-			return nil, err
-		}
-
-		// script permissions
-		scriptPerms, err := remote.NewCommand(ctx, fmt.Sprintf("scriptPermissions-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("chmod +x /data/scripts/start_%s.sh", args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{startScript}))
-		if err != nil {
-			ctx.Log.Error("Error setting script permissions", nil)
-			return nil, err
-		}
-
-		// create service
-		serviceDefinition, err := utils.NewServiceDefinitionComponent(ctx, fmt.Sprintf("executionService-%s", args.Client), &utils.ServiceComponentArgs{
-			Connection:  args.Connection,
-			ServiceType: args.Client,
-			Network:     args.Network,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{buildClient, scriptPerms}))
-		if err != nil {
-			ctx.Log.Error("Error creating execution service", nil)
-			return nil, err
-		}
-
-		// group permissions
-		_, err = remote.NewCommand(ctx, fmt.Sprintf("setDataDirGroupPermissions-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("chown -R %s:%s %s && chown %s:%s /data/scripts/start_%s.sh && chown /usr/local/bin/%s", args.Client, args.Client, args.DataDir, args.Client, args.Client, args.Client, args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{serviceDefinition, scriptPerms, startScript}))
-		if err != nil {
-			ctx.Log.Error("Error setting group permissions", nil)
-			return nil, err
-		}
-
-	} else if args.DeploymentType == Kubernetes {
-		// Define static string variables
-		gethDataVolumeName := pulumi.String("geth-config-data")
-		gethTomlData, err := os.ReadFile(args.ExecutionClientConfigPath)
-		if err != nil {
-			return nil, err
-		}
+	// clone repo
+	repo, err := remote.NewCommand(ctx, fmt.Sprintf("cloneRepo-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.String(sourceRepo(args, gethSourceRepo).CloneCommand(fmt.Sprintf("/data/repos/%s", args.Client))),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error cloning repo", nil)
+		return err
+	}
 
-		// Create a ConfigMap with the content of geth.toml
-		configMap, err := corev1.NewConfigMap(ctx, "geth-config", &corev1.ConfigMapArgs{
-			Data: pulumi.StringMap{
-				"geth.toml": pulumi.String(string(gethTomlData)),
-			},
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("geth-config"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("geth-config"),
-					"app.kubernetes.io/part-of": pulumi.String("geth"),
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
+	// install go
+	goDeps, err := remote.NewCommand(ctx, fmt.Sprintf("installGo-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.String("sudo apt update && sudo apt install -y golang-go"),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error installing go", nil)
+		return err
+	}
 
-		// Define the PersistentVolumeClaim for 1.5TB storage
-		storageSize := pulumi.String(args.PodStorageSize) // 30Gi size for holesky
-		_, err = corev1.NewPersistentVolumeClaim(ctx, "geth-data", &corev1.PersistentVolumeClaimArgs{
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: gethDataVolumeName,
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("geth-data"),
-					"app.kubernetes.io/part-of": pulumi.String("geth"),
-				},
-			},
-			Spec: &corev1.PersistentVolumeClaimSpecArgs{
-				AccessModes: pulumi.StringArray{pulumi.String("ReadWriteOnce")}, // This should match your requirements
-				Resources: &corev1.VolumeResourceRequirementsArgs{
-					Requests: pulumi.StringMap{
-						"storage": storageSize,
-					},
-				},
-				StorageClassName: pulumi.String(args.PodStorageClass),
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
+	// set repo permissions
+	repoPerms, err := remote.NewCommand(ctx, fmt.Sprintf("setRepoPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chown -R %s:%s /data/repos/%s", args.Client, args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{repo}))
+	if err != nil {
+		ctx.Log.Error("Error setting repo permissions", nil)
+		return err
+	}
 
-		// Create a secret for the execution jwt
-		secret, err := corev1.NewSecret(ctx, "execution-jwt", &corev1.SecretArgs{
-			StringData: pulumi.StringMap{
-				"jwt.hex": pulumi.String(args.ExecutionJwt),
-			},
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("execution-jwt"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name": pulumi.String("execution-jwt"),
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
+	// build execution client
+	buildClient, err := remote.NewCommand(ctx, fmt.Sprintf("buildExecutionClient-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("cd /data/repos/%s && sudo -u %s make geth", args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{goDeps, repoPerms}))
+	if err != nil {
+		ctx.Log.Error("Error building execution client", nil)
+		return err
+	}
 
-		// Define the StatefulSet for the 'geth' container with a configmap volume and a data persistent volume
-		_, err = appsv1.NewStatefulSet(ctx, "geth-set", &appsv1.StatefulSetArgs{
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("geth"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("geth-set"),
-					"app.kubernetes.io/part-of": pulumi.String("geth"),
-				},
-			},
-			Spec: &appsv1.StatefulSetSpecArgs{
-				Replicas: pulumi.Int(1),
-				Selector: &metav1.LabelSelectorArgs{
-					MatchLabels: pulumi.StringMap{
-						"app": pulumi.String("geth"),
-					},
-				},
-				Template: &corev1.PodTemplateSpecArgs{
-					Metadata: &metav1.ObjectMetaArgs{
-						Labels: pulumi.StringMap{
-							"app":                       pulumi.String("geth"),
-							"app.kubernetes.io/name":    pulumi.String("geth"),
-							"app.kubernetes.io/part-of": pulumi.String("geth"),
-						},
-					},
-					Spec: &corev1.PodSpecArgs{
-						Containers: corev1.ContainerArray{
-							corev1.ContainerArgs{
-								Name:    pulumi.String("geth"),
-								Image:   pulumi.String(args.ExecutionClientImage),
-								Command: pulumi.ToStringArray(args.ExecutionClientContainerCommands),
-								Ports: corev1.ContainerPortArray{
-									corev1.ContainerPortArgs{
-										ContainerPort: pulumi.Int(30303),
-									},
-									corev1.ContainerPortArgs{
-										ContainerPort: pulumi.Int(30303),
-										Protocol:      pulumi.String("UDP"),
-									},
-									corev1.ContainerPortArgs{
-										ContainerPort: pulumi.Int(9001),
-									},
-									corev1.ContainerPortArgs{
-										ContainerPort: pulumi.Int(8545),
-									},
-									corev1.ContainerPortArgs{
-										ContainerPort: pulumi.Int(8551),
-									},
-								},
-								VolumeMounts: corev1.VolumeMountArray{
-									corev1.VolumeMountArgs{
-										Name:      pulumi.String("geth-config"),
-										MountPath: pulumi.String("/etc/geth"),
-									},
-									corev1.VolumeMountArgs{
-										Name:      gethDataVolumeName,
-										MountPath: pulumi.String("/root/.local/share/geth"),
-									},
-									corev1.VolumeMountArgs{
-										Name:      pulumi.String("execution-jwt"),
-										MountPath: pulumi.String("/etc/geth/execution-jwt"),
-									},
-								},
-								Resources: &corev1.ResourceRequirementsArgs{
-									Limits: pulumi.StringMap{
-										"cpu":    pulumi.String(args.CpuLimit),
-										"memory": pulumi.String(args.MemoryLimit),
-									},
-									Requests: pulumi.StringMap{
-										"cpu":    pulumi.String(args.CpuRequest),
-										"memory": pulumi.String(args.MemoryRequest),
-									},
-								},
-							},
-						},
-						Volumes: corev1.VolumeArray{
-							corev1.VolumeArgs{
-								Name: pulumi.String("geth-config"),
-								ConfigMap: &corev1.ConfigMapVolumeSourceArgs{
-									Name: configMap.Metadata.Name(),
-								},
-							},
-							corev1.VolumeArgs{
-								Name: gethDataVolumeName,
-								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSourceArgs{
-									ClaimName: gethDataVolumeName,
-								},
-							},
-							corev1.VolumeArgs{
-								Name: pulumi.String("execution-jwt"),
-								Secret: &corev1.SecretVolumeSourceArgs{
-									SecretName: secret.Metadata.Name(),
-								},
-							},
-						},
-					},
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
+	// move client binary to /usr/local/bin
+	_, err = remote.NewCommand(ctx, fmt.Sprintf("moveClientBinary-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("mv /data/repos/%s/build/bin/geth /usr/local/bin/geth", args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{buildClient}))
+	if err != nil {
+		ctx.Log.Error("Error moving client binary", nil)
+		return err
+	}
 
-		// Create a Service for external ports
-		_, err = corev1.NewService(ctx, "geth-p2pnet-service", &corev1.ServiceArgs{
-			Spec: &corev1.ServiceSpecArgs{
-				Selector: pulumi.StringMap{"app": pulumi.String("geth")},
-				Type:     pulumi.String("NodePort"),
-				Ports: corev1.ServicePortArray{
-					&corev1.ServicePortArgs{
-						Port: pulumi.Int(30303),
-						Name: pulumi.String("p2p-tcp"),
-					},
-					&corev1.ServicePortArgs{
-						Port:     pulumi.Int(30303),
-						Protocol: pulumi.String("UDP"),
-						Name:     pulumi.String("p2p-udp"),
-					},
-				},
-			},
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("geth-p2pnet-service"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("geth-p2pnet-service"),
-					"app.kubernetes.io/part-of": pulumi.String("geth"),
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
+	// copy start script
+	startScript, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyStartScript-%s", resourcePrefix(args)), &remote.CopyFileArgs{
+		LocalPath:  pulumi.Sprintf("scripts/start_%s.sh", args.Client),
+		RemotePath: pulumi.Sprintf("/data/scripts/start_%s.sh", args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error copying start script", nil)
+		return err
+	}
 
-		// Create a service for internal ports
-		_, err = corev1.NewService(ctx, "geth-internal-service", &corev1.ServiceArgs{
-			Spec: &corev1.ServiceSpecArgs{
-				Selector: pulumi.StringMap{"app": pulumi.String("geth")},
-				Type:     pulumi.String("ClusterIP"),
-				Ports: corev1.ServicePortArray{
-					corev1.ServicePortArgs{
-						Port: pulumi.Int(9001),
-						Name: pulumi.String("metrics"),
-					},
-					corev1.ServicePortArgs{
-						Port: pulumi.Int(8551),
-						Name: pulumi.String("p2p"),
-					},
-				},
-			},
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("geth-internal-service"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("geth-internal-service"),
-					"app.kubernetes.io/part-of": pulumi.String("geth"),
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
+	// script permissions
+	scriptPerms, err := remote.NewCommand(ctx, fmt.Sprintf("scriptPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chmod +x /data/scripts/start_%s.sh", args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{startScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting script permissions", nil)
+		return err
+	}
 
-		// Create ingress for the geth rpc traffic on port 8545
-		_, err = corev1.NewService(ctx, "geth-rpc-service", &corev1.ServiceArgs{
-			Spec: &corev1.ServiceSpecArgs{
-				Selector: pulumi.StringMap{"app": pulumi.String("geth")},
-				Type:     pulumi.String("NodePort"),
-				Ports: corev1.ServicePortArray{
-					corev1.ServicePortArgs{
-						Port:       pulumi.Int(8545),
-						TargetPort: pulumi.Int(8545),
-					},
-				},
-			},
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("geth-rpc-service"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("geth-rpc-service"),
-					"app.kubernetes.io/part-of": pulumi.String("geth"),
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
-		return component, nil
+	// write the shared engine api jwt
+	jwtSecret, err := sourceJwtSecret(ctx, component, args)
+	if err != nil {
+		return err
+	}
 
+	// create service
+	serviceDefinition, err := utils.NewServiceDefinitionComponent(ctx, fmt.Sprintf("executionService-%s", resourcePrefix(args)), &utils.ServiceComponentArgs{
+		Connection:  args.Connection,
+		ServiceType: args.Client,
+		Network:     args.Network,
+	}, pulumi.Parent(component), pulumi.DependsOn(append([]pulumi.Resource{buildClient, scriptPerms}, jwtSecret...)))
+	if err != nil {
+		ctx.Log.Error("Error creating execution service", nil)
+		return err
 	}
 
-	return component, nil
+	// group permissions
+	_, err = remote.NewCommand(ctx, fmt.Sprintf("setDataDirGroupPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chown -R %s:%s %s && chown %s:%s /data/scripts/start_%s.sh && chown /usr/local/bin/%s", args.Client, args.Client, args.DataDir, args.Client, args.Client, args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{serviceDefinition, scriptPerms, startScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting group permissions", nil)
+		return err
+	}
 
+	return setSourceEndpoints(ctx, component, gethDriver{}.DefaultPorts(), args)
 }
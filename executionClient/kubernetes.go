@@ -0,0 +1,437 @@
+package executionClient
+
+import (
+	"os"
+	"strings"
+
+	appsv1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/apps/v1"
+	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
+	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// volumeClaim is a persistent volume kubernetes deployments of a client mount
+// in addition to the data volume.
+type volumeClaim struct {
+	// name is appended to the client's name to name the claim.
+	name      string
+	mountPath string
+	// size and snapshot return the requested storage and the VolumeSnapshot
+	// the claim is restored from, empty for a new volume.
+	size     func(args *ExecutionClientComponentArgs) string
+	snapshot func(args *ExecutionClientComponentArgs) string
+}
+
+// kubernetesHook returns the build hook running the client described by spec
+// as a kubernetes StatefulSet.
+func kubernetesHook(driver ExecutionClientDriver, spec containerSpec) ExecutionClientBuildHook {
+	return func(ctx *pulumi.Context, component *ExecutionClientComponent, args *ExecutionClientComponentArgs) error {
+		return deployKubernetes(ctx, component, driver, spec, args)
+	}
+}
+
+// kubernetesNames returns the names of the client's kubernetes objects, they
+// start with args.Name or the client.
+func kubernetesNames(driver ExecutionClientDriver, args *ExecutionClientComponentArgs) utils.KubernetesNames {
+	return utils.KubernetesNames{Name: containerName(driver, args), Namespace: args.Namespace, PartOf: driver.Client(), Legacy: legacyNames(driver, args)}
+}
+
+// legacyNames returns the names the objects of the built-in clients had before
+// they were derived from args.Name, so upgrading a stack keeps their data
+// volumes and services. reth-exex always named them after args.Name, the
+// other clients after themselves, which they still are when args.Name is
+// unset or the client.
+func legacyNames(driver ExecutionClientDriver, args *ExecutionClientComponentArgs) map[string]string {
+	name := containerName(driver, args)
+	switch driver.Client() {
+	case RethExEx:
+		return map[string]string{
+			"data":        name + "-config-data",
+			"exex":        name + "-persistent-storage",
+			"p2p-service": name + "-p2pnet-service",
+		}
+	case Geth, Nethermind, Reth, Besu, Erigon, OpGeth:
+		if name != driver.Client() {
+			return nil
+		}
+		legacy := map[string]string{
+			"data":        name + "-config-data",
+			"p2p-service": name + "-p2pnet-service",
+		}
+		// the first clients shared one jwt secret
+		if name == Geth || name == Nethermind || name == Reth {
+			legacy["execution-jwt"] = "execution-jwt"
+		}
+		return legacy
+	}
+	return nil
+}
+
+// deployKubernetes runs an execution client as a StatefulSet with a config
+// map, a data volume, the execution jwt secret, its p2p, internal and rpc
+// services and the rpc ingress, all named after args.Name in args.Namespace.
+func deployKubernetes(ctx *pulumi.Context, component *ExecutionClientComponent, driver ExecutionClientDriver, spec containerSpec, args *ExecutionClientComponentArgs) error {
+	names := kubernetesNames(driver, args)
+	ports := driver.DefaultPorts()
+
+	// Create a ConfigMap with the content of the client config file
+	configData, err := os.ReadFile(args.ExecutionClientConfigPath)
+	if err != nil {
+		return err
+	}
+	configFiles := pulumi.StringMap{
+		driver.ConfigFileName(): pulumi.String(string(configData)),
+	}
+	if spec.configFiles != nil {
+		for key, localPath := range spec.configFiles(args) {
+			data, err := os.ReadFile(localPath)
+			if err != nil {
+				return err
+			}
+			configFiles[key] = pulumi.String(string(data))
+		}
+	}
+	configMap, err := corev1.NewConfigMap(ctx, names.Resource("config"), &corev1.ConfigMapArgs{
+		Data:     configFiles,
+		Metadata: names.Metadata("config"),
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	// Create the data volume and the volumes of the client
+	claims := append([]volumeClaim{{
+		name:      "data",
+		mountPath: spec.dataDir,
+		size:      func(args *ExecutionClientComponentArgs) string { return args.PodStorageSize },
+		snapshot:  func(args *ExecutionClientComponentArgs) string { return args.RethSnapshotName },
+	}}, spec.claims...)
+	var volumes corev1.VolumeArray
+	var volumeMounts corev1.VolumeMountArray
+	for _, claim := range claims {
+		pvcSpec := &corev1.PersistentVolumeClaimSpecArgs{
+			AccessModes: pulumi.StringArray{pulumi.String("ReadWriteOnce")},
+			Resources: &corev1.VolumeResourceRequirementsArgs{
+				Requests: pulumi.StringMap{
+					"storage": pulumi.String(claim.size(args)),
+				},
+			},
+			StorageClassName: pulumi.String(args.PodStorageClass),
+		}
+		if snapshot := claim.snapshot(args); snapshot != "" {
+			pvcSpec.DataSource = &corev1.TypedLocalObjectReferenceArgs{
+				Kind:     pulumi.String("VolumeSnapshot"),
+				Name:     pulumi.String(snapshot),
+				ApiGroup: pulumi.String("snapshot.storage.k8s.io"),
+			}
+		}
+		_, err = corev1.NewPersistentVolumeClaim(ctx, names.Resource(claim.name), &corev1.PersistentVolumeClaimArgs{
+			Metadata: names.Metadata(claim.name),
+			Spec:     pvcSpec,
+		}, pulumi.Parent(component), names.Aliases(claim.name))
+		if err != nil {
+			return err
+		}
+		volumes = append(volumes, corev1.VolumeArgs{
+			Name: pulumi.String(claim.name),
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSourceArgs{
+				ClaimName: pulumi.String(names.Object(claim.name)),
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMountArgs{
+			Name:      pulumi.String(claim.name),
+			MountPath: pulumi.String(claim.mountPath),
+		})
+	}
+
+	// Create a secret for the execution jwt
+	secretName, err := utils.NewKubernetesSecret(ctx, names.Resource("execution-jwt"), &utils.KubernetesSecretArgs{
+		Name:      names.Object("execution-jwt"),
+		Namespace: names.Namespace,
+		Labels:    names.Labels("execution-jwt"),
+		StringData: pulumi.StringMap{
+			"jwt.hex": args.ExecutionJwt,
+		},
+		Rendering: args.manifestRendering(),
+	}, pulumi.Parent(component), names.Aliases("execution-jwt"))
+	if err != nil {
+		return err
+	}
+
+	volumes = append(volumes,
+		corev1.VolumeArgs{
+			Name: pulumi.String("config"),
+			ConfigMap: &corev1.ConfigMapVolumeSourceArgs{
+				Name: configMap.Metadata.Name(),
+			},
+		},
+		corev1.VolumeArgs{
+			Name: pulumi.String("execution-jwt"),
+			Secret: &corev1.SecretVolumeSourceArgs{
+				SecretName: secretName,
+			},
+		},
+	)
+	volumeMounts = append(volumeMounts,
+		corev1.VolumeMountArgs{
+			Name:      pulumi.String("config"),
+			MountPath: pulumi.String(spec.configDir),
+		},
+		corev1.VolumeMountArgs{
+			Name:      pulumi.String("execution-jwt"),
+			MountPath: pulumi.String(spec.jwtDir),
+		},
+	)
+
+	containerPorts := corev1.ContainerPortArray{
+		corev1.ContainerPortArgs{
+			ContainerPort: pulumi.Int(ports.P2P),
+		},
+		corev1.ContainerPortArgs{
+			ContainerPort: pulumi.Int(ports.P2P),
+			Protocol:      pulumi.String("UDP"),
+		},
+		corev1.ContainerPortArgs{
+			ContainerPort: pulumi.Int(ports.Metrics),
+		},
+		corev1.ContainerPortArgs{
+			ContainerPort: pulumi.Int(ports.Http),
+		},
+		corev1.ContainerPortArgs{
+			ContainerPort: pulumi.Int(ports.AuthRpc),
+		},
+	}
+	p2pPorts := corev1.ServicePortArray{
+		corev1.ServicePortArgs{
+			Port: pulumi.Int(ports.P2P),
+			Name: pulumi.String("p2p-tcp"),
+		},
+		corev1.ServicePortArgs{
+			Port:     pulumi.Int(ports.P2P),
+			Protocol: pulumi.String("UDP"),
+			Name:     pulumi.String("p2p-udp"),
+		},
+	}
+	internalPorts := corev1.ServicePortArray{
+		corev1.ServicePortArgs{
+			Port: pulumi.Int(ports.Metrics),
+			Name: pulumi.String("metrics"),
+		},
+		corev1.ServicePortArgs{
+			Port: pulumi.Int(ports.AuthRpc),
+			Name: pulumi.String("p2p"),
+		},
+	}
+	rpcPorts := corev1.ServicePortArray{
+		corev1.ServicePortArgs{
+			Port:       pulumi.Int(ports.Http),
+			TargetPort: pulumi.Int(ports.Http),
+			Name:       pulumi.String("http"),
+		},
+	}
+	// clients serving websockets on the http port get no separate ws port
+	if ports.Ws != ports.Http {
+		containerPorts = append(containerPorts, corev1.ContainerPortArgs{
+			ContainerPort: pulumi.Int(ports.Ws),
+		})
+		rpcPorts = append(rpcPorts, corev1.ServicePortArgs{
+			Port:       pulumi.Int(ports.Ws),
+			TargetPort: pulumi.Int(ports.Ws),
+			Name:       pulumi.String("ws"),
+		})
+	}
+	if ports.Torrent != 0 {
+		containerPorts = append(containerPorts,
+			corev1.ContainerPortArgs{
+				ContainerPort: pulumi.Int(ports.Torrent),
+			},
+			corev1.ContainerPortArgs{
+				ContainerPort: pulumi.Int(ports.Torrent),
+				Protocol:      pulumi.String("UDP"),
+			},
+		)
+		p2pPorts = append(p2pPorts,
+			corev1.ServicePortArgs{
+				Port: pulumi.Int(ports.Torrent),
+				Name: pulumi.String("torrent-tcp"),
+			},
+			corev1.ServicePortArgs{
+				Port:     pulumi.Int(ports.Torrent),
+				Protocol: pulumi.String("UDP"),
+				Name:     pulumi.String("torrent-udp"),
+			},
+		)
+	}
+	if spec.ports != nil {
+		for _, port := range spec.ports(args) {
+			containerPort := corev1.ContainerPortArgs{
+				ContainerPort: pulumi.Int(port.Port),
+			}
+			if port.Protocol != "" {
+				containerPort.Protocol = pulumi.String(strings.ToUpper(port.Protocol))
+			}
+			containerPorts = append(containerPorts, containerPort)
+		}
+	}
+	if spec.servicePorts != nil {
+		p2p, internal := spec.servicePorts(args)
+		p2pPorts = append(p2pPorts, p2p...)
+		internalPorts = append(internalPorts, internal...)
+	}
+
+	container := corev1.ContainerArgs{
+		Name:         pulumi.String(names.Name),
+		Image:        pulumi.String(image(driver, args)),
+		Command:      pulumi.ToStringArray(args.ExecutionClientContainerCommands),
+		Args:         spec.containerArgs(args),
+		Ports:        containerPorts,
+		VolumeMounts: volumeMounts,
+		Resources: &corev1.ResourceRequirementsArgs{
+			Limits: pulumi.StringMap{
+				"cpu":    pulumi.String(args.CpuLimit),
+				"memory": pulumi.String(args.MemoryLimit),
+			},
+			Requests: pulumi.StringMap{
+				"cpu":    pulumi.String(args.CpuRequest),
+				"memory": pulumi.String(args.MemoryRequest),
+			},
+		},
+	}
+
+	// pass the environment through a config map
+	statefulSetDeps := []pulumi.Resource{}
+	if len(args.Environment) > 0 {
+		envConfigMap, err := corev1.NewConfigMap(ctx, names.Resource("env-config"), &corev1.ConfigMapArgs{
+			Data:     pulumi.ToStringMap(args.Environment),
+			Metadata: names.Metadata("env-config"),
+		}, pulumi.Parent(component))
+		if err != nil {
+			return err
+		}
+		container.EnvFrom = corev1.EnvFromSourceArray{
+			corev1.EnvFromSourceArgs{
+				ConfigMapRef: &corev1.ConfigMapEnvSourceArgs{
+					Name: pulumi.String(names.Object("env-config")),
+				},
+			},
+		}
+		statefulSetDeps = append(statefulSetDeps, envConfigMap)
+	}
+
+	// probe the client's sync state through the probe sidecar
+	containers := corev1.ContainerArray{}
+	if probes := args.Probes(); !probes.Disabled {
+		sidecar, probeVolume, err := newProbeSidecar(ctx, component, names, ports, args)
+		if err != nil {
+			return err
+		}
+		checks := probes.HttpProbes(probePort, "/cgi-bin/live", "/cgi-bin/ready")
+		container.StartupProbe = checks.Startup
+		container.LivenessProbe = checks.Liveness
+		container.ReadinessProbe = checks.Readiness
+		containers = append(containers, sidecar)
+		volumes = append(volumes, probeVolume)
+	}
+	containers = append(corev1.ContainerArray{container}, containers...)
+
+	// prepare the data volume before the client starts, e.g. from a custom genesis
+	initContainers := corev1.ContainerArray{}
+	if spec.initCommand != nil {
+		if command := spec.initCommand(args, spec); command != "" {
+			initContainers = append(initContainers, corev1.ContainerArgs{
+				Name:         pulumi.String(names.Object("init")),
+				Image:        pulumi.String(image(driver, args)),
+				Command:      pulumi.ToStringArray([]string{"sh", "-c"}),
+				Args:         pulumi.ToStringArray([]string{command}),
+				VolumeMounts: volumeMounts,
+			})
+		}
+	}
+
+	// Define the StatefulSet running the client with its config, data and jwt volumes
+	podLabels := names.Labels("")
+	podLabels["app"] = pulumi.String(names.Name)
+	_, err = appsv1.NewStatefulSet(ctx, names.Resource("set"), &appsv1.StatefulSetArgs{
+		Metadata: names.Metadata(""),
+		Spec: &appsv1.StatefulSetSpecArgs{
+			Replicas: pulumi.Int(1),
+			Selector: &metav1.LabelSelectorArgs{
+				MatchLabels: names.Selector(),
+			},
+			Template: &corev1.PodTemplateSpecArgs{
+				Metadata: &metav1.ObjectMetaArgs{
+					Labels: podLabels,
+				},
+				Spec: &corev1.PodSpecArgs{
+					InitContainers: initContainers,
+					Containers:     containers,
+					Volumes:        volumes,
+				},
+			},
+		},
+	}, pulumi.Parent(component), pulumi.DependsOn(statefulSetDeps))
+	if err != nil {
+		return err
+	}
+
+	// Create a Service for external ports
+	p2pService, err := corev1.NewService(ctx, names.Resource("p2p-service"), &corev1.ServiceArgs{
+		Spec: &corev1.ServiceSpecArgs{
+			Selector: names.Selector(),
+			Type:     pulumi.String("NodePort"),
+			Ports:    p2pPorts,
+		},
+		Metadata: names.Metadata("p2p-service"),
+	}, pulumi.Parent(component), names.Aliases("p2p-service"))
+	if err != nil {
+		return err
+	}
+
+	// Create a service for internal ports
+	internalService, err := corev1.NewService(ctx, names.Resource("internal-service"), &corev1.ServiceArgs{
+		Spec: &corev1.ServiceSpecArgs{
+			Selector: names.Selector(),
+			Type:     pulumi.String("ClusterIP"),
+			Ports:    internalPorts,
+		},
+		Metadata: names.Metadata("internal-service"),
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	// Create a service for the rpc traffic, only reachable through the ingress when there is one
+	ingress := utils.EnabledIngress(args.EnableIngress, args.Ingress())
+	rpcServiceType := "NodePort"
+	if ingress != nil {
+		rpcServiceType = "ClusterIP"
+	}
+	rpcService, err := corev1.NewService(ctx, names.Resource("rpc-service"), &corev1.ServiceArgs{
+		Spec: &corev1.ServiceSpecArgs{
+			Selector: names.Selector(),
+			Type:     pulumi.String(rpcServiceType),
+			Ports:    rpcPorts,
+		},
+		Metadata: names.Metadata("rpc-service"),
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	// Route the rpc host to the http port and /ws to the ws port
+	if ingress != nil {
+		backends := []utils.IngressBackend{
+			{Name: "rpc", Path: "/", Service: names.Object("rpc-service"), Port: ports.Http},
+		}
+		if ports.Ws != ports.Http {
+			backends = append(backends, utils.IngressBackend{Name: "ws", Path: "/ws", Service: names.Object("rpc-service"), Port: ports.Ws})
+		}
+		err = utils.NewIngress(ctx, names, ingress, backends, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{rpcService}))
+		if err != nil {
+			return err
+		}
+	}
+
+	return setKubernetesEndpoints(ctx, component, names, ports, args, rpcService, internalService, p2pService)
+}
@@ -2,17 +2,89 @@ package executionClient
 
 import (
 	"fmt"
-	"os"
+	"path"
+	"strings"
 
 	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
-	appsv1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/apps/v1"
-	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
-	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
-	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
 	"github.com/rswanson/node_deployer/utils"
 )
 
+func init() {
+	RegisterExecutionClient(nethermindDriver{})
+}
+
+// nethermindDriver is the built-in ExecutionClientDriver for nethermind.
+type nethermindDriver struct{}
+
+func (nethermindDriver) Client() string { return Nethermind }
+
+func (nethermindDriver) BuildHooks() map[string]ExecutionClientBuildHook {
+	return map[string]ExecutionClientBuildHook{
+		Source:     nethermindSource,
+		Binary:     binaryHook(nethermindDriver{}, nethermindRelease),
+		Docker:     dockerHook(nethermindDriver{}, nethermindContainer),
+		Kubernetes: kubernetesHook(nethermindDriver{}, nethermindContainer),
+	}
+}
+
+// nethermindRelease are the nethermind releases published on github, their
+// version includes the commit, e.g. 1.29.1-dfea5240.
+var nethermindRelease = binaryRelease{
+	url: func(version string, arch string) string {
+		tag, _, _ := strings.Cut(version, "-")
+		if arch == "amd64" {
+			arch = "x64"
+		}
+		return fmt.Sprintf("https://github.com/NethermindEth/nethermind/releases/download/%s/nethermind-%s-linux-%s.zip", tag, version, arch)
+	},
+	executable: func(version string, arch string) string { return "nethermind" },
+	link:       "/usr/local/bin/nethermind",
+}
+
+// nethermindContainer is where the nethermind container mounts its volumes in kubernetes and docker.
+var nethermindContainer = containerSpec{
+	configDir: "/etc/nethermind",
+	dataDir:   "/root/.local/share/nethermind",
+	jwtDir:    "/etc/nethermind/execution-jwt",
+	args:      nethermindContainerArgs,
+}
+
+// nethermindContainerArgs returns the flags the nethermind container runs
+// with, matching its mounts. --config selects the network's built-in config
+// like the start script of source deployments does.
+func nethermindContainerArgs(args *ExecutionClientComponentArgs, spec containerSpec) []string {
+	ports := nethermindDriver{}.DefaultPorts()
+	return []string{
+		"--config=" + args.Network,
+		"--datadir=" + spec.dataDir,
+		"--JsonRpc.Enabled=true",
+		"--JsonRpc.Host=0.0.0.0",
+		fmt.Sprintf("--JsonRpc.Port=%d", ports.Http),
+		"--JsonRpc.EnabledModules=[" + httpApi(args, "eth,subscribe,trace,txpool,web3,personal,proof,net,parity,health,rpc") + "]",
+		fmt.Sprintf("--JsonRpc.WebSocketsPort=%d", ports.Ws),
+		"--JsonRpc.EngineHost=0.0.0.0",
+		fmt.Sprintf("--JsonRpc.EnginePort=%d", ports.AuthRpc),
+		"--JsonRpc.JwtSecretFile=" + path.Join(spec.jwtDir, "jwt.hex"),
+		"--Init.WebSocketsEnabled=true",
+		"--Metrics.Enabled=true",
+		fmt.Sprintf("--Metrics.ExposePort=%d", ports.Metrics),
+		fmt.Sprintf("--Network.P2PPort=%d", ports.P2P),
+		fmt.Sprintf("--Network.DiscoveryPort=%d", ports.P2P),
+	}
+}
+
+func (nethermindDriver) DefaultPorts() ExecutionClientPorts {
+	return ExecutionClientPorts{P2P: 30303, Metrics: 9001, Http: 8545, Ws: 8546, AuthRpc: 8551}
+}
+
+// nethermindSourceRepo is the repository nethermind is built from unless SourceRepoUrl and SourceRef are set.
+var nethermindSourceRepo = utils.SourceRepo{Url: "https://github.com/NethermindEth/nethermind.git", Ref: "master"}
+
+func (nethermindDriver) DefaultImage() string { return "nethermind/client:latest" }
+
+func (nethermindDriver) ConfigFileName() string { return "nethermind.toml" }
+
 // NewNethermindComponent creates a new Nethermind execution client component
 // and the necessary infrastructure to run it.
 //
@@ -30,362 +102,108 @@ import (
 //		DataDir:        "/data/mainnet/nethermind", // path to the data directory
 //	})
 func NewNethermindComponent(ctx *pulumi.Context, name string, args *ExecutionClientComponentArgs, opts ...pulumi.ResourceOption) (*ExecutionClientComponent, error) {
-	if args == nil {
-		args = &ExecutionClientComponentArgs{}
-	}
-
-	component := &ExecutionClientComponent{}
-	err := ctx.RegisterComponentResource(fmt.Sprintf("custom:component:ExecutionClient:%s", args.Client), name, component, opts...)
-	if err != nil {
-		return nil, err
-	}
+	return newClientComponent(ctx, name, nethermindDriver{}, args, opts...)
+}
 
+// nethermindSource builds nethermind from source on the remote host and runs it as a systemd service.
+func nethermindSource(ctx *pulumi.Context, component *ExecutionClientComponent, args *ExecutionClientComponentArgs) error {
 	// Execute a sequence of commands on the remote server
-	_, err = remote.NewCommand(ctx, fmt.Sprintf("createDataDir-%s", args.Client), &remote.CommandArgs{
+	_, err := remote.NewCommand(ctx, fmt.Sprintf("createDataDir-%s", resourcePrefix(args)), &remote.CommandArgs{
 		Create:     pulumi.Sprintf("mkdir -p %s", args.DataDir),
 		Connection: args.Connection,
 	}, pulumi.Parent(component))
 	if err != nil {
 		ctx.Log.Error("Error creating data directory", nil)
-		return nil, err
+		return err
 	}
 
-	if args.DeploymentType == Source {
-		// Load configuration
-		cfg := config.New(ctx, "")
-
-		// clone repo
-		repo, err := remote.NewCommand(ctx, fmt.Sprintf("cloneRepo-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("git clone -b %s %s /data/repos/%s", cfg.Require("nethermindBranch"), cfg.Require("nethermindRepoUrl"), args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error cloning repo", nil)
-			return nil, err
-		}
-
-		// install dotnet
-		dotnetDeps, err := remote.NewCommand(ctx, fmt.Sprintf("installDotnet-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.String("sudo apt update && sudo apt install -y dotnet-sdk-5.0"),
-			Connection: args.Connection,
-		}, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error installing dotnet", nil)
-			return nil, err
-		}
-
-		// set repo permissions
-		repoPerms, err := remote.NewCommand(ctx, fmt.Sprintf("setRepoPermissions-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("chown -R %s:%s /data/repos/%s", args.Client, args.Client, args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{repo}))
-		if err != nil {
-			ctx.Log.Error("Error setting repo permissions", nil)
-			return nil, err
-		}
-
-		// build execution client
-		buildClient, err := remote.NewCommand(ctx, fmt.Sprintf("buildExecutionClient-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("cd /data/repos/%s && sudo -u %s dotnet build -c Release", args.Client, args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{dotnetDeps, repoPerms}))
-		if err != nil {
-			ctx.Log.Error("Error building execution client", nil)
-			return nil, err
-		}
-
-		// copy start script
-		startScript, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyStartScript-%s", args.Client), &remote.CopyFileArgs{
-			LocalPath:  pulumi.Sprintf("scripts/start_%s.sh", args.Client),
-			RemotePath: pulumi.Sprintf("/data/scripts/start_%s.sh", args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component))
-		if err != nil {
-			ctx.Log.Error("Error copying start script", nil)
-			return nil, err
-		}
-
-		// script permissions
-		scriptPerms, err := remote.NewCommand(ctx, fmt.Sprintf("scriptPermissions-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("chmod +x /data/scripts/start_%s.sh", args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{startScript}))
-		if err != nil {
-			ctx.Log.Error("Error setting script permissions", nil)
-			return nil, err
-		}
-
-		// create service
-		serviceDefinition, err := utils.NewServiceDefinitionComponent(ctx, fmt.Sprintf("executionService-%s", args.Client), &utils.ServiceComponentArgs{
-			Connection:  args.Connection,
-			ServiceType: args.Client,
-			Network:     args.Network,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{buildClient, scriptPerms}))
-		if err != nil {
-			ctx.Log.Error("Error creating execution service", nil)
-			return nil, err
-		}
-
-		// group permissions
-		_, err = remote.NewCommand(ctx, fmt.Sprintf("setDataDirGroupPermissions-%s", args.Client), &remote.CommandArgs{
-			Create:     pulumi.Sprintf("chown -R %s:%s %s && chown %s:%s /data/scripts/start_%s.sh", args.Client, args.Client, args.DataDir, args.Client, args.Client, args.Client),
-			Connection: args.Connection,
-		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{serviceDefinition, scriptPerms, startScript}))
-		if err != nil {
-			ctx.Log.Error("Error setting group permissions", nil)
-			return nil, err
-		}
-
-	} else if args.DeploymentType == Kubernetes {
-		// Define static string variables
-		nethermindDataVolumeName := pulumi.String("nethermind-config-data")
-		nethermindTomlData, err := os.ReadFile(args.ExecutionClientConfigPath)
-		if err != nil {
-			return nil, err
-		}
+	// clone repo
+	repo, err := remote.NewCommand(ctx, fmt.Sprintf("cloneRepo-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.String(sourceRepo(args, nethermindSourceRepo).CloneCommand(fmt.Sprintf("/data/repos/%s", args.Client))),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error cloning repo", nil)
+		return err
+	}
 
-		// Create a ConfigMap with the content of nethermind.toml
-		configMap, err := corev1.NewConfigMap(ctx, "nethermind-config", &corev1.ConfigMapArgs{
-			Data: pulumi.StringMap{
-				"nethermind.toml": pulumi.String(string(nethermindTomlData)),
-			},
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("nethermind-config"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("nethermind-config"),
-					"app.kubernetes.io/part-of": pulumi.String("nethermind"),
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
+	// install dotnet
+	dotnetDeps, err := remote.NewCommand(ctx, fmt.Sprintf("installDotnet-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.String("sudo apt update && sudo apt install -y dotnet-sdk-5.0"),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error installing dotnet", nil)
+		return err
+	}
 
-		// Define the PersistentVolumeClaim for 1.5TB storage
-		storageSize := pulumi.String(args.PodStorageSize) // 30Gi size for holesky
-		_, err = corev1.NewPersistentVolumeClaim(ctx, "nethermind-data", &corev1.PersistentVolumeClaimArgs{
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: nethermindDataVolumeName,
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("nethermind-data"),
-					"app.kubernetes.io/part-of": pulumi.String("nethermind"),
-				},
-			},
-			Spec: &corev1.PersistentVolumeClaimSpecArgs{
-				AccessModes: pulumi.StringArray{pulumi.String("ReadWriteOnce")}, // This should match your requirements
-				Resources: &corev1.VolumeResourceRequirementsArgs{
-					Requests: pulumi.StringMap{
-						"storage": storageSize,
-					},
-				},
-				StorageClassName: pulumi.String(args.PodStorageClass),
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
+	// set repo permissions
+	repoPerms, err := remote.NewCommand(ctx, fmt.Sprintf("setRepoPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chown -R %s:%s /data/repos/%s", args.Client, args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{repo}))
+	if err != nil {
+		ctx.Log.Error("Error setting repo permissions", nil)
+		return err
+	}
 
-		// Create a secret for the execution jwt
-		secret, err := corev1.NewSecret(ctx, "execution-jwt", &corev1.SecretArgs{
-			StringData: pulumi.StringMap{
-				"jwt.hex": pulumi.String(args.ExecutionJwt),
-			},
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("execution-jwt"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name": pulumi.String("execution-jwt"),
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
+	// build execution client
+	buildClient, err := remote.NewCommand(ctx, fmt.Sprintf("buildExecutionClient-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("cd /data/repos/%s && sudo -u %s dotnet build -c Release", args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{dotnetDeps, repoPerms}))
+	if err != nil {
+		ctx.Log.Error("Error building execution client", nil)
+		return err
+	}
 
-		// Define the StatefulSet for the 'nethermind' container with a configmap volume and a data persistent volume
-		_, err = appsv1.NewStatefulSet(ctx, "nethermind-set", &appsv1.StatefulSetArgs{
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("nethermind"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("nethermind-set"),
-					"app.kubernetes.io/part-of": pulumi.String("nethermind"),
-				},
-			},
-			Spec: &appsv1.StatefulSetSpecArgs{
-				Replicas: pulumi.Int(1),
-				Selector: &metav1.LabelSelectorArgs{
-					MatchLabels: pulumi.StringMap{
-						"app": pulumi.String("nethermind"),
-					},
-				},
-				Template: &corev1.PodTemplateSpecArgs{
-					Metadata: &metav1.ObjectMetaArgs{
-						Labels: pulumi.StringMap{
-							"app":                       pulumi.String("nethermind"),
-							"app.kubernetes.io/name":    pulumi.String("nethermind"),
-							"app.kubernetes.io/part-of": pulumi.String("nethermind"),
-						},
-					},
-					Spec: &corev1.PodSpecArgs{
-						Containers: corev1.ContainerArray{
-							corev1.ContainerArgs{
-								Name:    pulumi.String("nethermind"),
-								Image:   pulumi.String(args.ExecutionClientImage),
-								Command: pulumi.ToStringArray(args.ExecutionClientContainerCommands),
-								Ports: corev1.ContainerPortArray{
-									corev1.ContainerPortArgs{
-										ContainerPort: pulumi.Int(30303),
-									},
-									corev1.ContainerPortArgs{
-										ContainerPort: pulumi.Int(30303),
-										Protocol:      pulumi.String("UDP"),
-									},
-									corev1.ContainerPortArgs{
-										ContainerPort: pulumi.Int(9001),
-									},
-									corev1.ContainerPortArgs{
-										ContainerPort: pulumi.Int(8545),
-									},
-									corev1.ContainerPortArgs{
-										ContainerPort: pulumi.Int(8551),
-									},
-								},
-								VolumeMounts: corev1.VolumeMountArray{
-									corev1.VolumeMountArgs{
-										Name:      pulumi.String("nethermind-config"),
-										MountPath: pulumi.String("/etc/nethermind"),
-									},
-									corev1.VolumeMountArgs{
-										Name:      nethermindDataVolumeName,
-										MountPath: pulumi.String("/root/.local/share/nethermind"),
-									},
-									corev1.VolumeMountArgs{
-										Name:      pulumi.String("execution-jwt"),
-										MountPath: pulumi.String("/etc/nethermind/execution-jwt"),
-									},
-								},
-								Resources: &corev1.ResourceRequirementsArgs{
-									Limits: pulumi.StringMap{
-										"cpu":    pulumi.String(args.CpuLimit),
-										"memory": pulumi.String(args.MemoryLimit),
-									},
-									Requests: pulumi.StringMap{
-										"cpu":    pulumi.String(args.CpuRequest),
-										"memory": pulumi.String(args.MemoryRequest),
-									},
-								},
-							},
-						},
-						Volumes: corev1.VolumeArray{
-							corev1.VolumeArgs{
-								Name: pulumi.String("nethermind-config"),
-								ConfigMap: &corev1.ConfigMapVolumeSourceArgs{
-									Name: configMap.Metadata.Name(),
-								},
-							},
-							corev1.VolumeArgs{
-								Name: nethermindDataVolumeName,
-								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSourceArgs{
-									ClaimName: nethermindDataVolumeName,
-								},
-							},
-							corev1.VolumeArgs{
-								Name: pulumi.String("execution-jwt"),
-								Secret: &corev1.SecretVolumeSourceArgs{
-									SecretName: secret.Metadata.Name(),
-								},
-							},
-						},
-					},
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
+	// copy start script
+	startScript, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyStartScript-%s", resourcePrefix(args)), &remote.CopyFileArgs{
+		LocalPath:  pulumi.Sprintf("scripts/start_%s.sh", args.Client),
+		RemotePath: pulumi.Sprintf("/data/scripts/start_%s.sh", args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error copying start script", nil)
+		return err
+	}
 
-		// Create a Service for external ports
-		_, err = corev1.NewService(ctx, "nethermind-p2pnet-service", &corev1.ServiceArgs{
-			Spec: &corev1.ServiceSpecArgs{
-				Selector: pulumi.StringMap{"app": pulumi.String("nethermind")},
-				Type:     pulumi.String("NodePort"),
-				Ports: corev1.ServicePortArray{
-					&corev1.ServicePortArgs{
-						Port: pulumi.Int(30303),
-						Name: pulumi.String("p2p-tcp"),
-					},
-					&corev1.ServicePortArgs{
-						Port:     pulumi.Int(30303),
-						Protocol: pulumi.String("UDP"),
-						Name:     pulumi.String("p2p-udp"),
-					},
-				},
-			},
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("nethermind-p2pnet-service"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("nethermind-p2pnet-service"),
-					"app.kubernetes.io/part-of": pulumi.String("nethermind"),
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
+	// script permissions
+	scriptPerms, err := remote.NewCommand(ctx, fmt.Sprintf("scriptPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chmod +x /data/scripts/start_%s.sh", args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{startScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting script permissions", nil)
+		return err
+	}
 
-		// Create a service for internal ports
-		_, err = corev1.NewService(ctx, "nethermind-internal-service", &corev1.ServiceArgs{
-			Spec: &corev1.ServiceSpecArgs{
-				Selector: pulumi.StringMap{"app": pulumi.String("nethermind")},
-				Type:     pulumi.String("ClusterIP"),
-				Ports: corev1.ServicePortArray{
-					corev1.ServicePortArgs{
-						Port: pulumi.Int(9001),
-						Name: pulumi.String("metrics"),
-					},
-					corev1.ServicePortArgs{
-						Port: pulumi.Int(8551),
-						Name: pulumi.String("p2p"),
-					},
-				},
-			},
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("nethermind-internal-service"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("nethermind-internal-service"),
-					"app.kubernetes.io/part-of": pulumi.String("nethermind"),
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
+	// write the shared engine api jwt
+	jwtSecret, err := sourceJwtSecret(ctx, component, args)
+	if err != nil {
+		return err
+	}
 
-		// Create ingress for the nethermind rpc traffic on port 8545
-		_, err = corev1.NewService(ctx, "nethermind-rpc-service", &corev1.ServiceArgs{
-			Spec: &corev1.ServiceSpecArgs{
-				Selector: pulumi.StringMap{"app": pulumi.String("nethermind")},
-				Type:     pulumi.String("NodePort"),
-				Ports: corev1.ServicePortArray{
-					corev1.ServicePortArgs{
-						Port:       pulumi.Int(8545),
-						TargetPort: pulumi.Int(8545),
-					},
-				},
-			},
-			Metadata: &metav1.ObjectMetaArgs{
-				Name: pulumi.String("nethermind-rpc-service"),
-				Labels: pulumi.StringMap{
-					"app.kubernetes.io/name":    pulumi.String("nethermind"),
-					"app.kubernetes.io/part-of": pulumi.String("nethermind"),
-				},
-			},
-		}, pulumi.Parent(component))
-		if err != nil {
-			return nil, err
-		}
-		return component, nil
+	// create service
+	serviceDefinition, err := utils.NewServiceDefinitionComponent(ctx, fmt.Sprintf("executionService-%s", resourcePrefix(args)), &utils.ServiceComponentArgs{
+		Connection:  args.Connection,
+		ServiceType: args.Client,
+		Network:     args.Network,
+	}, pulumi.Parent(component), pulumi.DependsOn(append([]pulumi.Resource{buildClient, scriptPerms}, jwtSecret...)))
+	if err != nil {
+		ctx.Log.Error("Error creating execution service", nil)
+		return err
+	}
 
+	// group permissions
+	_, err = remote.NewCommand(ctx, fmt.Sprintf("setDataDirGroupPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chown -R %s:%s %s && chown %s:%s /data/scripts/start_%s.sh", args.Client, args.Client, args.DataDir, args.Client, args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{serviceDefinition, scriptPerms, startScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting group permissions", nil)
+		return err
 	}
 
-	return component, nil
+	return setSourceEndpoints(ctx, component, nethermindDriver{}.DefaultPorts(), args)
 }
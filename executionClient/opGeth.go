@@ -0,0 +1,290 @@
+package executionClient
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+func init() {
+	RegisterExecutionClient(opGethDriver{})
+}
+
+// opGethDriver is the built-in ExecutionClientDriver for op-geth.
+type opGethDriver struct{}
+
+func (opGethDriver) Client() string { return OpGeth }
+
+func (opGethDriver) BuildHooks() map[string]ExecutionClientBuildHook {
+	return map[string]ExecutionClientBuildHook{
+		Source:     opGethSource,
+		Docker:     dockerHook(opGethDriver{}, opGethContainer),
+		Kubernetes: kubernetesHook(opGethDriver{}, opGethContainer),
+	}
+}
+
+// opGethContainer is where the op-geth container mounts its volumes in kubernetes and docker.
+var opGethContainer = containerSpec{
+	configDir: "/etc/op-geth",
+	dataDir:   "/data",
+	jwtDir:    "/etc/op-geth/execution-jwt",
+	args:      opGethContainerArgs,
+	// a custom genesis is added to the config map and written to the data
+	// volume by an init container once, later restarts keep the existing chain
+	configFiles: func(args *ExecutionClientComponentArgs) map[string]string {
+		if args.OpGenesisPath == "" {
+			return nil
+		}
+		return map[string]string{"genesis.json": args.OpGenesisPath}
+	},
+	initCommand: func(args *ExecutionClientComponentArgs, spec containerSpec) string {
+		if args.OpGenesisPath == "" {
+			return ""
+		}
+		return fmt.Sprintf("[ -d %s/geth/chaindata ] || geth init --state.scheme=path --datadir=%s %s", spec.dataDir, spec.dataDir, path.Join(spec.configDir, "genesis.json"))
+	},
+}
+
+func (opGethDriver) DefaultPorts() ExecutionClientPorts {
+	return ExecutionClientPorts{P2P: 30303, Metrics: 6060, Http: 8545, Ws: 8546, AuthRpc: 8551}
+}
+
+// opGethSourceRepo is the repository op-geth is built from unless SourceRepoUrl and SourceRef are set.
+var opGethSourceRepo = utils.SourceRepo{Url: "https://github.com/ethereum-optimism/op-geth.git", Ref: "optimism"}
+
+func (opGethDriver) DefaultImage() string {
+	return "us-docker.pkg.dev/oplabs-tools-artifacts/images/op-geth:latest"
+}
+
+func (opGethDriver) ConfigFileName() string { return "op-geth.toml" }
+
+// opStackRegistryNetworks maps the OP Stack networks to their names in the
+// superchain registry, op-geth loads the genesis and rollup config from it.
+var opStackRegistryNetworks = map[string]string{
+	Base:            "base-mainnet",
+	BaseSepolia:     "base-sepolia",
+	Optimism:        "op-mainnet",
+	OptimismSepolia: "op-sepolia",
+}
+
+// opStackSequencers holds the public sequencer endpoints op-geth forwards
+// transactions to unless SequencerHttp is set.
+var opStackSequencers = map[string]string{
+	Base:            "https://mainnet-sequencer.base.org",
+	BaseSepolia:     "https://sepolia-sequencer.base.org",
+	Optimism:        "https://mainnet-sequencer.optimism.io",
+	OptimismSepolia: "https://sepolia-sequencer.optimism.io",
+}
+
+// NewOpGethComponent creates a new ExecutionClientComponent resource that represents an op-geth
+// client and the necessary infrastructure to run it. op-geth is driven by an op-node, see
+// opNode.NewOpNodeComponent.
+//
+// Example usage:
+//
+//	client, err := executionClient.NewOpGethComponent(ctx, "testOpGethExecutionClient", &executionClient.ExecutionClientComponentArgs{
+//		Connection:     &remote.ConnectionArgs{
+//			User:       cfg.Require("sshUser"), // username for the ssh connection
+//			Host:       cfg.Require("sshHost"), // ip address of the host
+//			PrivateKey: cfg.RequireSecret("sshPrivateKey"), // must be a secret, RequireSecret is critical for security
+//		},
+//		Client:         "op-geth", // must be "op-geth"
+//		Network:        "optimism", // base, base-sepolia, optimism or optimism-sepolia
+//		DeploymentType: "source", // source or kubernetes
+//		DataDir:        "/data/optimism/op-geth", // path to the data directory
+//		HistoricalRpc:  "http://l2geth:8545", // serves the pre-bedrock history of optimism
+//	})
+func NewOpGethComponent(ctx *pulumi.Context, name string, args *ExecutionClientComponentArgs, opts ...pulumi.ResourceOption) (*ExecutionClientComponent, error) {
+	return newClientComponent(ctx, name, opGethDriver{}, args, opts...)
+}
+
+// opGethFlags returns the op-geth flags for args, keeping the chain in
+// dataDir and reading the engine api jwt from jwtPath. configPath is passed
+// as --config unless it is empty.
+func opGethFlags(args *ExecutionClientComponentArgs, dataDir string, jwtPath string, configPath string) []string {
+	ports := opGethDriver{}.DefaultPorts()
+	sequencer := args.SequencerHttp
+	if sequencer == "" {
+		sequencer = opStackSequencers[args.Network]
+	}
+
+	var flags []string
+	if configPath != "" {
+		flags = append(flags, "--config="+configPath)
+	}
+	// a custom genesis is written to the data dir by geth init, registry
+	// networks bring their genesis and rollup config with them
+	if args.OpGenesisPath == "" {
+		flags = append(flags, "--op-network="+opStackRegistryNetworks[args.Network])
+	}
+	flags = append(flags,
+		"--datadir="+dataDir,
+		"--syncmode=snap",
+		"--gcmode=full",
+		"--state.scheme=path",
+		"--rollup.sequencerhttp="+sequencer,
+		"--rollup.disabletxpoolgossip",
+		"--authrpc.addr=0.0.0.0",
+		fmt.Sprintf("--authrpc.port=%d", ports.AuthRpc),
+		"--authrpc.vhosts=*",
+		"--authrpc.jwtsecret="+jwtPath,
+		"--http",
+		"--http.addr=0.0.0.0",
+		fmt.Sprintf("--http.port=%d", ports.Http),
+		"--http.vhosts=*",
+		"--http.api="+httpApi(args, "web3,debug,eth,txpool,net"),
+		"--ws",
+		"--ws.addr=0.0.0.0",
+		fmt.Sprintf("--ws.port=%d", ports.Ws),
+		"--ws.origins=*",
+		"--metrics",
+		"--metrics.addr=0.0.0.0",
+		fmt.Sprintf("--metrics.port=%d", ports.Metrics),
+		fmt.Sprintf("--port=%d", ports.P2P),
+	)
+	if args.HistoricalRpc != "" {
+		flags = append(flags, "--rollup.historicalrpc="+args.HistoricalRpc)
+	}
+	return flags
+}
+
+// opGethContainerArgs returns the flags the op-geth container runs with, matching its mounts.
+func opGethContainerArgs(args *ExecutionClientComponentArgs, spec containerSpec) []string {
+	return opGethFlags(args, spec.dataDir, path.Join(spec.jwtDir, "jwt.hex"), path.Join(spec.configDir, opGethDriver{}.ConfigFileName()))
+}
+
+// opGethSource builds op-geth from source on the remote host and runs it as a systemd service.
+func opGethSource(ctx *pulumi.Context, component *ExecutionClientComponent, args *ExecutionClientComponentArgs) error {
+	// Execute a sequence of commands on the remote server
+	dataDir, err := remote.NewCommand(ctx, fmt.Sprintf("createDataDir-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("mkdir -p %s", args.DataDir),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error creating data directory", nil)
+		return err
+	}
+
+	// clone repo
+	repo, err := remote.NewCommand(ctx, fmt.Sprintf("cloneRepo-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.String(sourceRepo(args, opGethSourceRepo).CloneCommand(fmt.Sprintf("/data/repos/%s", args.Client))),
+		Update:     pulumi.String(sourceRepo(args, opGethSourceRepo).CheckoutCommand(fmt.Sprintf("/data/repos/%s", args.Client))),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error cloning repo", nil)
+		return err
+	}
+
+	// install go
+	goDeps, err := remote.NewCommand(ctx, fmt.Sprintf("installGo-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.String("sudo apt update && sudo apt install -y golang-go build-essential"),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error installing go", nil)
+		return err
+	}
+
+	// set repo permissions
+	repoPerms, err := remote.NewCommand(ctx, fmt.Sprintf("setRepoPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chown -R %s:%s /data/repos/%s", args.Client, args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{repo}))
+	if err != nil {
+		ctx.Log.Error("Error setting repo permissions", nil)
+		return err
+	}
+
+	// build execution client, op-geth keeps geth's build target and binary name
+	buildClient, err := remote.NewCommand(ctx, fmt.Sprintf("buildExecutionClient-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("cd /data/repos/%s && sudo -u %s make geth && cp build/bin/geth /usr/local/bin/op-geth", args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{goDeps, repoPerms}))
+	if err != nil {
+		ctx.Log.Error("Error building execution client", nil)
+		return err
+	}
+
+	// copy start script
+	startScript, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyStartScript-%s", resourcePrefix(args)), &remote.CopyFileArgs{
+		LocalPath:  pulumi.String("scripts/start_op_geth.sh"),
+		RemotePath: pulumi.String("/data/scripts/start_op_geth.sh"),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error copying start script", nil)
+		return err
+	}
+
+	// script permissions
+	scriptPerms, err := remote.NewCommand(ctx, fmt.Sprintf("scriptPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.String("chmod +x /data/scripts/start_op_geth.sh"),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{startScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting script permissions", nil)
+		return err
+	}
+
+	// write the shared engine api jwt
+	jwtSecret, err := sourceJwtSecret(ctx, component, args)
+	if err != nil {
+		return err
+	}
+	serviceDeps := append([]pulumi.Resource{buildClient, scriptPerms}, jwtSecret...)
+
+	// initialize the chain from a custom genesis before the first start
+	if args.OpGenesisPath != "" {
+		genesisPath := path.Join(args.DataDir, "genesis.json")
+		genesis, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyGenesis-%s", resourcePrefix(args)), &remote.CopyFileArgs{
+			LocalPath:  pulumi.String(args.OpGenesisPath),
+			RemotePath: pulumi.String(genesisPath),
+			Connection: args.Connection,
+		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{dataDir}))
+		if err != nil {
+			ctx.Log.Error("Error copying genesis", nil)
+			return err
+		}
+
+		initChain, err := remote.NewCommand(ctx, fmt.Sprintf("initGenesis-%s", resourcePrefix(args)), &remote.CommandArgs{
+			Create:     pulumi.Sprintf("chown -R %s:%s %s && sudo -u %s /usr/local/bin/op-geth init --state.scheme=path --datadir=%s %s", args.Client, args.Client, args.DataDir, args.Client, args.DataDir, genesisPath),
+			Connection: args.Connection,
+		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{buildClient, genesis}))
+		if err != nil {
+			ctx.Log.Error("Error initializing genesis", nil)
+			return err
+		}
+		serviceDeps = append(serviceDeps, initChain)
+	}
+
+	// create service, the start script reads its flags from the service environment
+	serviceDefinition, err := utils.NewServiceDefinitionComponent(ctx, fmt.Sprintf("executionService-%s", resourcePrefix(args)), &utils.ServiceComponentArgs{
+		Connection:  args.Connection,
+		ServiceType: args.Client,
+		Network:     args.Network,
+		Environment: pulumi.StringMap{
+			"OP_GETH_FLAGS": pulumi.String(strings.Join(opGethFlags(args, args.DataDir, utils.JwtSecretPath, ""), " ")),
+		},
+	}, pulumi.Parent(component), pulumi.DependsOn(serviceDeps))
+	if err != nil {
+		ctx.Log.Error("Error creating execution service", nil)
+		return err
+	}
+
+	// group permissions
+	_, err = remote.NewCommand(ctx, fmt.Sprintf("setDataDirGroupPermissions-%s", resourcePrefix(args)), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chown -R %s:%s %s && chown %s:%s /data/scripts/start_op_geth.sh", args.Client, args.Client, args.DataDir, args.Client, args.Client),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{serviceDefinition, scriptPerms, startScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting group permissions", nil)
+		return err
+	}
+
+	return setSourceEndpoints(ctx, component, opGethDriver{}.DefaultPorts(), args)
+}
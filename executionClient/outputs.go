@@ -0,0 +1,118 @@
+package executionClient
+
+import (
+	"fmt"
+
+	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// adminNodeInfoRequest is the JSON-RPC request used to read a node's enode,
+// clients only serve it with their admin api enabled, which container args
+// do when QueryNodeIdentity is set.
+const adminNodeInfoRequest = `{"jsonrpc":"2.0","method":"admin_nodeInfo","params":[],"id":1}`
+
+// httpApi returns the json-rpc apis a client serves over http, apis followed
+// by admin when QueryNodeIdentity is set.
+func httpApi(args *ExecutionClientComponentArgs, apis string) string {
+	if args.QueryNodeIdentity {
+		return apis + ",admin"
+	}
+	return apis
+}
+
+// initOutputs sets every output of component to an empty value so build hooks
+// only have to populate the endpoints they know about.
+func initOutputs(component *ExecutionClientComponent) {
+	component.HttpRpcUrl = pulumi.String("").ToStringOutput()
+	component.WsRpcUrl = pulumi.String("").ToStringOutput()
+	component.EngineApiUrl = pulumi.String("").ToStringOutput()
+	component.MetricsUrl = pulumi.String("").ToStringOutput()
+	component.P2pAddress = pulumi.String("").ToStringOutput()
+	component.P2pNodePort = pulumi.Int(0).ToIntOutput()
+	component.Enode = pulumi.String("").ToStringOutput()
+}
+
+// copyOutputs copies the outputs of a client component onto its parent.
+func copyOutputs(dst *ExecutionClientComponent, src *ExecutionClientComponent) {
+	dst.HttpRpcUrl = src.HttpRpcUrl
+	dst.WsRpcUrl = src.WsRpcUrl
+	dst.EngineApiUrl = src.EngineApiUrl
+	dst.MetricsUrl = src.MetricsUrl
+	dst.P2pAddress = src.P2pAddress
+	dst.P2pNodePort = src.P2pNodePort
+	dst.Enode = src.Enode
+}
+
+// registerOutputs registers the outputs of component with the engine.
+func registerOutputs(ctx *pulumi.Context, component *ExecutionClientComponent) error {
+	return ctx.RegisterResourceOutputs(component, pulumi.Map{
+		"httpRpcUrl":   component.HttpRpcUrl,
+		"wsRpcUrl":     component.WsRpcUrl,
+		"engineApiUrl": component.EngineApiUrl,
+		"metricsUrl":   component.MetricsUrl,
+		"p2pAddress":   component.P2pAddress,
+		"p2pNodePort":  component.P2pNodePort,
+		"enode":        component.Enode,
+	})
+}
+
+// setSourceEndpoints populates the outputs of a client running directly on the
+// ssh host. The start scripts bind the rpc, engine api and metrics to localhost,
+// so those urls are only reachable on the host itself, the p2p address is
+// reachable on the ssh host's address.
+func setSourceEndpoints(ctx *pulumi.Context, component *ExecutionClientComponent, ports ExecutionClientPorts, args *ExecutionClientComponentArgs) error {
+	component.HttpRpcUrl = pulumi.Sprintf("http://127.0.0.1:%d", ports.Http)
+	component.WsRpcUrl = pulumi.Sprintf("ws://127.0.0.1:%d", ports.Ws)
+	component.EngineApiUrl = pulumi.Sprintf("http://127.0.0.1:%d", ports.AuthRpc)
+	component.MetricsUrl = pulumi.Sprintf("http://127.0.0.1:%d", ports.Metrics)
+	component.P2pAddress = pulumi.Sprintf("%s:%d", args.Connection.Host, ports.P2P)
+	component.P2pNodePort = pulumi.Int(ports.P2P).ToIntOutput()
+
+	if args.QueryNodeIdentity {
+		enode, err := utils.NewNodeIdentityQuery(ctx, fmt.Sprintf("queryEnode-%s", resourcePrefix(args)), &utils.NodeIdentityQueryArgs{
+			Connection: args.Connection,
+			Url:        pulumi.Sprintf("http://127.0.0.1:%d", ports.Http),
+			Request:    adminNodeInfoRequest,
+			Field:      "enode",
+		}, pulumi.Parent(component))
+		if err != nil {
+			return err
+		}
+		component.Enode = enode
+	}
+
+	return nil
+}
+
+// setKubernetesEndpoints populates the outputs of a client running in kubernetes
+// from the in-cluster DNS names of its services, names are the names of the
+// client's kubernetes objects.
+func setKubernetesEndpoints(ctx *pulumi.Context, component *ExecutionClientComponent, names utils.KubernetesNames, ports ExecutionClientPorts, args *ExecutionClientComponentArgs, rpcService *corev1.Service, internalService *corev1.Service, p2pService *corev1.Service) error {
+	rpcHost := utils.ClusterServiceHost(rpcService)
+	internalHost := utils.ClusterServiceHost(internalService)
+	component.HttpRpcUrl = pulumi.Sprintf("http://%s:%d", rpcHost, ports.Http)
+	component.WsRpcUrl = pulumi.Sprintf("ws://%s:%d", rpcHost, ports.Ws)
+	component.EngineApiUrl = pulumi.Sprintf("http://%s:%d", internalHost, ports.AuthRpc)
+	component.MetricsUrl = pulumi.Sprintf("http://%s:%d", internalHost, ports.Metrics)
+	component.P2pAddress = pulumi.Sprintf("%s:%d", utils.ClusterServiceHost(p2pService), ports.P2P)
+	component.P2pNodePort = p2pService.Spec.Ports().Index(pulumi.Int(0)).NodePort().Elem()
+
+	if args.QueryNodeIdentity {
+		enode, err := utils.NewNodeIdentityQuery(ctx, names.Resource("enode-query"), &utils.NodeIdentityQueryArgs{
+			Namespace:  rpcService.Metadata.Namespace().Elem(),
+			Url:        component.HttpRpcUrl,
+			Request:    adminNodeInfoRequest,
+			Field:      "enode",
+			Kubeconfig: args.Kubeconfig,
+			Context:    args.KubeContext,
+		}, pulumi.Parent(component))
+		if err != nil {
+			return err
+		}
+		component.Enode = enode
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package executionClient
+
+import (
+	"fmt"
+
+	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+const (
+	// probePort is where the probe sidecar serves the checks of the client,
+	// clear of the ports of the built-in clients.
+	probePort               = 8099
+	defaultProbeImage       = "busybox:1.36"
+	defaultProbeMaxBlockAge = 120
+)
+
+// probeRpc is the start of the probe scripts, rpc calls a json-rpc method
+// of the client and prints the response without whitespace, respond answers
+// the probe through busybox httpd's cgi interface.
+const probeRpc = `
+rpc() {
+	wget -q -O - -T 5 --header 'Content-Type: application/json' \
+		--post-data "{\"jsonrpc\":\"2.0\",\"id\":1,\"method\":\"$1\",\"params\":$2}" \
+		"http://127.0.0.1:$RPC_PORT" | tr -d ' \t\r\n'
+}
+respond() {
+	printf 'HTTP/1.0 %s\r\nContent-Type: text/plain\r\n\r\n%s\n' "$1" "$2"
+	exit 0
+}
+`
+
+// probeLive answers while the client's json-rpc answers.
+const probeLive = `
+case "$(rpc eth_blockNumber '[]')" in
+*'"result"'*) respond "200 OK" "rpc answers" ;;
+*) respond "503 Service Unavailable" "rpc unavailable" ;;
+esac
+`
+
+// probeReady answers once the client is synced and its head is recent.
+const probeReady = `
+syncing=$(rpc eth_syncing '[]')
+case "$syncing" in
+*'"result":false'*) ;;
+*'"result"'*) respond "503 Service Unavailable" "syncing" ;;
+*) respond "503 Service Unavailable" "rpc unavailable" ;;
+esac
+timestamp=$(rpc eth_getBlockByNumber '["latest",false]' | sed -n 's/.*"timestamp":"\(0x[0-9a-fA-F]*\)".*/\1/p')
+[ -n "$timestamp" ] || respond "503 Service Unavailable" "no head block"
+age=$(( $(date +%s) - $(printf '%d' "$timestamp") ))
+[ "$age" -le "$MAX_BLOCK_AGE" ] || respond "503 Service Unavailable" "head is ${age}s old"
+respond "200 OK" "head is ${age}s old"
+`
+
+// newProbeSidecar creates the config map with the probe scripts of the
+// client and returns the busybox httpd sidecar serving them on probePort as
+// /cgi-bin/live and /cgi-bin/ready, and the volume of the scripts.
+func newProbeSidecar(ctx *pulumi.Context, component *ExecutionClientComponent, names utils.KubernetesNames, ports ExecutionClientPorts, args *ExecutionClientComponentArgs) (corev1.ContainerArgs, corev1.VolumeArgs, error) {
+	maxBlockAge := args.ProbeMaxBlockAge
+	if maxBlockAge == 0 {
+		maxBlockAge = defaultProbeMaxBlockAge
+	}
+	header := fmt.Sprintf("#!/bin/sh\nRPC_PORT=%d\nMAX_BLOCK_AGE=%d\n", ports.Http, maxBlockAge)
+	configMap, err := corev1.NewConfigMap(ctx, names.Resource("probe"), &corev1.ConfigMapArgs{
+		Data: pulumi.StringMap{
+			"live":  pulumi.String(header + probeRpc + probeLive),
+			"ready": pulumi.String(header + probeRpc + probeReady),
+		},
+		Metadata: names.Metadata("probe"),
+	}, pulumi.Parent(component))
+	if err != nil {
+		return corev1.ContainerArgs{}, corev1.VolumeArgs{}, err
+	}
+
+	image := args.ProbeImage
+	if image == "" {
+		image = defaultProbeImage
+	}
+	sidecar := corev1.ContainerArgs{
+		Name:    pulumi.String(names.Object("probe")),
+		Image:   pulumi.String(image),
+		Command: pulumi.ToStringArray([]string{"httpd", "-f", "-p", fmt.Sprint(probePort), "-h", "/www"}),
+		Ports: corev1.ContainerPortArray{
+			corev1.ContainerPortArgs{
+				ContainerPort: pulumi.Int(probePort),
+			},
+		},
+		VolumeMounts: corev1.VolumeMountArray{
+			corev1.VolumeMountArgs{
+				Name:      pulumi.String("probe"),
+				MountPath: pulumi.String("/www/cgi-bin"),
+			},
+		},
+		Resources: &corev1.ResourceRequirementsArgs{
+			Limits: pulumi.StringMap{
+				"cpu":    pulumi.String("100m"),
+				"memory": pulumi.String("32Mi"),
+			},
+			Requests: pulumi.StringMap{
+				"cpu":    pulumi.String("10m"),
+				"memory": pulumi.String("16Mi"),
+			},
+		},
+	}
+	volume := corev1.VolumeArgs{
+		Name: pulumi.String("probe"),
+		ConfigMap: &corev1.ConfigMapVolumeSourceArgs{
+			Name:        configMap.Metadata.Name(),
+			DefaultMode: pulumi.Int(0o755),
+		},
+	}
+	return sidecar, volume, nil
+}
@@ -0,0 +1,208 @@
+package node_deployer
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/consensusClient"
+	"github.com/rswanson/node_deployer/executionClient"
+)
+
+// EthereumNodeTemplate describes the replicas created by EthereumNodeFactory.
+// Every replica gets its own copy of the client args, so replicas never share
+// names, connections or providers by accident.
+type EthereumNodeTemplate struct {
+	EthereumNodeArgs
+	// NamePattern is a fmt pattern that receives the replica index and names
+	// the replica's node, e.g. "holesky-%02d". Defaults to "<name>-%d".
+	NamePattern string
+	// Connections holds the ssh connection of every replica, replica i is
+	// deployed over Connections[i]. When empty the connection in the client
+	// args is used.
+	Connections []*remote.ConnectionArgs
+	// KubernetesProviders holds the kubernetes provider of every replica,
+	// replica i is deployed with KubernetesProviders[i]. When empty the
+	// default provider is used.
+	KubernetesProviders []pulumi.ProviderResource
+	// Overrides holds per replica settings keyed by replica index.
+	Overrides map[int]*EthereumNodeOverrides
+}
+
+// EthereumNodeOverrides holds settings that differ between replicas.
+type EthereumNodeOverrides struct {
+	// PodStorageClass and PodStorageSize apply to both clients.
+	PodStorageClass string
+	PodStorageSize  string
+	// ExecutionSnapshotName replaces RethSnapshotName of the execution client args.
+	ExecutionSnapshotName string
+	// ConsensusSnapshotName replaces SnapshotName of the consensus client args.
+	ConsensusSnapshotName string
+}
+
+// NodeFleet holds the nodes created by EthereumNodeFactory along with their
+// outputs, indexed by replica.
+type NodeFleet struct {
+	Nodes []*EthereumNode
+
+	HttpRpcUrls   pulumi.StringArrayOutput
+	WsRpcUrls     pulumi.StringArrayOutput
+	EngineApiUrls pulumi.StringArrayOutput
+	Enodes        pulumi.StringArrayOutput
+	// BeaconApiUrls and Enrs are empty for nodes without a consensus client.
+	BeaconApiUrls pulumi.StringArrayOutput
+	Enrs          pulumi.StringArrayOutput
+}
+
+// EthereumNodeFactory creates template.Replicas nodes from template. Replica i
+// is named after template.NamePattern and its clients get the template's
+// client names suffixed with "-i", as well as the i-th connection, kubernetes
+// provider and overrides of the template. The template is not modified.
+//
+// Example usage:
+//
+//	fleet, err := node_deployer.EthereumNodeFactory(ctx, "holesky", &node_deployer.EthereumNodeTemplate{
+//		EthereumNodeArgs: node_deployer.EthereumNodeArgs{
+//			ExecutionClientArgs: executionClientArgs,
+//			ConsensusClientArgs: consensusClientArgs,
+//			Replicas:            2,
+//		},
+//		KubernetesProviders: []pulumi.ProviderResource{clusterA, clusterB},
+//		Overrides: map[int]*node_deployer.EthereumNodeOverrides{
+//			1: {PodStorageClass: "fast-ssd"},
+//		},
+//	})
+//	ctx.Export("rpcUrls", fleet.HttpRpcUrls)
+func EthereumNodeFactory(ctx *pulumi.Context, name string, template *EthereumNodeTemplate, opts ...pulumi.ResourceOption) (*NodeFleet, error) {
+	if template == nil {
+		template = &EthereumNodeTemplate{}
+	}
+	if len(template.Connections) > 0 && len(template.Connections) < template.Replicas {
+		return nil, fmt.Errorf("template has %d connections for %d replicas", len(template.Connections), template.Replicas)
+	}
+	if len(template.KubernetesProviders) > 0 && len(template.KubernetesProviders) < template.Replicas {
+		return nil, fmt.Errorf("template has %d kubernetes providers for %d replicas", len(template.KubernetesProviders), template.Replicas)
+	}
+
+	namePattern := template.NamePattern
+	if namePattern == "" {
+		namePattern = name + "-%d"
+	}
+
+	fleet := &NodeFleet{}
+	var httpRpcUrls, wsRpcUrls, engineApiUrls, enodes, beaconApiUrls, enrs pulumi.StringArray
+	for i := 0; i < template.Replicas; i++ {
+		args := template.replicaArgs(i)
+
+		replicaOpts := append([]pulumi.ResourceOption{}, opts...)
+		if len(template.KubernetesProviders) > 0 {
+			replicaOpts = append(replicaOpts, pulumi.Providers(template.KubernetesProviders[i]))
+		}
+
+		ethereumNode, err := NewEthereumNode(ctx, fmt.Sprintf(namePattern, i), args, replicaOpts...)
+		if err != nil {
+			ctx.Log.Error(fmt.Sprintf("Error creating replica %d", i), nil)
+			return nil, err
+		}
+		fleet.Nodes = append(fleet.Nodes, ethereumNode)
+
+		httpRpcUrls = append(httpRpcUrls, ethereumNode.ExecutionClient.HttpRpcUrl)
+		wsRpcUrls = append(wsRpcUrls, ethereumNode.ExecutionClient.WsRpcUrl)
+		engineApiUrls = append(engineApiUrls, ethereumNode.ExecutionClient.EngineApiUrl)
+		enodes = append(enodes, ethereumNode.ExecutionClient.Enode)
+		if ethereumNode.ConsensusClient != nil {
+			beaconApiUrls = append(beaconApiUrls, ethereumNode.ConsensusClient.BeaconApiUrl)
+			enrs = append(enrs, ethereumNode.ConsensusClient.Enr)
+		} else {
+			beaconApiUrls = append(beaconApiUrls, pulumi.String(""))
+			enrs = append(enrs, pulumi.String(""))
+		}
+	}
+
+	fleet.HttpRpcUrls = httpRpcUrls.ToStringArrayOutput()
+	fleet.WsRpcUrls = wsRpcUrls.ToStringArrayOutput()
+	fleet.EngineApiUrls = engineApiUrls.ToStringArrayOutput()
+	fleet.Enodes = enodes.ToStringArrayOutput()
+	fleet.BeaconApiUrls = beaconApiUrls.ToStringArrayOutput()
+	fleet.Enrs = enrs.ToStringArrayOutput()
+
+	return fleet, nil
+}
+
+// replicaArgs builds fresh args for replica i from the template.
+func (template *EthereumNodeTemplate) replicaArgs(i int) *EthereumNodeArgs {
+	executionClientArgs := &executionClient.ExecutionClientComponentArgs{}
+	if template.ExecutionClientArgs != nil {
+		*executionClientArgs = *template.ExecutionClientArgs
+	}
+	consensusClientArgs := &consensusClient.ConsensusClientComponentArgs{}
+	if template.ConsensusClientArgs != nil {
+		*consensusClientArgs = *template.ConsensusClientArgs
+	}
+
+	executionClientArgs.Name = replicaName(executionClientArgs.Name, executionClientArgs.Client, i)
+	consensusClientArgs.Name = replicaName(consensusClientArgs.Name, consensusClientArgs.Client, i)
+
+	if len(template.Connections) > 0 {
+		executionClientArgs.Connection = template.Connections[i]
+		consensusClientArgs.Connection = template.Connections[i]
+	}
+
+	if overrides, ok := template.Overrides[i]; ok && overrides != nil {
+		if overrides.PodStorageClass != "" {
+			executionClientArgs.PodStorageClass = overrides.PodStorageClass
+			consensusClientArgs.PodStorageClass = overrides.PodStorageClass
+		}
+		if overrides.PodStorageSize != "" {
+			executionClientArgs.PodStorageSize = overrides.PodStorageSize
+			consensusClientArgs.PodStorageSize = overrides.PodStorageSize
+		}
+		if overrides.ExecutionSnapshotName != "" {
+			executionClientArgs.RethSnapshotName = overrides.ExecutionSnapshotName
+		}
+		if overrides.ConsensusSnapshotName != "" {
+			consensusClientArgs.SnapshotName = overrides.ConsensusSnapshotName
+		}
+	}
+
+	args := &EthereumNodeArgs{
+		ExecutionClientArgs: executionClientArgs,
+		ConsensusClientArgs: consensusClientArgs,
+		ExecutionJwt:        template.ExecutionJwt,
+		RenderManifestsTo:   template.RenderManifestsTo,
+		RenderSecrets:       template.RenderSecrets,
+		SealedSecretsCert:   template.SealedSecretsCert,
+		Namespace:           template.Namespace,
+	}
+	if template.MevBoostArgs != nil {
+		mevBoostArgs := *template.MevBoostArgs
+		mevBoostArgs.Name = replicaName(mevBoostArgs.Name, "mev-boost", i)
+		if len(template.Connections) > 0 {
+			mevBoostArgs.Connection = template.Connections[i]
+		}
+		args.MevBoostArgs = &mevBoostArgs
+	}
+	if template.OpNodeArgs != nil {
+		opNodeArgs := *template.OpNodeArgs
+		opNodeArgs.Name = replicaName(opNodeArgs.Name, "op-node", i)
+		if len(template.Connections) > 0 {
+			opNodeArgs.Connection = template.Connections[i]
+		}
+		args.OpNodeArgs = &opNodeArgs
+	}
+	// keep the consensus client omitted for Caplin and L2 nodes
+	if template.ConsensusClientArgs == nil && (executionClientArgs.EnableCaplin || template.OpNodeArgs != nil) {
+		args.ConsensusClientArgs = nil
+	}
+	return args
+}
+
+// replicaName suffixes the template name, or the client when the template has
+// no name, with the replica index.
+func replicaName(name string, client string, i int) string {
+	if name == "" {
+		name = client
+	}
+	return name + "-" + strconv.Itoa(i)
+}
@@ -0,0 +1,113 @@
+// Package testutil holds the pulumi mocks and the helpers reading recorded
+// resource inputs that the component tests share.
+package testutil
+
+import (
+	"sync"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// Mocks creates every resource with its inputs as outputs.
+type Mocks int
+
+func (Mocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	return args.Name + "_id", args.Inputs, nil
+}
+
+func (Mocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	return args.Args, nil
+}
+
+// RecordingMocks records the inputs of every resource by name.
+type RecordingMocks struct {
+	Mocks
+	mu     sync.Mutex
+	Inputs map[string]resource.PropertyMap
+}
+
+// NewRecordingMocks returns mocks that haven't recorded any resource yet.
+func NewRecordingMocks() *RecordingMocks {
+	return &RecordingMocks{Inputs: map[string]resource.PropertyMap{}}
+}
+
+func (m *RecordingMocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Inputs[args.Name] = args.Inputs
+	return args.Name + "_id", args.Inputs, nil
+}
+
+// UnwrapSecret returns the plain value of a secret property.
+func UnwrapSecret(value resource.PropertyValue) resource.PropertyValue {
+	if value.IsSecret() {
+		return value.SecretValue().Element
+	}
+	return value
+}
+
+// StringValues returns the elements of an array property as strings.
+func StringValues(value resource.PropertyValue) []string {
+	var values []string
+	for _, element := range value.ArrayValue() {
+		values = append(values, element.StringValue())
+	}
+	return values
+}
+
+// PodSpec returns the pod template spec of a StatefulSet or Deployment.
+func PodSpec(workload resource.PropertyMap) resource.PropertyMap {
+	return workload["spec"].ObjectValue()["template"].ObjectValue()["spec"].ObjectValue()
+}
+
+// Containers returns the containers of a pod spec.
+func Containers(podSpec resource.PropertyMap) []resource.PropertyMap {
+	return objects(podSpec["containers"])
+}
+
+// InitContainers returns the init containers of a pod spec, nil without any.
+func InitContainers(podSpec resource.PropertyMap) []resource.PropertyMap {
+	return objects(podSpec["initContainers"])
+}
+
+// Names returns the names of containers, volumes or ports.
+func Names(objects []resource.PropertyMap) []string {
+	var names []string
+	for _, object := range objects {
+		names = append(names, object["name"].StringValue())
+	}
+	return names
+}
+
+// Flags returns the arguments of a container.
+func Flags(container resource.PropertyMap) []string {
+	return StringValues(container["args"])
+}
+
+// VolumeMounts returns the volume mounts of a container.
+func VolumeMounts(container resource.PropertyMap) []resource.PropertyMap {
+	return objects(container["volumeMounts"])
+}
+
+// MountPaths returns where a container mounts its volumes, in order.
+func MountPaths(container resource.PropertyMap) []string {
+	var paths []string
+	for _, mount := range VolumeMounts(container) {
+		paths = append(paths, mount["mountPath"].StringValue())
+	}
+	return paths
+}
+
+// objects returns the elements of an array property of objects, nil when the
+// property isn't set.
+func objects(value resource.PropertyValue) []resource.PropertyMap {
+	if !value.IsArray() {
+		return nil
+	}
+	var elements []resource.PropertyMap
+	for _, element := range value.ArrayValue() {
+		elements = append(elements, element.ObjectValue())
+	}
+	return elements
+}
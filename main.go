@@ -1,37 +1,200 @@
 package node_deployer
 
 import (
-	"strconv"
+	"fmt"
+	"path"
+	"slices"
 
+	"github.com/pulumi/pulumi-random/sdk/v4/go/random"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 	"github.com/rswanson/node_deployer/consensusClient"
 	"github.com/rswanson/node_deployer/executionClient"
+	"github.com/rswanson/node_deployer/mevBoost"
+	"github.com/rswanson/node_deployer/opNode"
 )
 
 type EthereumNode struct {
 	ExecutionClient *executionClient.ExecutionClientComponent
+	// ConsensusClient is nil for nodes running erigon's built-in Caplin consensus client.
 	ConsensusClient *consensusClient.ConsensusClientComponent
+	// ExecutionJwt is the engine api jwt shared by both clients.
+	ExecutionJwt pulumi.StringOutput
+	// MevBoost is nil unless EthereumNodeArgs.MevBoostArgs is set.
+	MevBoost *mevBoost.MevBoostComponent
+	// OpNode is the rollup node of L2 nodes, nil unless EthereumNodeArgs.OpNodeArgs is set.
+	OpNode *opNode.OpNodeComponent
 	pulumi.ResourceState
 }
 
 type EthereumNodeArgs struct {
 	ExecutionClientArgs *executionClient.ExecutionClientComponentArgs
+	// ConsensusClientArgs may be nil when the execution client args set EnableCaplin.
 	ConsensusClientArgs *consensusClient.ConsensusClientComponentArgs
-	Replicas            int
+	// ExecutionJwt is the engine api jwt injected into both clients. When nil the
+	// jwt of either client's args is used, and when neither sets one a new
+	// secret is generated.
+	ExecutionJwt pulumi.StringInput
+	// Replicas is the number of nodes EthereumNodeFactory creates from these args.
+	Replicas int
+	// MevBoostArgs adds a mev-boost sidecar whose Url becomes the consensus
+	// client's BuilderEndpoint unless the consensus client args set one.
+	MevBoostArgs *mevBoost.MevBoostComponentArgs
+	// OpNodeArgs turns the node into an OP Stack L2 node whose op-node drives
+	// the execution client, e.g. op-reth on base or op-geth, in place of a
+	// consensus client. The execution client's engine api becomes its
+	// L2EngineUrl unless the op-node args set one.
+	OpNodeArgs *opNode.OpNodeComponentArgs
+	// RenderManifestsTo writes the kubernetes objects of both clients as YAML
+	// to <RenderManifestsTo>/<name>/<client> instead of applying them, with
+	// secrets written as RenderSecrets describes, see the client args. Client
+	// args setting their own RenderManifestsTo keep it. Rendering does not
+	// cover mev-boost and op-node, which have to run elsewhere.
+	RenderManifestsTo string
+	RenderSecrets     string
+	SealedSecretsCert string
+	// Namespace is the kubernetes namespace of the clients deployed to
+	// kubernetes unless their args set one.
+	Namespace string
 }
 
+// NewEthereumNode creates an execution client and a consensus client connected to it.
+// Both clients get the same engine api jwt, the consensus client's execution endpoint
+// is taken from the execution client's EngineApiUrl output (localhost when both are
+// built from source on the same host) and the consensus client is only created once
+// the execution client exists. Nodes running erigon with EnableCaplin can omit the
+// consensus client args. With MevBoostArgs a mev-boost sidecar is created before
+// the consensus client and used as its builder. L2 nodes set OpNodeArgs instead
+// of consensus client args and get an op-node sharing the jwt. With
+// RenderManifestsTo the kubernetes objects of both clients are written to
+// <RenderManifestsTo>/<name>/<client> for a GitOps repository instead of being
+// applied. The client args passed in are not modified.
+//
+// Example usage:
+//
+//	node, err := node_deployer.NewEthereumNode(ctx, "holesky", &node_deployer.EthereumNodeArgs{
+//		ExecutionClientArgs: &executionClient.ExecutionClientComponentArgs{
+//			Client:         "reth",
+//			Network:        "holesky",
+//			DeploymentType: "kubernetes",
+//			...
+//		},
+//		ConsensusClientArgs: &consensusClient.ConsensusClientComponentArgs{
+//			Client:         "lighthouse",
+//			Network:        "holesky",
+//			DeploymentType: "kubernetes",
+//			...
+//		},
+//	})
 func NewEthereumNode(ctx *pulumi.Context, name string, args *EthereumNodeArgs, opts ...pulumi.ResourceOption) (*EthereumNode, error) {
 	if args == nil {
 		args = &EthereumNodeArgs{}
 	}
 
-	executionClient, err := executionClient.NewExecutionClientComponent(ctx, name+"-executionClient", args.ExecutionClientArgs, opts...)
+	// copy the client args so the jwt and endpoint can be set without touching the caller's args
+	executionClientArgs := &executionClient.ExecutionClientComponentArgs{}
+	if args.ExecutionClientArgs != nil {
+		*executionClientArgs = *args.ExecutionClientArgs
+	}
+	consensusClientArgs := &consensusClient.ConsensusClientComponentArgs{}
+	if args.ConsensusClientArgs != nil {
+		*consensusClientArgs = *args.ConsensusClientArgs
+	}
+
+	if args.OpNodeArgs != nil && args.ConsensusClientArgs != nil {
+		return nil, fmt.Errorf("node %s sets both ConsensusClientArgs and OpNodeArgs", name)
+	}
+	if args.Namespace != "" {
+		if executionClientArgs.Namespace == "" && executionClientArgs.DeploymentType == executionClient.Kubernetes {
+			executionClientArgs.Namespace = args.Namespace
+		}
+		if consensusClientArgs.Namespace == "" && consensusClientArgs.DeploymentType == consensusClient.Kubernetes {
+			consensusClientArgs.Namespace = args.Namespace
+		}
+	}
+	if args.RenderManifestsTo != "" {
+		if (args.MevBoostArgs != nil && args.MevBoostArgs.DeploymentType == mevBoost.Kubernetes) || (args.OpNodeArgs != nil && args.OpNodeArgs.DeploymentType == opNode.Kubernetes) {
+			return nil, fmt.Errorf("node %s renders manifests, which is not supported for mev-boost and op-node", name)
+		}
+		dir := path.Join(args.RenderManifestsTo, name)
+		if executionClientArgs.RenderManifestsTo == "" {
+			executionClientArgs.RenderManifestsTo = dir
+			executionClientArgs.RenderSecrets = args.RenderSecrets
+			executionClientArgs.SealedSecretsCert = args.SealedSecretsCert
+		}
+		if consensusClientArgs.RenderManifestsTo == "" {
+			consensusClientArgs.RenderManifestsTo = dir
+			consensusClientArgs.RenderSecrets = args.RenderSecrets
+			consensusClientArgs.SealedSecretsCert = args.SealedSecretsCert
+		}
+	}
+
+	jwt, err := executionJwt(ctx, name, args, opts...)
+	if err != nil {
+		ctx.Log.Error("Error generating execution jwt", nil)
+		return nil, err
+	}
+	executionClientArgs.ExecutionJwt = jwt
+	consensusClientArgs.ExecutionJwt = jwt
+
+	executionClient, err := executionClient.NewExecutionClientComponent(ctx, name+"-executionClient", executionClientArgs, opts...)
 	if err != nil {
 		ctx.Log.Error("Error creating execution client", nil)
 		return nil, err
 	}
 
-	consensusClient, err := consensusClient.NewConsensusClientComponent(ctx, name+"-consensusClient", args.ConsensusClientArgs, opts...)
+	// the rollup node drives the execution client of L2 nodes
+	if args.OpNodeArgs != nil {
+		opNodeArgs := *args.OpNodeArgs
+		opNodeArgs.ExecutionJwt = jwt
+		if opNodeArgs.L2EngineUrl == nil {
+			opNodeArgs.L2EngineUrl = l2EngineUrl(executionClient, executionClientArgs, &opNodeArgs)
+		}
+		if opNodeArgs.L2EngineKind == "" {
+			opNodeArgs.L2EngineKind = l2EngineKind(executionClientArgs)
+		}
+		opNodeOpts := append([]pulumi.ResourceOption{}, opts...)
+		opNodeOpts = append(opNodeOpts, pulumi.DependsOn([]pulumi.Resource{executionClient}))
+		rollupNode, err := opNode.NewOpNodeComponent(ctx, name+"-opNode", &opNodeArgs, opNodeOpts...)
+		if err != nil {
+			ctx.Log.Error("Error creating op-node", nil)
+			return nil, err
+		}
+		return &EthereumNode{
+			ExecutionClient: executionClient,
+			ExecutionJwt:    jwt,
+			OpNode:          rollupNode,
+		}, nil
+	}
+
+	// erigon's built-in Caplin consensus client replaces a separate one
+	if args.ConsensusClientArgs == nil && executionClientArgs.EnableCaplin {
+		return &EthereumNode{
+			ExecutionClient: executionClient,
+			ExecutionJwt:    jwt,
+		}, nil
+	}
+
+	if consensusClientArgs.ExecutionEndpoint == nil {
+		consensusClientArgs.ExecutionEndpoint = executionEndpoint(executionClient, executionClientArgs, consensusClientArgs)
+	}
+	consensusClientDeps := []pulumi.Resource{executionClient}
+
+	var boost *mevBoost.MevBoostComponent
+	if args.MevBoostArgs != nil {
+		boost, err = mevBoost.NewMevBoostComponent(ctx, name+"-mevBoost", args.MevBoostArgs, opts...)
+		if err != nil {
+			ctx.Log.Error("Error creating mev-boost", nil)
+			return nil, err
+		}
+		if consensusClientArgs.BuilderEndpoint == nil {
+			consensusClientArgs.BuilderEndpoint = builderEndpoint(boost, args.MevBoostArgs, consensusClientArgs)
+		}
+		consensusClientDeps = append(consensusClientDeps, boost)
+	}
+
+	consensusClientOpts := append([]pulumi.ResourceOption{}, opts...)
+	consensusClientOpts = append(consensusClientOpts, pulumi.DependsOn(consensusClientDeps))
+	consensusClient, err := consensusClient.NewConsensusClientComponent(ctx, name+"-consensusClient", consensusClientArgs, consensusClientOpts...)
 	if err != nil {
 		ctx.Log.Error("Error creating consensus client", nil)
 		return nil, err
@@ -40,20 +203,90 @@ func NewEthereumNode(ctx *pulumi.Context, name string, args *EthereumNodeArgs, o
 	return &EthereumNode{
 		ExecutionClient: executionClient,
 		ConsensusClient: consensusClient,
+		ExecutionJwt:    jwt,
+		MevBoost:        boost,
 	}, nil
 }
 
-func EthereumNodeFactory(ctx *pulumi.Context, name string, args *EthereumNodeArgs, opts ...pulumi.ResourceOption) ([]*EthereumNode, error) {
-	// loop to create a number of EthereumNodes based on replicas
-	ethereumNodes := make([]*EthereumNode, 0)
-	for i := 0; i < args.Replicas; i++ {
-		args.ExecutionClientArgs.Name = args.ExecutionClientArgs.Name + "-" + strconv.Itoa(i)
-		args.ConsensusClientArgs.Name = args.ConsensusClientArgs.Name + "-" + strconv.Itoa(i)
-		ethereumNode, err := NewEthereumNode(ctx, name+"-"+strconv.Itoa(i), args, opts...)
-		if err != nil {
-			return nil, err
+// executionJwt returns the jwt shared by the node's clients, generating a new
+// 32 byte hex secret when none was provided. The generated secret is kept in
+// the stack state so it stays the same across updates.
+func executionJwt(ctx *pulumi.Context, name string, args *EthereumNodeArgs, opts ...pulumi.ResourceOption) (pulumi.StringOutput, error) {
+	switch {
+	case args.ExecutionJwt != nil:
+		return pulumi.ToSecret(args.ExecutionJwt).(pulumi.StringOutput), nil
+	case args.ExecutionClientArgs != nil && args.ExecutionClientArgs.ExecutionJwt != nil:
+		return pulumi.ToSecret(args.ExecutionClientArgs.ExecutionJwt).(pulumi.StringOutput), nil
+	case args.ConsensusClientArgs != nil && args.ConsensusClientArgs.ExecutionJwt != nil:
+		return pulumi.ToSecret(args.ConsensusClientArgs.ExecutionJwt).(pulumi.StringOutput), nil
+	}
+
+	// 64 hex digits, the hex letters are passed as the special characters
+	jwtOpts := append([]pulumi.ResourceOption{}, opts...)
+	jwtOpts = append(jwtOpts, pulumi.AdditionalSecretOutputs([]string{"result"}))
+	jwt, err := random.NewRandomPassword(ctx, fmt.Sprintf("%s-executionJwt", name), &random.RandomPasswordArgs{
+		Length:          pulumi.Int(64),
+		Upper:           pulumi.Bool(false),
+		Lower:           pulumi.Bool(false),
+		Number:          pulumi.Bool(true),
+		Special:         pulumi.Bool(true),
+		OverrideSpecial: pulumi.String("abcdef"),
+	}, jwtOpts...)
+	if err != nil {
+		return pulumi.StringOutput{}, err
+	}
+	return pulumi.ToSecret(jwt.Result).(pulumi.StringOutput), nil
+}
+
+// executionEndpoint returns the engine api endpoint the consensus client connects to.
+// Source and binary deployments of a node share a host, so such consensus
+// clients use localhost, where docker execution clients publish the engine api
+// as well. Docker consensus clients use the EngineApiUrl output, the execution
+// client's container name on the shared docker network.
+func executionEndpoint(client *executionClient.ExecutionClientComponent, executionClientArgs *executionClient.ExecutionClientComponentArgs, consensusClientArgs *consensusClient.ConsensusClientComponentArgs) pulumi.StringInput {
+	onHost := slices.Contains([]string{executionClient.Source, executionClient.Binary, executionClient.Docker}, executionClientArgs.DeploymentType)
+	if onHost && (consensusClientArgs.DeploymentType == consensusClient.Source || consensusClientArgs.DeploymentType == consensusClient.Binary) {
+		if driver, ok := executionClient.LookupExecutionClient(executionClientArgs.Client); ok {
+			return pulumi.Sprintf("http://127.0.0.1:%d", driver.DefaultPorts().AuthRpc)
 		}
-		ethereumNodes = append(ethereumNodes, ethereumNode)
 	}
-	return ethereumNodes, nil
+	return client.EngineApiUrl
+}
+
+// builderEndpoint returns the mev-boost endpoint the consensus client connects to.
+// Like the engine api, mev-boost built from source shares the host with source
+// and binary deployments of the consensus client, which use localhost.
+func builderEndpoint(boost *mevBoost.MevBoostComponent, mevBoostArgs *mevBoost.MevBoostComponentArgs, consensusClientArgs *consensusClient.ConsensusClientComponentArgs) pulumi.StringInput {
+	if mevBoostArgs.DeploymentType == mevBoost.Source && (consensusClientArgs.DeploymentType == consensusClient.Source || consensusClientArgs.DeploymentType == consensusClient.Binary) {
+		port := mevBoostArgs.Port
+		if port == 0 {
+			port = mevBoost.DefaultPort
+		}
+		return pulumi.Sprintf("http://127.0.0.1:%d", port)
+	}
+	return boost.Url
+}
+
+// l2EngineUrl returns the engine api endpoint op-node connects to. Source
+// deployments share a host, op-reth on base serves its engine api on the port
+// set in scripts/start_base.sh.
+func l2EngineUrl(client *executionClient.ExecutionClientComponent, executionClientArgs *executionClient.ExecutionClientComponentArgs, opNodeArgs *opNode.OpNodeComponentArgs) pulumi.StringInput {
+	if executionClientArgs.DeploymentType == executionClient.Source && opNodeArgs.DeploymentType == opNode.Source {
+		if executionClientArgs.Client == executionClient.Reth && executionClientArgs.Network == executionClient.Base {
+			return pulumi.String("http://127.0.0.1:9551")
+		}
+		if driver, ok := executionClient.LookupExecutionClient(executionClientArgs.Client); ok {
+			return pulumi.Sprintf("http://127.0.0.1:%d", driver.DefaultPorts().AuthRpc)
+		}
+	}
+	return client.EngineApiUrl
+}
+
+// l2EngineKind returns the op-node engine kind of the L2 execution client,
+// empty for op-reth which is op-node's default.
+func l2EngineKind(executionClientArgs *executionClient.ExecutionClientComponentArgs) string {
+	if executionClientArgs.Client == executionClient.OpGeth {
+		return "geth"
+	}
+	return ""
 }
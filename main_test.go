@@ -0,0 +1,635 @@
+package node_deployer
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/consensusClient"
+	"github.com/rswanson/node_deployer/executionClient"
+	"github.com/rswanson/node_deployer/mevBoost"
+	"github.com/rswanson/node_deployer/opNode"
+	"github.com/stretchr/testify/assert"
+)
+
+// mocks records the inputs of every resource by name, and the type and name
+// of every resource in registration order so duplicates can be found.
+type mocks struct {
+	mu        sync.Mutex
+	inputs    map[string]resource.PropertyMap
+	resources []string
+}
+
+func (m *mocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inputs[args.Name] = args.Inputs
+	m.resources = append(m.resources, args.TypeToken+"::"+args.Name)
+	return args.Name + "_id", args.Inputs, nil
+}
+
+func (m *mocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	return args.Args, nil
+}
+
+// unwrapSecret returns the plain value of a secret property.
+func unwrapSecret(value resource.PropertyValue) resource.PropertyValue {
+	if value.IsSecret() {
+		return value.SecretValue().Element
+	}
+	return value
+}
+
+// nodeArgs returns valid args to deploy a reth and lighthouse node to kubernetes.
+func nodeArgs(t *testing.T) *EthereumNodeArgs {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	assert.NoError(t, os.WriteFile(configPath, []byte("[test]"), 0o600))
+
+	return &EthereumNodeArgs{
+		ExecutionClientArgs: &executionClient.ExecutionClientComponentArgs{
+			Client:                    "reth",
+			Network:                   "holesky",
+			DeploymentType:            "kubernetes",
+			ExecutionClientConfigPath: configPath,
+			CpuLimit:                  "1",
+			MemoryLimit:               "1Gi",
+			Name:                      "reth",
+		},
+		ConsensusClientArgs: &consensusClient.ConsensusClientComponentArgs{
+			Client:                    "lighthouse",
+			Network:                   "holesky",
+			DeploymentType:            "kubernetes",
+			ConsensusClientConfigPath: configPath,
+			CpuLimit:                  "1",
+			MemoryLimit:               "1Gi",
+			Name:                      "lighthouse",
+		},
+	}
+}
+
+func TestNewEthereumNode(t *testing.T) {
+	t.Run("SharedJwt", func(t *testing.T) {
+		m := &mocks{inputs: map[string]resource.PropertyMap{}}
+		args := nodeArgs(t)
+		args.ExecutionJwt = pulumi.String("testJwt")
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			_, err := NewEthereumNode(ctx, "testNode", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		for _, secret := range []string{"reth-execution-jwt", "lighthouse-execution-jwt"} {
+			jwt := unwrapSecret(unwrapSecret(m.inputs[secret]["stringData"]).ObjectValue()["jwt.hex"])
+			assert.Equal(t, "testJwt", jwt.StringValue(), "Expected %s to hold the shared jwt", secret)
+		}
+		assert.Nil(t, args.ExecutionClientArgs.ExecutionJwt, "Expected the caller's args to be left untouched")
+	})
+
+	t.Run("ExecutionEndpoint", func(t *testing.T) {
+		m := &mocks{inputs: map[string]resource.PropertyMap{}}
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			_, err := NewEthereumNode(ctx, "testNode", nodeArgs(t))
+			assert.NoError(t, err, "Expected to not receive an error")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		assert.Contains(t, m.inputs, "testNode-executionJwt", "Expected a jwt to be generated")
+		assert.Equal(t, 64.0, m.inputs["testNode-executionJwt"]["length"].NumberValue(), "Expected 32 hex encoded bytes")
+		assert.Equal(t, "abcdef", m.inputs["testNode-executionJwt"]["overrideSpecial"].StringValue())
+		container := m.inputs["lighthouse-set"]["spec"].ObjectValue()["template"].ObjectValue()["spec"].ObjectValue()["containers"].ArrayValue()[0].ObjectValue()
+		env := unwrapSecret(container["env"]).ArrayValue()[0].ObjectValue()
+		assert.Equal(t, "http://reth-internal-service.default.svc.cluster.local:8551", env["value"].StringValue())
+	})
+
+	t.Run("NetworkArgs", func(t *testing.T) {
+		m := &mocks{inputs: map[string]resource.PropertyMap{}}
+		args := nodeArgs(t)
+		args.ExecutionClientArgs.Network = "mainnet"
+		args.ConsensusClientArgs.Network = "mainnet"
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			_, err := NewEthereumNode(ctx, "testNode", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		containerFlags := func(set string) ([]string, []string) {
+			container := m.inputs[set]["spec"].ObjectValue()["template"].ObjectValue()["spec"].ObjectValue()["containers"].ArrayValue()[0].ObjectValue()
+			var flags, mounts []string
+			for _, flag := range container["args"].ArrayValue() {
+				flags = append(flags, flag.StringValue())
+			}
+			for _, mount := range container["volumeMounts"].ArrayValue() {
+				mounts = append(mounts, mount.ObjectValue()["mountPath"].StringValue())
+			}
+			return flags, mounts
+		}
+		flags, _ := containerFlags("reth-set")
+		assert.Subset(t, flags, []string{"node", "--chain=mainnet", "--datadir=/root/.local/share/reth"})
+		flags, mounts := containerFlags("lighthouse-set")
+		assert.Subset(t, flags, []string{"--network", "mainnet", "--datadir", "/root/.lighthouse/mainnet"})
+		assert.Contains(t, mounts, "/root/.lighthouse/mainnet")
+
+		m = &mocks{inputs: map[string]resource.PropertyMap{}}
+		args.ExecutionClientArgs.ExecutionClientContainerCommands = []string{"reth", "node"}
+		err = pulumi.RunErr(func(ctx *pulumi.Context) error {
+			_, err := NewEthereumNode(ctx, "testNode", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+		flags, _ = containerFlags("reth-set")
+		assert.Empty(t, flags, "Expected container commands to replace the default flags")
+	})
+
+	t.Run("Namespace", func(t *testing.T) {
+		m := &mocks{inputs: map[string]resource.PropertyMap{}}
+		args := nodeArgs(t)
+		args.Namespace = "holesky"
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			_, err := NewEthereumNode(ctx, "testNode", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		for _, object := range []string{"holesky-reth-data", "holesky-reth-config", "holesky-reth-execution-jwt", "holesky-reth-set", "holesky-lighthouse-data", "holesky-lighthouse-set"} {
+			assert.Contains(t, m.inputs, object)
+			assert.Equal(t, "holesky", m.inputs[object]["metadata"].ObjectValue()["namespace"].StringValue(), "Expected %s in the node's namespace", object)
+		}
+		container := m.inputs["holesky-lighthouse-set"]["spec"].ObjectValue()["template"].ObjectValue()["spec"].ObjectValue()["containers"].ArrayValue()[0].ObjectValue()
+		env := unwrapSecret(container["env"]).ArrayValue()[0].ObjectValue()
+		assert.Equal(t, "http://reth-internal-service.holesky.svc.cluster.local:8551", env["value"].StringValue())
+	})
+
+	t.Run("Ingress", func(t *testing.T) {
+		m := &mocks{inputs: map[string]resource.PropertyMap{}}
+		args := nodeArgs(t)
+		args.ExecutionClientArgs.EnableIngress = true
+		args.ExecutionClientArgs.IngressHost = "rpc.example.com"
+		args.ExecutionClientArgs.IngressTlsIssuer = "letsencrypt"
+		args.ExecutionClientArgs.IngressBasicAuthSecret = "rpc-auth"
+		args.ExecutionClientArgs.IngressAllowedSourceRanges = []string{"10.0.0.0/8", "192.168.0.0/16"}
+		args.ConsensusClientArgs.EnableRpcIngress = true
+		args.ConsensusClientArgs.IngressKind = "httproute"
+		args.ConsensusClientArgs.IngressHost = "beacon.example.com"
+		args.ConsensusClientArgs.IngressGateway = "gateways/public"
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			_, err := NewEthereumNode(ctx, "testNode", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		assert.Equal(t, "ClusterIP", m.inputs["reth-rpc-service"]["spec"].ObjectValue()["type"].StringValue())
+		certificate := m.inputs["reth-rpc-tls"]["spec"].ObjectValue()
+		assert.Equal(t, "reth-rpc-tls", certificate["secretName"].StringValue())
+		assert.Equal(t, "letsencrypt", certificate["issuerRef"].ObjectValue()["name"].StringValue())
+
+		for ingress, port := range map[string]float64{"reth-rpc-ingress": 8545, "reth-ws-ingress": 8546} {
+			annotations := m.inputs[ingress]["metadata"].ObjectValue()["annotations"].ObjectValue()
+			assert.Equal(t, "rpc-auth", annotations["nginx.ingress.kubernetes.io/auth-secret"].StringValue())
+			assert.Equal(t, "10.0.0.0/8,192.168.0.0/16", annotations["nginx.ingress.kubernetes.io/whitelist-source-range"].StringValue())
+			spec := m.inputs[ingress]["spec"].ObjectValue()
+			assert.Equal(t, "reth-rpc-tls", spec["tls"].ArrayValue()[0].ObjectValue()["secretName"].StringValue())
+			path := spec["rules"].ArrayValue()[0].ObjectValue()["http"].ObjectValue()["paths"].ArrayValue()[0].ObjectValue()
+			assert.Equal(t, port, path["backend"].ObjectValue()["service"].ObjectValue()["port"].ObjectValue()["number"].NumberValue())
+		}
+		assert.Equal(t, "/ws", m.inputs["reth-ws-ingress"]["spec"].ObjectValue()["rules"].ArrayValue()[0].ObjectValue()["http"].ObjectValue()["paths"].ArrayValue()[0].ObjectValue()["path"].StringValue())
+
+		route := m.inputs["lighthouse-rpc-route"]["spec"].ObjectValue()
+		assert.Equal(t, "public", route["parentRefs"].ArrayValue()[0].ObjectValue()["name"].StringValue())
+		assert.Equal(t, "gateways", route["parentRefs"].ArrayValue()[0].ObjectValue()["namespace"].StringValue())
+		backend := route["rules"].ArrayValue()[0].ObjectValue()["backendRefs"].ArrayValue()[0].ObjectValue()
+		assert.Equal(t, "lighthouse-beacon-api-service", backend["name"].StringValue())
+		assert.NotContains(t, m.inputs, "lighthouse-rpc-tls", "Expected the gateway to terminate TLS")
+	})
+
+	t.Run("Probes", func(t *testing.T) {
+		m := &mocks{inputs: map[string]resource.PropertyMap{}}
+		args := nodeArgs(t)
+		args.ExecutionClientArgs.ProbeMaxBlockAge = 60
+		args.ConsensusClientArgs.ProbeFailureThreshold = 3
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			_, err := NewEthereumNode(ctx, "testNode", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		containers := func(set string) []resource.PropertyValue {
+			return m.inputs[set]["spec"].ObjectValue()["template"].ObjectValue()["spec"].ObjectValue()["containers"].ArrayValue()
+		}
+		reth := containers("reth-set")
+		assert.Len(t, reth, 2, "Expected the probe sidecar next to reth")
+		assert.Equal(t, "reth-probe", reth[1].ObjectValue()["name"].StringValue())
+		readiness := reth[0].ObjectValue()["readinessProbe"].ObjectValue()
+		assert.Equal(t, "/cgi-bin/ready", readiness["httpGet"].ObjectValue()["path"].StringValue())
+		assert.Equal(t, float64(8099), readiness["httpGet"].ObjectValue()["port"].NumberValue())
+		startup := reth[0].ObjectValue()["startupProbe"].ObjectValue()
+		assert.Equal(t, float64(720), startup["failureThreshold"].NumberValue())
+		assert.Contains(t, m.inputs["reth-probe"]["data"].ObjectValue()["ready"].StringValue(), "MAX_BLOCK_AGE=60")
+
+		lighthouse := containers("lighthouse-set")[0].ObjectValue()
+		readiness = lighthouse["readinessProbe"].ObjectValue()
+		assert.Equal(t, "/eth/v1/node/health?syncing_status=503", readiness["httpGet"].ObjectValue()["path"].StringValue())
+		assert.Equal(t, float64(5052), readiness["httpGet"].ObjectValue()["port"].NumberValue())
+		assert.Equal(t, float64(3), lighthouse["livenessProbe"].ObjectValue()["failureThreshold"].NumberValue())
+
+		m = &mocks{inputs: map[string]resource.PropertyMap{}}
+		args.ExecutionClientArgs.DisableProbes = true
+		args.ConsensusClientArgs.DisableProbes = true
+		err = pulumi.RunErr(func(ctx *pulumi.Context) error {
+			_, err := NewEthereumNode(ctx, "testNode", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+		assert.Len(t, containers("reth-set"), 1)
+		assert.NotContains(t, m.inputs, "reth-probe")
+		assert.NotContains(t, containers("lighthouse-set")[0].ObjectValue(), resource.PropertyKey("readinessProbe"))
+	})
+
+	t.Run("RenderManifests", func(t *testing.T) {
+		m := &mocks{inputs: map[string]resource.PropertyMap{}}
+		args := nodeArgs(t)
+		args.RenderManifestsTo = "manifests"
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			_, err := NewEthereumNode(ctx, "testNode", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		assert.Equal(t, "manifests/testNode/reth", m.inputs["reth-manifests"]["renderYamlToDirectory"].StringValue())
+		assert.Equal(t, "manifests/testNode/lighthouse", m.inputs["lighthouse-manifests"]["renderYamlToDirectory"].StringValue())
+		// objects without a namespace are written to the one secrets are sealed for
+		assert.Equal(t, "default", m.inputs["reth-manifests"]["namespace"].StringValue())
+		for _, secret := range []string{"reth-execution-jwt", "lighthouse-execution-jwt"} {
+			assert.NotContains(t, m.inputs, secret, "Expected %s to be written as a reference", secret)
+		}
+		assert.Equal(t, "", args.ExecutionClientArgs.RenderManifestsTo, "Expected the caller's args to be left untouched")
+
+		args.MevBoostArgs = &mevBoost.MevBoostComponentArgs{Network: "holesky", DeploymentType: "kubernetes"}
+		err = pulumi.RunErr(func(ctx *pulumi.Context) error {
+			_, err := NewEthereumNode(ctx, "testNode", args)
+			return err
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.ErrorContains(t, err, "not supported for mev-boost and op-node")
+	})
+
+	t.Run("MevBoost", func(t *testing.T) {
+		m := &mocks{inputs: map[string]resource.PropertyMap{}}
+		args := nodeArgs(t)
+		args.MevBoostArgs = &mevBoost.MevBoostComponentArgs{
+			Network:        "holesky",
+			DeploymentType: "kubernetes",
+			CpuLimit:       "500m",
+			MemoryLimit:    "256Mi",
+		}
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			node, err := NewEthereumNode(ctx, "testNode", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+			assert.NotNil(t, node.MevBoost, "Expected a mev-boost sidecar")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		container := m.inputs["lighthouse-set"]["spec"].ObjectValue()["template"].ObjectValue()["spec"].ObjectValue()["containers"].ArrayValue()[0].ObjectValue()
+		var flags []string
+		for _, flag := range container["args"].ArrayValue() {
+			flags = append(flags, flag.StringValue())
+		}
+		assert.Subset(t, flags, []string{"--builder", "http://mev-boost-service.default.svc.cluster.local:18550"})
+	})
+
+	t.Run("OpNode", func(t *testing.T) {
+		m := &mocks{inputs: map[string]resource.PropertyMap{}}
+		args := nodeArgs(t)
+		args.ExecutionClientArgs.Network = "base"
+		args.ConsensusClientArgs = nil
+		args.ExecutionJwt = pulumi.String("testJwt")
+		args.OpNodeArgs = &opNode.OpNodeComponentArgs{
+			Network:        "base",
+			DeploymentType: "kubernetes",
+			L1RpcUrl:       pulumi.String("http://reth-rpc:8545"),
+			L1BeaconUrl:    pulumi.String("http://lighthouse-beacon:5052"),
+			CpuLimit:       "2",
+			MemoryLimit:    "4Gi",
+		}
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			node, err := NewEthereumNode(ctx, "testNode", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+			assert.Nil(t, node.ConsensusClient, "Expected no consensus client")
+			assert.NotNil(t, node.OpNode, "Expected an op-node")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		container := m.inputs["op-node-set"]["spec"].ObjectValue()["template"].ObjectValue()["spec"].ObjectValue()["containers"].ArrayValue()[0].ObjectValue()
+		var flags []string
+		for _, flag := range container["args"].ArrayValue() {
+			flags = append(flags, flag.StringValue())
+		}
+		assert.Contains(t, flags, "--l2=http://reth-internal-service.default.svc.cluster.local:8551")
+		reth := m.inputs["reth-set"]["spec"].ObjectValue()["template"].ObjectValue()["spec"].ObjectValue()["containers"].ArrayValue()[0].ObjectValue()
+		assert.Equal(t, "ghcr.io/paradigmxyz/op-reth:latest", reth["image"].StringValue())
+		assert.Equal(t, "testJwt", unwrapSecret(unwrapSecret(m.inputs["op-node-execution-jwt"]["stringData"]).ObjectValue()["jwt.hex"]).StringValue())
+
+		// a node with both a consensus client and op-node is rejected before
+		// anything is registered
+		m = &mocks{inputs: map[string]resource.PropertyMap{}}
+		args.ConsensusClientArgs = nodeArgs(t).ConsensusClientArgs
+		err = pulumi.RunErr(func(ctx *pulumi.Context) error {
+			_, err := NewEthereumNode(ctx, "testNode", args)
+			return err
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.ErrorContains(t, err, "node testNode sets both ConsensusClientArgs and OpNodeArgs")
+		assert.Empty(t, m.resources, "Expected no resources to be registered")
+	})
+
+	t.Run("Caplin", func(t *testing.T) {
+		m := &mocks{inputs: map[string]resource.PropertyMap{}}
+		args := nodeArgs(t)
+		args.ExecutionClientArgs.Client = "erigon"
+		args.ExecutionClientArgs.Name = ""
+		args.ExecutionClientArgs.EnableCaplin = true
+		args.ConsensusClientArgs = nil
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			node, err := NewEthereumNode(ctx, "testNode", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+			assert.Nil(t, node.ConsensusClient, "Expected no separate consensus client")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		var portNames []string
+		for _, port := range m.inputs["erigon-p2p-service"]["spec"].ObjectValue()["ports"].ArrayValue() {
+			portNames = append(portNames, port.ObjectValue()["name"].StringValue())
+		}
+		assert.Equal(t, []string{"p2p-tcp", "p2p-udp", "torrent-tcp", "torrent-udp", "caplin-udp", "caplin-tcp"}, portNames)
+	})
+}
+
+func TestBuilderEndpoint(t *testing.T) {
+	boost := &mevBoost.MevBoostComponent{Url: pulumi.String("http://10.0.0.1:18550").ToStringOutput()}
+	for _, test := range []struct {
+		mevBoost, consensusClient, expected string
+	}{
+		{"source", "source", "http://127.0.0.1:18550"},
+		{"source", "binary", "http://127.0.0.1:18550"},
+		{"source", "docker", "http://10.0.0.1:18550"},
+		{"kubernetes", "kubernetes", "http://10.0.0.1:18550"},
+	} {
+		endpoint := builderEndpoint(boost, &mevBoost.MevBoostComponentArgs{DeploymentType: test.mevBoost}, &consensusClient.ConsensusClientComponentArgs{DeploymentType: test.consensusClient})
+		done := make(chan struct{})
+		endpoint.ToStringOutput().ApplyT(func(url string) error {
+			assert.Equal(t, test.expected, url, "mev-boost %s, consensus client %s", test.mevBoost, test.consensusClient)
+			close(done)
+			return nil
+		})
+		<-done
+	}
+}
+
+func TestEthereumNodeFactory(t *testing.T) {
+	m := &mocks{inputs: map[string]resource.PropertyMap{}}
+	template := &EthereumNodeTemplate{
+		EthereumNodeArgs: *nodeArgs(t),
+		Overrides: map[int]*EthereumNodeOverrides{
+			1: {PodStorageClass: "fast-ssd"},
+		},
+	}
+	template.ExecutionClientArgs.Client = "reth-exex"
+	template.Replicas = 2
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		fleet, err := EthereumNodeFactory(ctx, "testFleet", template)
+		assert.NoError(t, err, "Expected to not receive an error")
+		assert.Len(t, fleet.Nodes, 2)
+		return nil
+	}, pulumi.WithMocks("project", "stack", m))
+	assert.NoError(t, err, "Expected to not receive an error")
+
+	for _, name := range []string{"testFleet-0-executionJwt", "testFleet-1-executionJwt", "reth-0-rpc-service", "reth-1-rpc-service", "lighthouse-0-set", "lighthouse-1-set"} {
+		assert.Contains(t, m.inputs, name)
+	}
+	assert.Equal(t, "fast-ssd", m.inputs["lighthouse-1-data"]["spec"].ObjectValue()["storageClassName"].StringValue())
+	assert.Equal(t, "reth", template.ExecutionClientArgs.Name, "Expected the template to be left untouched")
+
+	m = &mocks{inputs: map[string]resource.PropertyMap{}}
+	template.RenderManifestsTo = "manifests"
+	err = pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := EthereumNodeFactory(ctx, "testFleet", template)
+		assert.NoError(t, err, "Expected to not receive an error")
+		return nil
+	}, pulumi.WithMocks("project", "stack", m))
+	assert.NoError(t, err, "Expected to not receive an error")
+	for _, replica := range []string{"0", "1"} {
+		assert.Equal(t, "manifests/testFleet-"+replica+"/reth-"+replica, m.inputs["reth-"+replica+"-manifests"]["renderYamlToDirectory"].StringValue())
+		assert.Equal(t, "manifests/testFleet-"+replica+"/lighthouse-"+replica, m.inputs["lighthouse-"+replica+"-manifests"]["renderYamlToDirectory"].StringValue())
+		assert.NotContains(t, m.inputs, "reth-"+replica+"-execution-jwt", "Expected the replica's secrets to be written as references")
+	}
+	template.RenderManifestsTo = ""
+
+	m = &mocks{inputs: map[string]resource.PropertyMap{}}
+	template.Namespace = "holesky"
+	err = pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := EthereumNodeFactory(ctx, "testFleet", template)
+		assert.NoError(t, err, "Expected to not receive an error")
+		return nil
+	}, pulumi.WithMocks("project", "stack", m))
+	assert.NoError(t, err, "Expected to not receive an error")
+	for _, set := range []string{"holesky-reth-0-set", "holesky-lighthouse-1-set"} {
+		assert.Equal(t, "holesky", m.inputs[set]["metadata"].ObjectValue()["namespace"].StringValue(), "Expected %s in the template's namespace", set)
+	}
+	template.Namespace = ""
+
+	err = pulumi.RunErr(func(ctx *pulumi.Context) error {
+		template.Connections = []*remote.ConnectionArgs{{}}
+		_, err := EthereumNodeFactory(ctx, "testFleet", template)
+		return err
+	}, pulumi.WithMocks("project", "stack", &mocks{inputs: map[string]resource.PropertyMap{}}))
+	assert.ErrorContains(t, err, "template has 1 connections for 2 replicas")
+}
+
+func TestEthereumNodeFactorySource(t *testing.T) {
+	m := &mocks{inputs: map[string]resource.PropertyMap{}}
+	template := &EthereumNodeTemplate{
+		EthereumNodeArgs: EthereumNodeArgs{
+			ExecutionClientArgs: &executionClient.ExecutionClientComponentArgs{
+				Client:         "geth",
+				Network:        "holesky",
+				DeploymentType: "source",
+			},
+			ConsensusClientArgs: &consensusClient.ConsensusClientComponentArgs{
+				Client:         "lighthouse",
+				Network:        "holesky",
+				DeploymentType: "source",
+			},
+			Replicas: 2,
+		},
+		Connections: []*remote.ConnectionArgs{
+			{Host: pulumi.String("10.0.0.1")},
+			{Host: pulumi.String("10.0.0.2")},
+		},
+	}
+
+	err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := EthereumNodeFactory(ctx, "testFleet", template)
+		assert.NoError(t, err, "Expected to not receive an error")
+		return nil
+	}, pulumi.WithMocks("project", "stack", m))
+	assert.NoError(t, err, "Expected to not receive an error")
+
+	seen := map[string]bool{}
+	for _, name := range m.resources {
+		assert.False(t, seen[name], "Expected %s to be registered once", name)
+		seen[name] = true
+	}
+	for _, name := range []string{"cloneRepo-geth-0", "cloneRepo-geth-1", "executionService-geth-1", "createServiceDefinition-executionService-geth-1", "consensusService-lighthouse-0", "consensusService-lighthouse-1"} {
+		assert.Contains(t, m.inputs, name)
+	}
+}
+
+func TestLoadFleetSpec(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "reth.toml"), []byte("[test]"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "lighthouse.toml"), []byte("[test]"), 0o600))
+
+	specPath := filepath.Join(dir, "fleet.yaml")
+	assert.NoError(t, os.WriteFile(specPath, []byte(`
+nodes:
+  - name: holesky
+    network: holesky
+    replicas: 2
+    cluster:
+      context: staging
+      namespace: holesky
+    execution:
+      client: reth
+      deploymentType: kubernetes
+      version: v1.1.0
+      configPath: reth.toml
+      resources: {cpuLimit: "4", memoryLimit: 16Gi}
+      storage: {class: fast-ssd, size: 500Gi}
+    consensus:
+      client: lighthouse
+      deploymentType: kubernetes
+      configPath: lighthouse.toml
+      resources: {cpuLimit: "2", memoryLimit: 8Gi}
+`), 0o600))
+
+	spec, err := LoadFleetSpec(specPath)
+	assert.NoError(t, err, "Expected to not receive an error")
+	assert.Len(t, spec.Nodes, 1)
+	node := spec.Nodes[0]
+	assert.Equal(t, "holesky", node.Name)
+	assert.Equal(t, 2, node.Args.Replicas)
+	assert.Equal(t, "staging", node.Cluster.Context)
+	assert.Equal(t, "staging", node.Args.ExecutionClientArgs.KubeContext)
+	assert.Equal(t, "staging", node.Args.ConsensusClientArgs.KubeContext)
+	assert.Equal(t, "holesky", node.Args.Namespace)
+	assert.Equal(t, "ghcr.io/paradigmxyz/reth:v1.1.0", node.Args.ExecutionClientArgs.ExecutionClientImage)
+	assert.Equal(t, filepath.Join(dir, "reth.toml"), node.Args.ExecutionClientArgs.ExecutionClientConfigPath)
+	assert.Equal(t, "fast-ssd", node.Args.ExecutionClientArgs.PodStorageClass)
+	assert.Equal(t, "8Gi", node.Args.ConsensusClientArgs.MemoryLimit)
+
+	m := &mocks{inputs: map[string]resource.PropertyMap{}}
+	err = pulumi.RunErr(func(ctx *pulumi.Context) error {
+		_, err := DeployFleetSpec(ctx, spec)
+		assert.NoError(t, err, "Expected to not receive an error")
+		return nil
+	}, pulumi.WithMocks("project", "stack", m))
+	assert.NoError(t, err, "Expected to not receive an error")
+	for _, set := range []string{"holesky-reth-0-set", "holesky-reth-1-set", "holesky-lighthouse-1-set"} {
+		assert.Contains(t, m.inputs, set)
+		assert.Equal(t, "holesky", m.inputs[set]["metadata"].ObjectValue()["namespace"].StringValue(), "Expected %s in the cluster's namespace", set)
+	}
+
+	assert.NoError(t, os.WriteFile(specPath, []byte(`
+nodes:
+  - name: holesky
+    network: holesky
+    host: {address: 10.0.0.1, user: root}
+    execution:
+      client: geth
+      deploymentType: binary
+      version: 1.14.11-f3c696fa
+      release: {sha256: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08, arch: arm64}
+    consensus:
+      client: lighthouse
+      deploymentType: source
+      source: {repoUrl: https://example.com/lighthouse.git, ref: v5.3.0}
+`), 0o600))
+	spec, err = LoadFleetSpec(specPath)
+	assert.NoError(t, err, "Expected to not receive an error")
+	consensusArgs := spec.Nodes[0].Args.ConsensusClientArgs
+	assert.Equal(t, "https://example.com/lighthouse.git", consensusArgs.SourceRepoUrl)
+	assert.Equal(t, "v5.3.0", consensusArgs.SourceRef)
+	executionArgs := spec.Nodes[0].Args.ExecutionClientArgs
+	assert.Equal(t, "1.14.11-f3c696fa", executionArgs.BinaryVersion)
+	assert.Equal(t, "arm64", executionArgs.BinaryArch)
+	assert.Empty(t, executionArgs.ExecutionClientImage)
+
+	assert.NoError(t, os.WriteFile(specPath, []byte(`
+nodes:
+  - name: holesky
+    network: holesky
+    execution: {client: reth, deploymentType: ssh}
+    consensus: {client: lighthouse, deploymentType: kubernetes}
+`), 0o600))
+	_, err = LoadFleetSpec(specPath)
+	assert.ErrorContains(t, err, "deploymentType", "Expected the schema to reject the deployment type")
+
+	assert.NoError(t, os.WriteFile(specPath, []byte(`
+nodes:
+  - name: holesky
+    network: holesky
+    execution: {client: reth, deploymentType: source, source: {branch: main}}
+    consensus: {client: lighthouse, deploymentType: kubernetes}
+`), 0o600))
+	_, err = LoadFleetSpec(specPath)
+	assert.ErrorContains(t, err, "branch", "Expected the schema to reject unknown source fields")
+
+	assert.NoError(t, os.WriteFile(specPath, []byte(`
+nodes:
+  - name: holesky
+    network: holesky
+    execution: {client: not-a-client, deploymentType: kubernetes}
+    consensus: {client: lighthouse, deploymentType: kubernetes}
+`), 0o600))
+	_, err = LoadFleetSpec(specPath)
+	assert.ErrorContains(t, err, `unknown execution client "not-a-client"`)
+
+	assert.NoError(t, os.WriteFile(specPath, []byte(`
+nodes:
+  - name: holesky
+    network: holesky
+    execution: {client: erigon, deploymentType: kubernetes}
+`), 0o600))
+	_, err = LoadFleetSpec(specPath)
+	assert.ErrorContains(t, err, "consensus", "Expected the schema to require a consensus client without caplin")
+
+	assert.NoError(t, os.WriteFile(specPath, []byte(`
+nodes:
+  - name: holesky
+    network: holesky
+    execution:
+      client: erigon
+      deploymentType: kubernetes
+      caplin: true
+      configPath: reth.toml
+      resources: {cpuLimit: "4", memoryLimit: 16Gi}
+`), 0o600))
+	spec, err = LoadFleetSpec(specPath)
+	assert.NoError(t, err, "Expected caplin nodes to omit the consensus client")
+	assert.True(t, spec.Nodes[0].Args.ExecutionClientArgs.EnableCaplin)
+	assert.Nil(t, spec.Nodes[0].Args.ConsensusClientArgs)
+}
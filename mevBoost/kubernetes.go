@@ -0,0 +1,105 @@
+package mevBoost
+
+import (
+	"fmt"
+
+	appsv1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/apps/v1"
+	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
+	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// defaultImage is used when MevBoostComponentArgs.Image is empty.
+const defaultImage = "flashbots/mev-boost:latest"
+
+// deployKubernetes runs mev-boost as a Deployment, it keeps no state, behind a
+// ClusterIP service the consensus clients connect to.
+func deployKubernetes(ctx *pulumi.Context, component *MevBoostComponent, args *MevBoostComponentArgs) error {
+	prefix := resourcePrefix(args)
+	image := args.Image
+	if image == "" {
+		image = defaultImage
+	}
+
+	_, err := appsv1.NewDeployment(ctx, fmt.Sprintf("%s-deployment", prefix), &appsv1.DeploymentArgs{
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.String(prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name":    pulumi.Sprintf("%s-deployment", prefix),
+				"app.kubernetes.io/part-of": pulumi.String("mev-boost"),
+			},
+		},
+		Spec: &appsv1.DeploymentSpecArgs{
+			Replicas: pulumi.Int(1),
+			Selector: &metav1.LabelSelectorArgs{
+				MatchLabels: pulumi.StringMap{
+					"app": pulumi.String(prefix),
+				},
+			},
+			Template: &corev1.PodTemplateSpecArgs{
+				Metadata: &metav1.ObjectMetaArgs{
+					Labels: pulumi.StringMap{
+						"app":                       pulumi.String(prefix),
+						"app.kubernetes.io/name":    pulumi.String(prefix),
+						"app.kubernetes.io/part-of": pulumi.String("mev-boost"),
+					},
+				},
+				Spec: &corev1.PodSpecArgs{
+					Containers: corev1.ContainerArray{
+						corev1.ContainerArgs{
+							Name:  pulumi.String(prefix),
+							Image: pulumi.String(image),
+							Args:  pulumi.ToStringArray(Flags(args)),
+							Ports: corev1.ContainerPortArray{
+								corev1.ContainerPortArgs{
+									ContainerPort: pulumi.Int(port(args)),
+								},
+							},
+							Resources: &corev1.ResourceRequirementsArgs{
+								Limits: pulumi.StringMap{
+									"cpu":    pulumi.String(args.CpuLimit),
+									"memory": pulumi.String(args.MemoryLimit),
+								},
+								Requests: pulumi.StringMap{
+									"cpu":    pulumi.String(args.CpuRequest),
+									"memory": pulumi.String(args.MemoryRequest),
+								},
+							},
+						},
+					},
+					DnsPolicy: pulumi.String("ClusterFirst"),
+				},
+			},
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	service, err := corev1.NewService(ctx, fmt.Sprintf("%s-service", prefix), &corev1.ServiceArgs{
+		Spec: &corev1.ServiceSpecArgs{
+			Selector: pulumi.StringMap{"app": pulumi.String(prefix)},
+			Type:     pulumi.String("ClusterIP"),
+			Ports: corev1.ServicePortArray{
+				corev1.ServicePortArgs{
+					Port: pulumi.Int(port(args)),
+					Name: pulumi.String("builder-api"),
+				},
+			},
+		},
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.Sprintf("%s-service", prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name":    pulumi.Sprintf("%s-service", prefix),
+				"app.kubernetes.io/part-of": pulumi.String("mev-boost"),
+			},
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	component.Url = pulumi.Sprintf("http://%s:%d", utils.ClusterServiceHost(service), port(args))
+	return nil
+}
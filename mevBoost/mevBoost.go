@@ -0,0 +1,203 @@
+package mevBoost
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+type MevBoostComponent struct {
+	pulumi.ResourceState
+
+	// Url is the builder api endpoint consensus clients use as BuilderEndpoint.
+	Url pulumi.StringOutput `pulumi:"url"`
+}
+
+type MevBoostComponentArgs struct {
+	Connection     *remote.ConnectionArgs
+	Network        string
+	DeploymentType string
+	Name           string
+	// Relays replaces the DefaultRelays of the network. Every relay is an url
+	// of the form https://<pubkey>@<host>.
+	Relays []string
+	// MinBid is the minimum bid in ETH, e.g. "0.05". Lower bids are ignored and
+	// the block is built locally instead.
+	MinBid        string
+	Port          int
+	Image         string
+	CpuLimit      string
+	MemoryLimit   string
+	CpuRequest    string
+	MemoryRequest string
+	// SourceRepoUrl and SourceRef select the repository and the branch, tag or
+	// commit source deployments are built from, flashbots/mev-boost's stable
+	// branch by default.
+	SourceRepoUrl string
+	SourceRef     string
+}
+
+const (
+	Source      = "source"
+	Kubernetes  = "kubernetes"
+	Mainnet     = "mainnet"
+	Sepolia     = "sepolia"
+	Holesky     = "holesky"
+	Hoodi       = "hoodi"
+	DefaultPort = 18550
+)
+
+// DefaultRelays holds the relays mev-boost connects to per network when
+// MevBoostComponentArgs.Relays is empty.
+var DefaultRelays = map[string][]string{
+	Mainnet: {
+		"https://0xac6e77dfe25ecd6110b8e780608cce0dab71fdd5ebea22a16c0205200f2f8e2e3ad3b71d3499c54ad14d6c21b41a37ae@boost-relay.flashbots.net",
+		"https://0xa1559ace749633b997cb3fdacffb890aeebdb0f5a3b6aaa7eeeaf1a38af0a8fe88b9e4b1f61f236d2e64d95733327a62@relay.ultrasound.money",
+		"https://0xa7ab7a996c8584251c8f925da3170bdfd6ebc75d50f5ddc4050a6fdc77f2a3b5fce2cc750d0865e05d7228af97d69561@agnostic-relay.net",
+		"https://0x8b5d2e73e2a3a55c6c87b8b6eb92e0149a125c852751db1422fa951e42a09b82c142c3ea98d0d9930b056a3bc9896b8f@bloxroute.max-profit.blxrbdn.com",
+	},
+	Sepolia: {
+		"https://0x845bd072b7cd566f02faeb0a4033ce9399e42839ced64e8b2adcfc859ed1e8e1a5a293336a49feac6d9a5edb779be53a@boost-relay-sepolia.flashbots.net",
+	},
+	Holesky: {
+		"https://0xafa4c6985aa049fb79dd37010438cfebeb0f2bd42b115b89dd678dab0670c1de38da0c4e9138c9290a398ecd9a0b3110@boost-relay-holesky.flashbots.net",
+		"https://0xb1559beef7b5ba3127485bbbb090362d9f497ba64e177ee2c8e7db74746306efad687f2cf8574e38d70067d40ef136dc@relay-stag.ultrasound.money",
+	},
+	Hoodi: {
+		"https://0xafa4c6985aa049fb79dd37010438cfebeb0f2bd42b115b89dd678dab0670c1de38da0c4e9138c9290a398ecd9a0b3110@boost-relay-hoodi.flashbots.net",
+	},
+}
+
+var (
+	networks        = []string{Mainnet, Sepolia, Holesky, Hoodi}
+	deploymentTypes = []string{Source, Kubernetes}
+)
+
+// Validate checks the args for unknown networks and deployment types, malformed
+// relays and min-bid as well as settings required by the chosen deployment
+// type. All problems found are returned together as utils.ValidationErrors.
+func (args *MevBoostComponentArgs) Validate() error {
+	var errs utils.ValidationErrors
+
+	if !slices.Contains(networks, args.Network) {
+		errs.Add("Network", "unknown network %q, expected one of %s", args.Network, strings.Join(networks, ", "))
+	}
+	if !slices.Contains(deploymentTypes, args.DeploymentType) {
+		errs.Add("DeploymentType", "unknown deployment type %q, expected one of %s", args.DeploymentType, strings.Join(deploymentTypes, ", "))
+	}
+	for _, relay := range args.Relays {
+		if (!strings.HasPrefix(relay, "https://0x") && !strings.HasPrefix(relay, "http://0x")) || !strings.Contains(relay, "@") {
+			errs.Add("Relays", "%q is not a relay url of the form https://<pubkey>@<host>", relay)
+		}
+	}
+	if args.MinBid != "" {
+		if bid, err := strconv.ParseFloat(args.MinBid, 64); err != nil || bid < 0 {
+			errs.Add("MinBid", "%q is not an amount of ETH", args.MinBid)
+		}
+	}
+
+	switch args.DeploymentType {
+	case Source:
+		if args.Connection == nil {
+			errs.Add("Connection", "required for %s deployments", args.DeploymentType)
+		}
+	case Kubernetes:
+		if args.CpuLimit == "" {
+			errs.Add("CpuLimit", "required for %s deployments", args.DeploymentType)
+		}
+		if args.MemoryLimit == "" {
+			errs.Add("MemoryLimit", "required for %s deployments", args.DeploymentType)
+		}
+	}
+
+	return errs.Err()
+}
+
+// NewMevBoostComponent runs mev-boost with the network's relays, either built
+// from source as a systemd service or as a kubernetes Deployment behind a
+// ClusterIP service. Consensus clients use its Url output as BuilderEndpoint.
+// The args are validated before any resources are registered.
+//
+// Example usage:
+//
+//	boost, err := mevBoost.NewMevBoostComponent(ctx, "holeskyMevBoost", &mevBoost.MevBoostComponentArgs{
+//		Network:        "holesky",
+//		DeploymentType: "kubernetes",
+//		MinBid:         "0.05",
+//		CpuLimit:       "500m",
+//		MemoryLimit:    "256Mi",
+//	})
+func NewMevBoostComponent(ctx *pulumi.Context, name string, args *MevBoostComponentArgs, opts ...pulumi.ResourceOption) (*MevBoostComponent, error) {
+	if args == nil {
+		args = &MevBoostComponentArgs{}
+	}
+
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+
+	component := &MevBoostComponent{}
+	err := ctx.RegisterComponentResource("custom:component:MevBoost", name, component, opts...)
+	if err != nil {
+		return nil, err
+	}
+	component.Url = pulumi.String("").ToStringOutput()
+
+	switch args.DeploymentType {
+	case Source:
+		err = deploySource(ctx, component, args)
+	case Kubernetes:
+		err = deployKubernetes(ctx, component, args)
+	}
+	if err != nil {
+		ctx.Log.Error("Error creating mev-boost", nil)
+		return nil, err
+	}
+
+	if err := ctx.RegisterResourceOutputs(component, pulumi.Map{
+		"url": component.Url,
+	}); err != nil {
+		return nil, err
+	}
+
+	return component, nil
+}
+
+// Flags renders the mev-boost command line arguments for args.
+func Flags(args *MevBoostComponentArgs) []string {
+	relays := args.Relays
+	if len(relays) == 0 {
+		relays = DefaultRelays[args.Network]
+	}
+	flags := []string{
+		"-" + args.Network,
+		"-addr", fmt.Sprintf("0.0.0.0:%d", port(args)),
+		"-relay-check",
+		"-relays", strings.Join(relays, ","),
+	}
+	if args.MinBid != "" {
+		flags = append(flags, "-min-bid", args.MinBid)
+	}
+	return flags
+}
+
+// port returns the port mev-boost listens on.
+func port(args *MevBoostComponentArgs) int {
+	if args.Port != 0 {
+		return args.Port
+	}
+	return DefaultPort
+}
+
+// resourcePrefix returns the prefix of the kubernetes objects and remote resources.
+func resourcePrefix(args *MevBoostComponentArgs) string {
+	if args.Name != "" {
+		return args.Name
+	}
+	return "mev-boost"
+}
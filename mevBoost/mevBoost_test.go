@@ -0,0 +1,117 @@
+package mevBoost_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rswanson/node_deployer/internal/testutil"
+	"github.com/rswanson/node_deployer/mevBoost"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+)
+
+// kubernetesArgs returns valid args to deploy mev-boost to kubernetes.
+func kubernetesArgs() *mevBoost.MevBoostComponentArgs {
+	return &mevBoost.MevBoostComponentArgs{
+		Network:        "holesky",
+		DeploymentType: "kubernetes",
+		MinBid:         "0.05",
+		CpuLimit:       "500m",
+		MemoryLimit:    "256Mi",
+	}
+}
+
+func TestMevBoostComponent(t *testing.T) {
+	t.Run("KubernetesComponent", func(t *testing.T) {
+		m := testutil.NewRecordingMocks()
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			boost, err := mevBoost.NewMevBoostComponent(ctx, "testMevBoost", kubernetesArgs())
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			done := make(chan struct{})
+			boost.Url.ApplyT(func(url string) error {
+				assert.Equal(t, "http://mev-boost-service.default.svc.cluster.local:18550", url)
+				close(done)
+				return nil
+			})
+			<-done
+
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		// the container runs the image entrypoint with the flags, the service exposes the builder api
+		container := testutil.Containers(testutil.PodSpec(m.Inputs["mev-boost-deployment"]))[0]
+		assert.Equal(t, "flashbots/mev-boost:latest", container["image"].StringValue())
+		assert.Equal(t, mevBoost.Flags(kubernetesArgs()), testutil.Flags(container))
+		assert.Equal(t, 18550.0, container["ports"].ArrayValue()[0].ObjectValue()["containerPort"].NumberValue())
+		port := m.Inputs["mev-boost-service"]["spec"].ObjectValue()["ports"].ArrayValue()[0].ObjectValue()
+		assert.Equal(t, "builder-api", port["name"].StringValue())
+		assert.Equal(t, 18550.0, port["port"].NumberValue())
+	})
+
+	t.Run("SourceComponent", func(t *testing.T) {
+		m := testutil.NewRecordingMocks()
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			args := kubernetesArgs()
+			args.DeploymentType = "source"
+			args.Connection = &remote.ConnectionArgs{Host: pulumi.String("127.0.0.1")}
+			args.Port = 18551
+
+			boost, err := mevBoost.NewMevBoostComponent(ctx, "testMevBoost", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			done := make(chan struct{})
+			boost.Url.ApplyT(func(url string) error {
+				assert.Equal(t, "http://127.0.0.1:18551", url)
+				close(done)
+				return nil
+			})
+			<-done
+
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		// the start script reads the flags, listening on the configured port, from the service environment
+		assert.Contains(t, m.Inputs["buildMevBoost-mev-boost"]["create"].StringValue(), "cd /data/repos/holesky/mev-boost && make build")
+		environment := m.Inputs["serviceEnvironment-mevBoostService-mev-boost"]["stdin"].StringValue()
+		assert.Contains(t, environment, `Environment="MEV_BOOST_FLAGS=-holesky -addr 0.0.0.0:18551 -relay-check -relays `)
+		assert.True(t, strings.HasSuffix(environment, " -min-bid 0.05\"\n"), "Expected the minimum bid to be set: %s", environment)
+	})
+}
+
+func TestFlags(t *testing.T) {
+	flags := mevBoost.Flags(kubernetesArgs())
+	assert.Equal(t, []string{"-holesky", "-addr", "0.0.0.0:18550", "-relay-check", "-relays"}, flags[:5])
+	for _, relay := range mevBoost.DefaultRelays["holesky"] {
+		assert.Contains(t, flags[5], relay)
+	}
+	assert.Equal(t, []string{"-min-bid", "0.05"}, flags[6:])
+
+	args := kubernetesArgs()
+	args.Relays = []string{"https://0xabc@relay.example.com"}
+	args.MinBid = ""
+	assert.Equal(t, []string{"-holesky", "-addr", "0.0.0.0:18550", "-relay-check", "-relays", "https://0xabc@relay.example.com"}, mevBoost.Flags(args))
+}
+
+func TestMevBoostComponentArgsValidate(t *testing.T) {
+	assert.NoError(t, kubernetesArgs().Validate(), "Expected valid args to pass validation")
+
+	err := (&mevBoost.MevBoostComponentArgs{
+		Network:        "testNetwork",
+		DeploymentType: "testDeploymentType",
+		Relays:         []string{"relay.example.com"},
+		MinBid:         "-1",
+	}).Validate()
+	assert.ErrorContains(t, err, `Network: unknown network "testNetwork"`)
+	assert.ErrorContains(t, err, `DeploymentType: unknown deployment type "testDeploymentType"`)
+	assert.ErrorContains(t, err, `Relays: "relay.example.com" is not a relay url of the form https://<pubkey>@<host>`)
+	assert.ErrorContains(t, err, `MinBid: "-1" is not an amount of ETH`)
+
+	args := kubernetesArgs()
+	args.DeploymentType = "source"
+	assert.ErrorContains(t, args.Validate(), "Connection: required for source deployments")
+}
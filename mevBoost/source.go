@@ -0,0 +1,85 @@
+package mevBoost
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// sourceRepo is the repository and ref built when the args set none.
+var sourceRepo = utils.SourceRepo{Url: "https://github.com/flashbots/mev-boost.git", Ref: "stable"}
+
+// deploySource builds mev-boost on the remote host and runs it as a systemd service.
+func deploySource(ctx *pulumi.Context, component *MevBoostComponent, args *MevBoostComponentArgs) error {
+	prefix := resourcePrefix(args)
+	repoDir := fmt.Sprintf("/data/repos/%s/mev-boost", args.Network)
+
+	// clone repo
+	repo, err := remote.NewCommand(ctx, fmt.Sprintf("cloneRepo-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.String(utils.SourceRepo{Url: args.SourceRepoUrl, Ref: args.SourceRef}.WithDefaults(sourceRepo).CloneCommand(repoDir)),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error cloning repo", nil)
+		return err
+	}
+
+	installGo, err := remote.NewCommand(ctx, fmt.Sprintf("installGo-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.String("sudo apt update && sudo apt install -y golang-go build-essential"),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error installing go", nil)
+		return err
+	}
+
+	buildBoost, err := remote.NewCommand(ctx, fmt.Sprintf("buildMevBoost-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("cd %s && make build && mkdir -p /data/bin && mv mev-boost /data/bin/mev-boost && chown mev-boost:mev-boost /data/bin/mev-boost", repoDir),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{repo, installGo}))
+	if err != nil {
+		ctx.Log.Error("Error building mev-boost", nil)
+		return err
+	}
+
+	// copy start script
+	startScript, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyStartScript-%s", prefix), &remote.CopyFileArgs{
+		LocalPath:  pulumi.String("scripts/start_mev_boost.sh"),
+		RemotePath: pulumi.String("/data/scripts/start_mev_boost.sh"),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error copying start script", nil)
+		return err
+	}
+
+	// script permissions
+	scriptPerms, err := remote.NewCommand(ctx, fmt.Sprintf("scriptPermissions-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.String("chmod +x /data/scripts/start_mev_boost.sh && chown mev-boost:mev-boost /data/scripts/start_mev_boost.sh"),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{startScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting script permissions", nil)
+		return err
+	}
+
+	// create service, the start script reads its flags from the service environment
+	_, err = utils.NewServiceDefinitionComponent(ctx, fmt.Sprintf("mevBoostService-%s", prefix), &utils.ServiceComponentArgs{
+		Connection:  args.Connection,
+		ServiceType: "mev-boost",
+		Network:     args.Network,
+		Environment: pulumi.StringMap{
+			"MEV_BOOST_FLAGS": pulumi.String(strings.Join(Flags(args), " ")),
+		},
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{buildBoost, scriptPerms}))
+	if err != nil {
+		ctx.Log.Error("Error creating mev-boost service", nil)
+		return err
+	}
+
+	component.Url = pulumi.Sprintf("http://%s:%d", args.Connection.Host, port(args))
+	return nil
+}
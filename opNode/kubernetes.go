@@ -0,0 +1,223 @@
+package opNode
+
+import (
+	"fmt"
+
+	appsv1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/apps/v1"
+	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
+	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+const (
+	defaultImage = "us-docker.pkg.dev/oplabs-tools-artifacts/images/op-node:latest"
+	// dataMountPath and jwtMountPath are where the data volume and the
+	// execution jwt secret are mounted in the op-node container.
+	dataMountPath = "/data"
+	jwtMountPath  = "/secrets"
+)
+
+// deployKubernetes runs op-node as a StatefulSet with the execution jwt
+// secret, a data volume for its p2p state and its rpc, metrics and p2p services.
+func deployKubernetes(ctx *pulumi.Context, component *OpNodeComponent, args *OpNodeComponentArgs) error {
+	prefix := resourcePrefix(args)
+	image := args.Image
+	if image == "" {
+		image = defaultImage
+	}
+
+	_, err := corev1.NewPersistentVolumeClaim(ctx, fmt.Sprintf("%s-data", prefix), &corev1.PersistentVolumeClaimArgs{
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.Sprintf("%s-data", prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name":    pulumi.Sprintf("%s-data", prefix),
+				"app.kubernetes.io/part-of": pulumi.String("op-node"),
+			},
+		},
+		Spec: &corev1.PersistentVolumeClaimSpecArgs{
+			AccessModes: pulumi.StringArray{pulumi.String("ReadWriteOnce")},
+			Resources: &corev1.VolumeResourceRequirementsArgs{
+				Requests: pulumi.StringMap{
+					"storage": pulumi.String(args.PodStorageSize),
+				},
+			},
+			StorageClassName: pulumi.String(args.PodStorageClass),
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	// Create a secret for the execution jwt
+	secret, err := corev1.NewSecret(ctx, fmt.Sprintf("%s-execution-jwt", prefix), &corev1.SecretArgs{
+		StringData: pulumi.StringMap{
+			"jwt.hex": args.ExecutionJwt,
+		},
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.Sprintf("%s-execution-jwt", prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name": pulumi.Sprintf("%s-execution-jwt", prefix),
+			},
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	container := corev1.ContainerArgs{
+		Name:    pulumi.String(prefix),
+		Image:   pulumi.String(image),
+		Command: pulumi.ToStringArray(args.ContainerCommands),
+		Ports: corev1.ContainerPortArray{
+			corev1.ContainerPortArgs{
+				ContainerPort: pulumi.Int(P2pPort),
+			},
+			corev1.ContainerPortArgs{
+				ContainerPort: pulumi.Int(P2pPort),
+				Protocol:      pulumi.String("UDP"),
+			},
+			corev1.ContainerPortArgs{
+				ContainerPort: pulumi.Int(RpcPort),
+			},
+			corev1.ContainerPortArgs{
+				ContainerPort: pulumi.Int(MetricsPort),
+			},
+		},
+		VolumeMounts: corev1.VolumeMountArray{
+			corev1.VolumeMountArgs{
+				Name:      pulumi.Sprintf("%s-data", prefix),
+				MountPath: pulumi.String(dataMountPath),
+			},
+			corev1.VolumeMountArgs{
+				Name:      pulumi.Sprintf("%s-execution-jwt", prefix),
+				MountPath: pulumi.String(jwtMountPath),
+			},
+		},
+		Resources: &corev1.ResourceRequirementsArgs{
+			Limits: pulumi.StringMap{
+				"cpu":    pulumi.String(args.CpuLimit),
+				"memory": pulumi.String(args.MemoryLimit),
+			},
+			Requests: pulumi.StringMap{
+				"cpu":    pulumi.String(args.CpuRequest),
+				"memory": pulumi.String(args.MemoryRequest),
+			},
+		},
+	}
+	// without explicit commands the image is run with the default flags
+	if len(args.ContainerCommands) == 0 {
+		container.Command = pulumi.ToStringArray([]string{"op-node"})
+		container.Args = flags(args, dataMountPath, jwtMountPath+"/jwt.hex")
+	}
+
+	_, err = appsv1.NewStatefulSet(ctx, fmt.Sprintf("%s-set", prefix), &appsv1.StatefulSetArgs{
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.String(prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name":    pulumi.Sprintf("%s-set", prefix),
+				"app.kubernetes.io/part-of": pulumi.String("op-node"),
+			},
+		},
+		Spec: &appsv1.StatefulSetSpecArgs{
+			Replicas: pulumi.Int(1),
+			Selector: &metav1.LabelSelectorArgs{
+				MatchLabels: pulumi.StringMap{
+					"app": pulumi.String(prefix),
+				},
+			},
+			Template: &corev1.PodTemplateSpecArgs{
+				Metadata: &metav1.ObjectMetaArgs{
+					Labels: pulumi.StringMap{
+						"app":                       pulumi.String(prefix),
+						"app.kubernetes.io/name":    pulumi.String(prefix),
+						"app.kubernetes.io/part-of": pulumi.String("op-node"),
+					},
+				},
+				Spec: &corev1.PodSpecArgs{
+					Containers: corev1.ContainerArray{container},
+					DnsPolicy:  pulumi.String("ClusterFirst"),
+					Volumes: corev1.VolumeArray{
+						corev1.VolumeArgs{
+							Name: pulumi.Sprintf("%s-data", prefix),
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSourceArgs{
+								ClaimName: pulumi.Sprintf("%s-data", prefix),
+							},
+						},
+						corev1.VolumeArgs{
+							Name: pulumi.Sprintf("%s-execution-jwt", prefix),
+							Secret: &corev1.SecretVolumeSourceArgs{
+								SecretName: secret.Metadata.Name(),
+							},
+						},
+					},
+				},
+			},
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	// Create ingress for p2p traffic
+	p2pService, err := corev1.NewService(ctx, fmt.Sprintf("%s-p2p-service", prefix), &corev1.ServiceArgs{
+		Spec: &corev1.ServiceSpecArgs{
+			Selector: pulumi.StringMap{"app": pulumi.String(prefix)},
+			Type:     pulumi.String("NodePort"),
+			Ports: corev1.ServicePortArray{
+				corev1.ServicePortArgs{
+					Port: pulumi.Int(P2pPort),
+					Name: pulumi.String("p2p-tcp"),
+				},
+				corev1.ServicePortArgs{
+					Port:     pulumi.Int(P2pPort),
+					Protocol: pulumi.String("UDP"),
+					Name:     pulumi.String("p2p-udp"),
+				},
+			},
+		},
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.Sprintf("%s-p2p-service", prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name":    pulumi.Sprintf("%s-p2p-service", prefix),
+				"app.kubernetes.io/part-of": pulumi.String("op-node"),
+			},
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	// create the rpc and metrics service
+	rpcService, err := corev1.NewService(ctx, fmt.Sprintf("%s-rpc-service", prefix), &corev1.ServiceArgs{
+		Spec: &corev1.ServiceSpecArgs{
+			Selector: pulumi.StringMap{"app": pulumi.String(prefix)},
+			Type:     pulumi.String("ClusterIP"),
+			Ports: corev1.ServicePortArray{
+				corev1.ServicePortArgs{
+					Port: pulumi.Int(RpcPort),
+					Name: pulumi.String("rpc"),
+				},
+				corev1.ServicePortArgs{
+					Port: pulumi.Int(MetricsPort),
+					Name: pulumi.String("metrics"),
+				},
+			},
+		},
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.Sprintf("%s-rpc-service", prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name":    pulumi.Sprintf("%s-rpc-service", prefix),
+				"app.kubernetes.io/part-of": pulumi.String("op-node"),
+			},
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	component.RpcUrl = pulumi.Sprintf("http://%s:%d", utils.ClusterServiceHost(rpcService), RpcPort)
+	component.MetricsUrl = pulumi.Sprintf("http://%s:%d", utils.ClusterServiceHost(rpcService), MetricsPort)
+	component.P2pNodePort = p2pService.Spec.Ports().Index(pulumi.Int(0)).NodePort().Elem()
+	return nil
+}
@@ -0,0 +1,252 @@
+package opNode
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+type OpNodeComponent struct {
+	pulumi.ResourceState
+
+	// RpcUrl is the op-node rollup RPC endpoint, e.g. for optimism_syncStatus.
+	RpcUrl pulumi.StringOutput `pulumi:"rpcUrl"`
+	// MetricsUrl is the prometheus metrics endpoint.
+	MetricsUrl pulumi.StringOutput `pulumi:"metricsUrl"`
+	// P2pNodePort is the NodePort of the p2p service, or the p2p port on the host for source deployments.
+	P2pNodePort pulumi.IntOutput `pulumi:"p2pNodePort"`
+}
+
+type OpNodeComponentArgs struct {
+	Connection     *remote.ConnectionArgs
+	Network        string
+	DeploymentType string
+	DataDir        string
+	Name           string
+	// L1RpcUrl and L1BeaconUrl are the execution and beacon api of an L1 node
+	// on the network the rollup settles to, mainnet for base and optimism and
+	// sepolia for their testnets.
+	L1RpcUrl    pulumi.StringInput
+	L1BeaconUrl pulumi.StringInput
+	// L1RpcKind tells op-node which receipts api the L1 rpc offers, basic by default.
+	L1RpcKind string
+	// L2EngineUrl is the engine api of the L2 execution client, e.g. the
+	// EngineApiUrl of an op-reth execution client.
+	L2EngineUrl pulumi.StringInput
+	// L2EngineKind is the L2 execution client, reth by default.
+	L2EngineKind string
+	// ExecutionJwt is the engine api jwt shared with the L2 execution client.
+	ExecutionJwt      pulumi.StringInput
+	Image             string
+	ContainerCommands []string
+	PodStorageClass   string
+	PodStorageSize    string
+	CpuLimit          string
+	MemoryLimit       string
+	CpuRequest        string
+	MemoryRequest     string
+	// SourceRepoUrl and SourceRef select the repository and the branch, tag or
+	// commit source deployments are built from, ethereum-optimism/optimism's
+	// develop branch by default.
+	SourceRepoUrl string
+	SourceRef     string
+}
+
+const (
+	Source          = "source"
+	Kubernetes      = "kubernetes"
+	Base            = "base"
+	BaseSepolia     = "base-sepolia"
+	Optimism        = "optimism"
+	OptimismSepolia = "optimism-sepolia"
+	RpcPort         = 9545
+	MetricsPort     = 7300
+	P2pPort         = 9222
+)
+
+// registryNetworks maps the networks to their names in the superchain
+// registry, op-node loads the rollup config of the network from it.
+var registryNetworks = map[string]string{
+	Base:            "base-mainnet",
+	BaseSepolia:     "base-sepolia",
+	Optimism:        "op-mainnet",
+	OptimismSepolia: "op-sepolia",
+}
+
+var (
+	networks        = []string{Base, BaseSepolia, Optimism, OptimismSepolia}
+	deploymentTypes = []string{Source, Kubernetes}
+	engineKinds     = []string{"reth", "geth", "erigon"}
+)
+
+// L1Network returns the L1 network the rollup network settles to.
+func L1Network(network string) string {
+	if strings.HasSuffix(network, "-sepolia") {
+		return "sepolia"
+	}
+	return "mainnet"
+}
+
+// Validate checks the args for unknown networks and deployment types as well
+// as the L1 and L2 endpoints and settings required by the chosen deployment
+// type. All problems found are returned together as utils.ValidationErrors.
+func (args *OpNodeComponentArgs) Validate() error {
+	var errs utils.ValidationErrors
+
+	if !slices.Contains(networks, args.Network) {
+		errs.Add("Network", "unknown network %q, expected one of %s", args.Network, strings.Join(networks, ", "))
+	}
+	if !slices.Contains(deploymentTypes, args.DeploymentType) {
+		errs.Add("DeploymentType", "unknown deployment type %q, expected one of %s", args.DeploymentType, strings.Join(deploymentTypes, ", "))
+	}
+	if args.L1RpcUrl == nil {
+		errs.Add("L1RpcUrl", "required")
+	}
+	if args.L1BeaconUrl == nil {
+		errs.Add("L1BeaconUrl", "required")
+	}
+	if args.L2EngineUrl == nil {
+		errs.Add("L2EngineUrl", "required")
+	}
+	if args.L2EngineKind != "" && !slices.Contains(engineKinds, args.L2EngineKind) {
+		errs.Add("L2EngineKind", "unknown engine kind %q, expected one of %s", args.L2EngineKind, strings.Join(engineKinds, ", "))
+	}
+
+	switch args.DeploymentType {
+	case Source:
+		if args.Connection == nil {
+			errs.Add("Connection", "required for %s deployments", args.DeploymentType)
+		}
+	case Kubernetes:
+		if args.ExecutionJwt == nil {
+			errs.Add("ExecutionJwt", "required for %s deployments", args.DeploymentType)
+		}
+		if args.CpuLimit == "" {
+			errs.Add("CpuLimit", "required for %s deployments", args.DeploymentType)
+		}
+		if args.MemoryLimit == "" {
+			errs.Add("MemoryLimit", "required for %s deployments", args.DeploymentType)
+		}
+	}
+
+	return errs.Err()
+}
+
+// NewOpNodeComponent runs op-node, the rollup node deriving the L2 chain of an
+// OP Stack network from its L1, and drives the L2 execution client through the
+// engine api. The rollup config is taken from the superchain registry for the
+// network. Source deployments build op-node on the ssh host and run it as a
+// systemd service, kubernetes deployments run it as a StatefulSet. The args
+// are validated before any resources are registered.
+//
+// Example usage:
+//
+//	rollupNode, err := opNode.NewOpNodeComponent(ctx, "baseOpNode", &opNode.OpNodeComponentArgs{
+//		Network:        "base",
+//		DeploymentType: "kubernetes",
+//		L1RpcUrl:       l1.ExecutionClient.HttpRpcUrl,
+//		L1BeaconUrl:    l1.ConsensusClient.BeaconApiUrl,
+//		L2EngineUrl:    opReth.EngineApiUrl,
+//		ExecutionJwt:   jwt,
+//		CpuLimit:       "2",
+//		MemoryLimit:    "4Gi",
+//	})
+func NewOpNodeComponent(ctx *pulumi.Context, name string, args *OpNodeComponentArgs, opts ...pulumi.ResourceOption) (*OpNodeComponent, error) {
+	if args == nil {
+		args = &OpNodeComponentArgs{}
+	}
+
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+
+	component := &OpNodeComponent{}
+	err := ctx.RegisterComponentResource(fmt.Sprintf("custom:component:OpNode:%s", args.Network), name, component, opts...)
+	if err != nil {
+		return nil, err
+	}
+	component.RpcUrl = pulumi.String("").ToStringOutput()
+	component.MetricsUrl = pulumi.String("").ToStringOutput()
+	component.P2pNodePort = pulumi.Int(0).ToIntOutput()
+
+	switch args.DeploymentType {
+	case Source:
+		err = deploySource(ctx, component, args)
+	case Kubernetes:
+		err = deployKubernetes(ctx, component, args)
+	}
+	if err != nil {
+		ctx.Log.Error("Error creating op-node", nil)
+		return nil, err
+	}
+
+	if err := ctx.RegisterResourceOutputs(component, pulumi.Map{
+		"rpcUrl":      component.RpcUrl,
+		"metricsUrl":  component.MetricsUrl,
+		"p2pNodePort": component.P2pNodePort,
+	}); err != nil {
+		return nil, err
+	}
+
+	return component, nil
+}
+
+// OpNodeEndpoints holds the endpoints op-node connects to.
+type OpNodeEndpoints struct {
+	L1Rpc    string
+	L1Beacon string
+	L2Engine string
+}
+
+// Flags renders the op-node arguments for args, keeping p2p state in dataDir
+// and reading the engine api jwt from jwtPath.
+func Flags(args *OpNodeComponentArgs, endpoints OpNodeEndpoints, dataDir string, jwtPath string) []string {
+	rpcKind, engineKind := args.L1RpcKind, args.L2EngineKind
+	if rpcKind == "" {
+		rpcKind = "basic"
+	}
+	if engineKind == "" {
+		engineKind = "reth"
+	}
+	return []string{
+		"--network=" + registryNetworks[args.Network],
+		"--syncmode=execution-layer",
+		"--l1=" + endpoints.L1Rpc,
+		"--l1.beacon=" + endpoints.L1Beacon,
+		"--l1.rpckind=" + rpcKind,
+		"--l2=" + endpoints.L2Engine,
+		"--l2.jwt-secret=" + jwtPath,
+		"--l2.enginekind=" + engineKind,
+		"--rpc.addr=0.0.0.0",
+		"--rpc.port=" + strconv.Itoa(RpcPort),
+		"--p2p.listen.tcp=" + strconv.Itoa(P2pPort),
+		"--p2p.listen.udp=" + strconv.Itoa(P2pPort),
+		"--p2p.priv.path=" + path.Join(dataDir, "opnode_p2p_priv.txt"),
+		"--p2p.peerstore.path=" + path.Join(dataDir, "opnode_peerstore_db"),
+		"--p2p.discovery.path=" + path.Join(dataDir, "opnode_discovery_db"),
+		"--metrics.enabled",
+		"--metrics.addr=0.0.0.0",
+		"--metrics.port=" + strconv.Itoa(MetricsPort),
+	}
+}
+
+// flags resolves the endpoints of args and renders the op-node arguments.
+func flags(args *OpNodeComponentArgs, dataDir string, jwtPath string) pulumi.StringArrayOutput {
+	return pulumi.All(args.L1RpcUrl, args.L1BeaconUrl, args.L2EngineUrl).ApplyT(func(urls []interface{}) []string {
+		return Flags(args, OpNodeEndpoints{L1Rpc: urls[0].(string), L1Beacon: urls[1].(string), L2Engine: urls[2].(string)}, dataDir, jwtPath)
+	}).(pulumi.StringArrayOutput)
+}
+
+// resourcePrefix returns the prefix of the kubernetes objects and remote resources.
+func resourcePrefix(args *OpNodeComponentArgs) string {
+	if args.Name != "" {
+		return args.Name
+	}
+	return "op-node"
+}
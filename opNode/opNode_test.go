@@ -0,0 +1,145 @@
+package opNode_test
+
+import (
+	"testing"
+
+	"github.com/rswanson/node_deployer/internal/testutil"
+	"github.com/rswanson/node_deployer/opNode"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+)
+
+// kubernetesArgs returns valid args to deploy op-node for base to kubernetes.
+func kubernetesArgs() *opNode.OpNodeComponentArgs {
+	return &opNode.OpNodeComponentArgs{
+		Network:        "base",
+		DeploymentType: "kubernetes",
+		L1RpcUrl:       pulumi.String("http://reth-rpc:8545"),
+		L1BeaconUrl:    pulumi.String("http://lighthouse-beacon:5052"),
+		L2EngineUrl:    pulumi.String("http://op-reth-internal:8551"),
+		ExecutionJwt:   pulumi.String("testJwt"),
+		CpuLimit:       "2",
+		MemoryLimit:    "4Gi",
+	}
+}
+
+func TestOpNodeComponent(t *testing.T) {
+	t.Run("KubernetesComponent", func(t *testing.T) {
+		m := testutil.NewRecordingMocks()
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			rollupNode, err := opNode.NewOpNodeComponent(ctx, "testOpNode", kubernetesArgs())
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			done := make(chan struct{})
+			pulumi.All(rollupNode.RpcUrl, rollupNode.MetricsUrl).ApplyT(func(urls []interface{}) error {
+				assert.Equal(t, "http://op-node-rpc-service.default.svc.cluster.local:9545", urls[0])
+				assert.Equal(t, "http://op-node-rpc-service.default.svc.cluster.local:7300", urls[1])
+				close(done)
+				return nil
+			})
+			<-done
+
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		// the engine api jwt is mounted from its secret next to the data volume
+		jwt := testutil.UnwrapSecret(testutil.UnwrapSecret(m.Inputs["op-node-execution-jwt"]["stringData"]).ObjectValue()["jwt.hex"])
+		assert.Equal(t, "testJwt", jwt.StringValue())
+		container := testutil.Containers(testutil.PodSpec(m.Inputs["op-node-set"]))[0]
+		assert.Equal(t, []string{"op-node-data", "op-node-execution-jwt"}, testutil.Names(testutil.VolumeMounts(container)))
+		assert.Equal(t, []string{"/data", "/secrets"}, testutil.MountPaths(container))
+
+		// the image runs op-node against the configured l1 and l2 endpoints
+		assert.Equal(t, "op-node", container["command"].ArrayValue()[0].StringValue())
+		assert.Subset(t, testutil.Flags(container), []string{
+			"--network=base-mainnet",
+			"--l1=http://reth-rpc:8545",
+			"--l1.beacon=http://lighthouse-beacon:5052",
+			"--l2=http://op-reth-internal:8551",
+			"--l2.jwt-secret=/secrets/jwt.hex",
+			"--p2p.priv.path=/data/opnode_p2p_priv.txt",
+		})
+	})
+
+	t.Run("SourceComponent", func(t *testing.T) {
+		m := testutil.NewRecordingMocks()
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			args := kubernetesArgs()
+			args.Network = "optimism-sepolia"
+			args.DeploymentType = "source"
+			args.Connection = &remote.ConnectionArgs{Host: pulumi.String("127.0.0.1")}
+
+			rollupNode, err := opNode.NewOpNodeComponent(ctx, "testOpNode", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			done := make(chan struct{})
+			pulumi.All(rollupNode.RpcUrl, rollupNode.P2pNodePort).ApplyT(func(outputs []interface{}) error {
+				assert.Equal(t, "http://127.0.0.1:9545", outputs[0])
+				assert.Equal(t, 9222, outputs[1])
+				close(done)
+				return nil
+			})
+			<-done
+
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		// the jwt is written to the shared path the start script's flags point at
+		assert.Contains(t, m.Inputs["writeJwtSecret-op-node"]["create"].StringValue(), "/data/shared/jwt.hex")
+		environment := m.Inputs["serviceEnvironment-opNodeService-op-node"]["stdin"].StringValue()
+		assert.Contains(t, environment, "--network=op-sepolia")
+		assert.Contains(t, environment, "--l2=http://op-reth-internal:8551")
+		assert.Contains(t, environment, "--l2.jwt-secret=/data/shared/jwt.hex")
+		assert.Contains(t, environment, "--p2p.priv.path=/data/optimism-sepolia/op-node/opnode_p2p_priv.txt")
+	})
+}
+
+func TestFlags(t *testing.T) {
+	endpoints := opNode.OpNodeEndpoints{L1Rpc: "http://l1:8545", L1Beacon: "http://l1:5052", L2Engine: "http://l2:8551"}
+	flags := opNode.Flags(kubernetesArgs(), endpoints, "/data", "/secrets/jwt.hex")
+	assert.Subset(t, flags, []string{
+		"--network=base-mainnet",
+		"--l1=http://l1:8545",
+		"--l1.beacon=http://l1:5052",
+		"--l1.rpckind=basic",
+		"--l2=http://l2:8551",
+		"--l2.jwt-secret=/secrets/jwt.hex",
+		"--l2.enginekind=reth",
+		"--p2p.priv.path=/data/opnode_p2p_priv.txt",
+	})
+
+	args := kubernetesArgs()
+	args.Network = "optimism-sepolia"
+	args.L2EngineKind = "geth"
+	assert.Subset(t, opNode.Flags(args, endpoints, "/data", "/secrets/jwt.hex"), []string{"--network=op-sepolia", "--l2.enginekind=geth"})
+	assert.Equal(t, "sepolia", opNode.L1Network(args.Network))
+	assert.Equal(t, "mainnet", opNode.L1Network("base"))
+}
+
+func TestOpNodeComponentArgsValidate(t *testing.T) {
+	assert.NoError(t, kubernetesArgs().Validate(), "Expected valid args to pass validation")
+
+	err := (&opNode.OpNodeComponentArgs{
+		Network:        "testNetwork",
+		DeploymentType: "testDeploymentType",
+		L2EngineKind:   "testEngine",
+	}).Validate()
+	assert.ErrorContains(t, err, `Network: unknown network "testNetwork"`)
+	assert.ErrorContains(t, err, `DeploymentType: unknown deployment type "testDeploymentType"`)
+	assert.ErrorContains(t, err, "L1RpcUrl: required")
+	assert.ErrorContains(t, err, "L1BeaconUrl: required")
+	assert.ErrorContains(t, err, "L2EngineUrl: required")
+	assert.ErrorContains(t, err, `L2EngineKind: unknown engine kind "testEngine"`)
+
+	args := kubernetesArgs()
+	args.ExecutionJwt = nil
+	assert.ErrorContains(t, args.Validate(), "ExecutionJwt: required for kubernetes deployments")
+
+	args = kubernetesArgs()
+	args.DeploymentType = "source"
+	assert.ErrorContains(t, args.Validate(), "Connection: required for source deployments")
+}
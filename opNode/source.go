@@ -0,0 +1,115 @@
+package opNode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// sourceRepo is the repository and ref built when the args set none.
+var sourceRepo = utils.SourceRepo{Url: "https://github.com/ethereum-optimism/optimism.git", Ref: "develop"}
+
+// deploySource builds op-node on the remote host and runs it as a systemd
+// service sharing the engine api jwt with the L2 execution client.
+func deploySource(ctx *pulumi.Context, component *OpNodeComponent, args *OpNodeComponentArgs) error {
+	prefix := resourcePrefix(args)
+	repoDir := fmt.Sprintf("/data/repos/%s/optimism", args.Network)
+	dataDir := args.DataDir
+	if dataDir == "" {
+		dataDir = fmt.Sprintf("/data/%s/op-node", args.Network)
+	}
+
+	dataDirs, err := remote.NewCommand(ctx, fmt.Sprintf("createDataDir-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("mkdir -p %s", dataDir),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error creating data directory", nil)
+		return err
+	}
+
+	// clone repo
+	repo, err := remote.NewCommand(ctx, fmt.Sprintf("cloneRepo-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.String(utils.SourceRepo{Url: args.SourceRepoUrl, Ref: args.SourceRef}.WithDefaults(sourceRepo).CloneCommand(repoDir)),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error cloning repo", nil)
+		return err
+	}
+
+	installGo, err := remote.NewCommand(ctx, fmt.Sprintf("installGo-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.String("sudo apt update && sudo apt install -y golang-go build-essential"),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error installing go", nil)
+		return err
+	}
+
+	buildOpNode, err := remote.NewCommand(ctx, fmt.Sprintf("buildOpNode-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("cd %s/op-node && make op-node && mkdir -p /data/bin && cp bin/op-node /data/bin/op-node && chown op-node:op-node /data/bin/op-node", repoDir),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{repo, installGo}))
+	if err != nil {
+		ctx.Log.Error("Error building op-node", nil)
+		return err
+	}
+
+	// copy start script
+	startScript, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyStartScript-%s", prefix), &remote.CopyFileArgs{
+		LocalPath:  pulumi.String("scripts/start_op_node.sh"),
+		RemotePath: pulumi.String("/data/scripts/start_op_node.sh"),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error copying start script", nil)
+		return err
+	}
+
+	// script permissions
+	scriptPerms, err := remote.NewCommand(ctx, fmt.Sprintf("scriptPermissions-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chmod +x /data/scripts/start_op_node.sh && chown op-node:op-node /data/scripts/start_op_node.sh && chown -R op-node:op-node %s", dataDir),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{startScript, dataDirs}))
+	if err != nil {
+		ctx.Log.Error("Error setting script permissions", nil)
+		return err
+	}
+
+	// write the shared engine api jwt, without one the secret is expected to be provisioned already
+	serviceDeps := []pulumi.Resource{buildOpNode, scriptPerms}
+	if args.ExecutionJwt != nil {
+		jwtSecret, err := utils.NewJwtSecretFile(ctx, fmt.Sprintf("writeJwtSecret-%s", prefix), args.Connection, args.ExecutionJwt, pulumi.Parent(component))
+		if err != nil {
+			ctx.Log.Error("Error writing jwt secret", nil)
+			return err
+		}
+		serviceDeps = append(serviceDeps, jwtSecret)
+	}
+
+	// create service, the start script reads its flags from the service environment
+	_, err = utils.NewServiceDefinitionComponent(ctx, fmt.Sprintf("opNodeService-%s", prefix), &utils.ServiceComponentArgs{
+		Connection:  args.Connection,
+		ServiceType: "op-node",
+		Network:     args.Network,
+		Environment: pulumi.StringMap{
+			"OP_NODE_FLAGS": flags(args, dataDir, utils.JwtSecretPath).ApplyT(func(flags []string) string {
+				return strings.Join(flags, " ")
+			}).(pulumi.StringOutput),
+		},
+	}, pulumi.Parent(component), pulumi.DependsOn(serviceDeps))
+	if err != nil {
+		ctx.Log.Error("Error creating op-node service", nil)
+		return err
+	}
+
+	host := args.Connection.Host
+	component.RpcUrl = pulumi.Sprintf("http://%s:%d", host, RpcPort)
+	component.MetricsUrl = pulumi.Sprintf("http://%s:%d", host, MetricsPort)
+	component.P2pNodePort = pulumi.Int(P2pPort).ToIntOutput()
+	return nil
+}
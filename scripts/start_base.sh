@@ -10,6 +10,7 @@ op-reth node \
     --chain base \
     --rollup.sequencer-http https://sequencer.base.org \
     --http \
+    --http.api eth,net,web3,admin \
     --ws \
     --authrpc.port 9551 \
     --authrpc.jwtsecret /data/shared/jwt.hex \
@@ -0,0 +1,19 @@
+#!/bin/bash
+
+# Environment variables, the service drop-in sets them for the deployed network
+NETWORK="${NETWORK:-mainnet}"
+DATA_DIR="${DATA_DIR:-/data/${NETWORK}/besu}"
+STORAGE_FORMAT="${STORAGE_FORMAT:-BONSAI}"
+JWT_SECRET_FILE="${JWT_SECRET_FILE:-/data/shared/jwt.hex}"
+
+# Start besu, the rpc only listens on localhost so it serves the admin api
+# the enode is read with
+/data/repos/besu/build/install/besu/bin/besu \
+  --network=$NETWORK \
+  --data-path=$DATA_DIR \
+  --data-storage-format=$STORAGE_FORMAT \
+  --engine-jwt-secret=$JWT_SECRET_FILE \
+  --rpc-http-enabled \
+  --rpc-http-api=ETH,NET,WEB3,ADMIN \
+  --rpc-ws-enabled \
+  --metrics-enabled
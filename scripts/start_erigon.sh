@@ -0,0 +1,20 @@
+#!/bin/bash
+
+# Environment variables, the service drop-in sets them for the deployed network
+DATA_DIR="${DATA_DIR:-/data/mainnet/erigon}"
+JWT_SECRET_FILE="${JWT_SECRET_FILE:-/data/shared/jwt.hex}"
+ERIGON_FLAGS="${ERIGON_FLAGS:---chain=mainnet --externalcl}"
+METRICS_PORT=6060
+TORRENT_PORT=42069
+
+# Start erigon
+/usr/local/bin/erigon \
+  --datadir $DATA_DIR \
+  --authrpc.jwtsecret $JWT_SECRET_FILE \
+  --http \
+  --ws \
+  --metrics \
+  --metrics.addr 127.0.0.1 \
+  --metrics.port $METRICS_PORT \
+  --torrent.port $TORRENT_PORT \
+  $ERIGON_FLAGS
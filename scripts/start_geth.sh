@@ -1,11 +1,13 @@
 #!/bin/bash
 
-METRICS_ENDPOINT=http://127.0.0.1:8551
-METRICS_PORT=6060
+METRICS_ADDR=127.0.0.1
+METRICS_PORT=9001
 METRICS_ENABLED="true"
 DATA_DIR="/data/mainnet/geth/data"
 GETH_MAINNET=true
 GETH_HOLESKY=false
+JWT_SECRET_FILE="${JWT_SECRET_FILE:-/data/shared/jwt.hex}"
 
-# Start geth
-/usr/local/bin/geth --datadir $DATA_DIR --metrics=$METRICS_ENABLED --metrics.addr $METRICS_ENDPOINT --metrics.port $METRICS_PORT 
\ No newline at end of file
+# Start geth, the rpc only listens on localhost so it serves the admin api
+# the enode is read with
+/usr/local/bin/geth --datadir $DATA_DIR --metrics=$METRICS_ENABLED --metrics.addr $METRICS_ADDR --metrics.port $METRICS_PORT --authrpc.jwtsecret $JWT_SECRET_FILE --http --http.api eth,net,web3,admin 
\ No newline at end of file
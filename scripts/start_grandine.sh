@@ -0,0 +1,18 @@
+#!/bin/bash
+
+# Environment variables
+NETWORK="mainnet"
+DATA_DIR="/data/${NETWORK}/grandine"
+EXECUTION_ENDPOINT="${EXECUTION_ENDPOINT:-http://localhost:8551}"
+JWT_SECRET_FILE="${JWT_SECRET_FILE:-/data/shared/jwt.hex}"
+BUILDER_FLAGS="${BUILDER_FLAGS:-}"
+
+# Start grandine
+/data/bin/grandine \
+  --network $NETWORK \
+  --data-dir $DATA_DIR \
+  --metrics \
+  --eth1-rpc-urls $EXECUTION_ENDPOINT \
+  --jwt-secret $JWT_SECRET_FILE \
+  --checkpoint-sync-url https://mainnet.checkpoint.sigp.io \
+  $BUILDER_FLAGS
@@ -3,11 +3,17 @@
 # Environment variables
 NETWORK="mainnet"
 DATA_DIR="/data/${NETWORK}/lighthouse"
+EXECUTION_ENDPOINT="${EXECUTION_ENDPOINT:-http://localhost:8551}"
+JWT_SECRET_FILE="${JWT_SECRET_FILE:-/data/shared/jwt.hex}"
+BUILDER_FLAGS="${BUILDER_FLAGS:-}"
 # Start lighthouse
 lighthouse bn \
   --network $NETWORK \
   --datadir $DATA_DIR \
   --http \
   --metrics \
+  --execution-endpoint $EXECUTION_ENDPOINT \
+  --execution-jwt $JWT_SECRET_FILE \
   --disable-deposit-contract-sync \
-  --checkpoint-sync-url https://mainnet.checkpoint.sigp.io 
\ No newline at end of file
+  --checkpoint-sync-url https://mainnet.checkpoint.sigp.io \
+  $BUILDER_FLAGS 
\ No newline at end of file
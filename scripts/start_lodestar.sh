@@ -3,10 +3,16 @@
 # Environment variables
 NETWORK="mainnet"
 DATA_DIR="/data/${NETWORK}/lodestar"
+EXECUTION_ENDPOINT="${EXECUTION_ENDPOINT:-http://localhost:8551}"
+JWT_SECRET_FILE="${JWT_SECRET_FILE:-/data/shared/jwt.hex}"
+BUILDER_FLAGS="${BUILDER_FLAGS:-}"
 # Start lodestar
 lodestar \
   --network $NETWORK \
   --datadir $DATA_DIR \
   --http \
   --metrics \
-  --disable-deposit-contract-sync 
+  --execution.urls $EXECUTION_ENDPOINT \
+  --jwt-secret $JWT_SECRET_FILE \
+  --disable-deposit-contract-sync \
+  $BUILDER_FLAGS 
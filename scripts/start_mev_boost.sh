@@ -0,0 +1,7 @@
+#!/bin/bash
+
+# Environment variables, the service drop-in sets them for the deployed network
+MEV_BOOST_FLAGS="${MEV_BOOST_FLAGS:--mainnet -addr 127.0.0.1:18550 -relay-check}"
+
+# Start mev-boost
+/data/bin/mev-boost $MEV_BOOST_FLAGS
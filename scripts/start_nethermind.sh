@@ -4,12 +4,16 @@
 NETWORK="mainnet"
 DATA_DIR="/data/${NETWORK}/nethermind"
 METRICS_ENABLED="true"
+JWT_SECRET_FILE="${JWT_SECRET_FILE:-/data/shared/jwt.hex}"
 
-# Start nethermind
+# Start nethermind, the rpc only listens on localhost so it serves the admin
+# api the enode is read with
 nethermind \
   --config $NETWORK \
   --baseDbPath $DATA_DIR \
   --JsonRpc.Enabled true \
   --JsonRpc.Host 127.0.0.1 \
   --JsonRpc.Port 8545 \
+  --JsonRpc.EnabledModules "[Eth,Subscribe,Trace,TxPool,Web3,Personal,Proof,Net,Parity,Health,Rpc,Admin]" \
+  --JsonRpc.JwtSecretFile $JWT_SECRET_FILE \
   --metrics $METRICS_ENABLED
\ No newline at end of file
@@ -4,9 +4,15 @@
 NETWORK="mainnet"
 DATA_DIR="/data/${NETWORK}/nimbus"
 METRICS_ENABLED="true"
+EXECUTION_ENDPOINT="${EXECUTION_ENDPOINT:-http://localhost:8551}"
+JWT_SECRET_FILE="${JWT_SECRET_FILE:-/data/shared/jwt.hex}"
+BUILDER_FLAGS="${BUILDER_FLAGS:-}"
 
 # Start nimbus
 /data/repos/nimbus2-eth/build/nimbus_beacon_node \
   --network $NETWORK \
   --data-dir $DATA_DIR \
-  --metrics $METRICS_ENABLED
\ No newline at end of file
+  --el=$EXECUTION_ENDPOINT \
+  --jwt-secret=$JWT_SECRET_FILE \
+  --metrics $METRICS_ENABLED \
+  $BUILDER_FLAGS
\ No newline at end of file
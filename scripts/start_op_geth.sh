@@ -0,0 +1,7 @@
+#!/bin/bash
+
+# Environment variables, the service drop-in sets them for the deployed network
+OP_GETH_FLAGS="${OP_GETH_FLAGS:?OP_GETH_FLAGS is not set}"
+
+# Start op-geth
+/usr/local/bin/op-geth $OP_GETH_FLAGS
\ No newline at end of file
@@ -0,0 +1,7 @@
+#!/bin/bash
+
+# Environment variables, the service drop-in sets them for the deployed network
+OP_NODE_FLAGS="${OP_NODE_FLAGS:?OP_NODE_FLAGS is not set}"
+
+# Start op-node
+/data/bin/op-node $OP_NODE_FLAGS
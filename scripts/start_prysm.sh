@@ -4,6 +4,9 @@
 NETWORK="mainnet"
 DATA_DIR="/data/${NETWORK}/prysm"
 METRICS_ENABLED="true"
+EXECUTION_ENDPOINT="${EXECUTION_ENDPOINT:-http://localhost:8551}"
+JWT_SECRET_FILE="${JWT_SECRET_FILE:-/data/shared/jwt.hex}"
+BUILDER_FLAGS="${BUILDER_FLAGS:-}"
 
 # Start prysm
-./prysm.sh beacon-chain --execution-endpoint=http://localhost:8551 --mainnet --jwt-secret=/data/shared/jwt.hex --checkpoint-sync-url=https://beaconstate.info --genesis-beacon-api-url=https://beaconstate.info
\ No newline at end of file
+./prysm.sh beacon-chain --execution-endpoint=$EXECUTION_ENDPOINT --mainnet --jwt-secret=$JWT_SECRET_FILE --checkpoint-sync-url=https://beaconstate.info --genesis-beacon-api-url=https://beaconstate.info $BUILDER_FLAGS
\ No newline at end of file
@@ -4,4 +4,5 @@
 INSTANCE_ID="1"
 RETH_DATA_DIR="/data/${INSTANCE_ID}/reth"
 
-RUST_LOG=info /data/bin/reth node --instance $INSTANCE_ID --datadir $RETH_DATA_DIR --authrpc.jwtsecret /data/shared/jwt.hex
\ No newline at end of file
+# the rpc only listens on localhost so it serves the admin api the enode is read with
+RUST_LOG=info /data/bin/reth node --instance $INSTANCE_ID --datadir $RETH_DATA_DIR --authrpc.jwtsecret /data/shared/jwt.hex --http --http.api eth,net,web3,admin
\ No newline at end of file
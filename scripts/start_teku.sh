@@ -1,11 +1,12 @@
 #!/bin/bash
 
-EE_ENDPOINT=http://127.0.0.1:8551
-EE_JWT_SECRET_FILE="/data/shared/jwt.hex"
+EE_ENDPOINT="${EXECUTION_ENDPOINT:-http://127.0.0.1:8551}"
+EE_JWT_SECRET_FILE="${JWT_SECRET_FILE:-/data/shared/jwt.hex}"
+BUILDER_FLAGS="${BUILDER_FLAGS:-}"
 METRICS_ENABLED="true"
 REST_API_ENABLED="true"
 DATA_DIR="/data/mainnet/teku/data"
 CHECKPOINT_SYNC_URL="https://beaconstate.ethstaker.cc"
 
 # Start Teku
-/data/repos/teku/build/install/teku/bin/teku --ee-endpoint="${EE_ENDPOINT}" --ee-jwt-secret-file="$EE_JWT_SECRET_FILE" --metrics-enabled=$METRICS_ENABLED --rest-api-enabled=$REST_API_ENABLED --checkpoint-sync-url="$CHECKPOINT_SYNC_URL" --data-path=$DATA_DIR
\ No newline at end of file
+/data/repos/teku/build/install/teku/bin/teku --ee-endpoint="${EE_ENDPOINT}" --ee-jwt-secret-file="$EE_JWT_SECRET_FILE" --metrics-enabled=$METRICS_ENABLED --rest-api-enabled=$REST_API_ENABLED --checkpoint-sync-url="$CHECKPOINT_SYNC_URL" --data-path=$DATA_DIR $BUILDER_FLAGS
\ No newline at end of file
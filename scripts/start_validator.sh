@@ -0,0 +1,11 @@
+#!/bin/bash
+
+# Environment variables, the service drop-in sets them for the deployed client
+VALIDATOR_COMMAND="${VALIDATOR_COMMAND:?VALIDATOR_COMMAND is not set}"
+GRAFFITI_FLAG="${GRAFFITI_FLAG:---graffiti}"
+
+# Start the validator client, the graffiti may contain spaces
+if [ -n "$GRAFFITI" ]; then
+  exec $VALIDATOR_COMMAND "$GRAFFITI_FLAG=$GRAFFITI"
+fi
+exec $VALIDATOR_COMMAND
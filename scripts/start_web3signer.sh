@@ -0,0 +1,10 @@
+#!/bin/bash
+
+# Environment variables, the service drop-in sets them for the deployed network
+WEB3SIGNER_BINARY="${WEB3SIGNER_BINARY:-/data/repos/mainnet/web3signer/build/install/web3signer/bin/web3signer}"
+WEB3SIGNER_FLAGS="${WEB3SIGNER_FLAGS:-eth2 --network=mainnet}"
+# WEB3SIGNER_ETH2_SLASHING_PROTECTION_DB_PASSWORD is read by web3signer itself
+export WEB3SIGNER_ETH2_SLASHING_PROTECTION_DB_PASSWORD
+
+# Start web3signer
+$WEB3SIGNER_BINARY $WEB3SIGNER_FLAGS
@@ -0,0 +1,389 @@
+package node_deployer
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/consensusClient"
+	"github.com/rswanson/node_deployer/executionClient"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// FleetSchema is the JSON schema fleet spec files are validated against.
+//
+//go:embed schema/fleet.schema.json
+var FleetSchema []byte
+
+// FleetSpec is a declarative description of the nodes of a fleet, see LoadFleetSpec.
+type FleetSpec struct {
+	Nodes []*FleetNodeSpec
+}
+
+// FleetNodeSpec describes a single node, or several replicas of it, of a FleetSpec.
+type FleetNodeSpec struct {
+	Name string
+	// Args holds the client args of the node, Replicas included.
+	Args *EthereumNodeArgs
+	// Cluster is the kubernetes cluster the node runs in, nil for the default cluster.
+	Cluster *FleetClusterSpec
+}
+
+// FleetClusterSpec selects a kubernetes cluster from a kubeconfig and the
+// namespace the node's clients run in.
+type FleetClusterSpec struct {
+	Kubeconfig string
+	Context    string
+	Namespace  string
+}
+
+type fleetDocument struct {
+	Nodes []nodeDocument `yaml:"nodes"`
+}
+
+type nodeDocument struct {
+	Name      string             `yaml:"name"`
+	Network   string             `yaml:"network"`
+	Replicas  int                `yaml:"replicas"`
+	Host      *hostDocument      `yaml:"host"`
+	Cluster   *FleetClusterSpec  `yaml:"cluster"`
+	Manifests *manifestsDocument `yaml:"manifests"`
+	Execution clientDocument     `yaml:"execution"`
+	Consensus *clientDocument    `yaml:"consensus"`
+}
+
+type manifestsDocument struct {
+	Dir               string `yaml:"dir"`
+	Secrets           string `yaml:"secrets"`
+	SealedSecretsCert string `yaml:"sealedSecretsCert"`
+}
+
+type hostDocument struct {
+	Address        string `yaml:"address"`
+	Port           int    `yaml:"port"`
+	User           string `yaml:"user"`
+	PrivateKeyPath string `yaml:"privateKeyPath"`
+}
+
+type clientDocument struct {
+	Client         string   `yaml:"client"`
+	DeploymentType string   `yaml:"deploymentType"`
+	Name           string   `yaml:"name"`
+	Version        string   `yaml:"version"`
+	Image          string   `yaml:"image"`
+	ConfigPath     string   `yaml:"configPath"`
+	DataDir        string   `yaml:"dataDir"`
+	Commands       []string `yaml:"commands"`
+	Caplin         bool     `yaml:"caplin"`
+	Source         struct {
+		RepoUrl string `yaml:"repoUrl"`
+		Ref     string `yaml:"ref"`
+	} `yaml:"source"`
+	Release struct {
+		Sha256 string `yaml:"sha256"`
+		PgpKey string `yaml:"pgpKey"`
+		Arch   string `yaml:"arch"`
+		Url    string `yaml:"url"`
+	} `yaml:"release"`
+	Resources struct {
+		CpuLimit      string `yaml:"cpuLimit"`
+		MemoryLimit   string `yaml:"memoryLimit"`
+		CpuRequest    string `yaml:"cpuRequest"`
+		MemoryRequest string `yaml:"memoryRequest"`
+	} `yaml:"resources"`
+	Storage struct {
+		Class    string `yaml:"class"`
+		Size     string `yaml:"size"`
+		Snapshot string `yaml:"snapshot"`
+		Format   string `yaml:"format"`
+	} `yaml:"storage"`
+}
+
+// LoadFleetSpec reads the YAML fleet spec at path, validates it against
+// FleetSchema and the client args' own validation and returns the args of
+// every node. Relative paths in the spec are resolved against the spec's
+// directory and ssh private keys are read into secrets.
+//
+// Example spec:
+//
+//	nodes:
+//	  - name: holesky
+//	    network: holesky
+//	    replicas: 2
+//	    cluster:
+//	      context: staging
+//	    execution:
+//	      client: reth
+//	      deploymentType: kubernetes
+//	      version: v1.1.0
+//	      configPath: config/reth.toml
+//	      resources: {cpuLimit: "4", memoryLimit: 16Gi}
+//	      storage: {class: fast-ssd, size: 500Gi}
+//	    consensus:
+//	      client: lighthouse
+//	      deploymentType: kubernetes
+//	      configPath: config/lighthouse.toml
+//	      resources: {cpuLimit: "2", memoryLimit: 8Gi}
+func LoadFleetSpec(path string) (*FleetSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateFleetSpec(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var document fleetDocument
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	spec := &FleetSpec{}
+	for _, node := range document.Nodes {
+		nodeSpec, err := node.spec(dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: node %s: %w", path, node.Name, err)
+		}
+		spec.Nodes = append(spec.Nodes, nodeSpec)
+	}
+	return spec, nil
+}
+
+// DeployFleetSpec creates the replicas of every node in spec with EthereumNodeFactory
+// and returns the fleets keyed by node name. Nodes with a cluster get their own
+// kubernetes provider.
+func DeployFleetSpec(ctx *pulumi.Context, spec *FleetSpec, opts ...pulumi.ResourceOption) (map[string]*NodeFleet, error) {
+	fleets := make(map[string]*NodeFleet, len(spec.Nodes))
+	for _, node := range spec.Nodes {
+		template := &EthereumNodeTemplate{EthereumNodeArgs: *node.Args}
+		if node.Cluster != nil {
+			providerArgs := &kubernetes.ProviderArgs{}
+			if node.Cluster.Kubeconfig != "" {
+				providerArgs.Kubeconfig = pulumi.StringPtr(node.Cluster.Kubeconfig)
+			}
+			if node.Cluster.Context != "" {
+				providerArgs.Context = pulumi.StringPtr(node.Cluster.Context)
+			}
+			provider, err := kubernetes.NewProvider(ctx, fmt.Sprintf("%s-cluster", node.Name), providerArgs, opts...)
+			if err != nil {
+				ctx.Log.Error("Error creating kubernetes provider for "+node.Name, nil)
+				return nil, err
+			}
+			for i := 0; i < template.Replicas; i++ {
+				template.KubernetesProviders = append(template.KubernetesProviders, provider)
+			}
+		}
+
+		fleet, err := EthereumNodeFactory(ctx, node.Name, template, opts...)
+		if err != nil {
+			return nil, err
+		}
+		fleets[node.Name] = fleet
+	}
+	return fleets, nil
+}
+
+// validateFleetSpec validates the YAML document data against FleetSchema.
+func validateFleetSpec(data []byte) error {
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("fleet.schema.json", bytes.NewReader(FleetSchema)); err != nil {
+		return err
+	}
+	schema, err := compiler.Compile("fleet.schema.json")
+	if err != nil {
+		return err
+	}
+
+	// round trip through json so the document only holds json types
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return err
+	}
+	return schema.Validate(document)
+}
+
+// spec converts the node document into the node's args.
+func (node nodeDocument) spec(dir string) (*FleetNodeSpec, error) {
+	var connection *remote.ConnectionArgs
+	if node.Host != nil {
+		connection = &remote.ConnectionArgs{
+			Host: pulumi.String(node.Host.Address),
+			User: pulumi.String(node.Host.User),
+		}
+		if node.Host.Port != 0 {
+			connection.Port = pulumi.Float64(float64(node.Host.Port))
+		}
+		if node.Host.PrivateKeyPath != "" {
+			privateKey, err := os.ReadFile(resolvePath(dir, node.Host.PrivateKeyPath))
+			if err != nil {
+				return nil, err
+			}
+			connection.PrivateKey = pulumi.ToSecret(pulumi.String(string(privateKey))).(pulumi.StringOutput)
+		}
+	}
+
+	el := node.Execution
+	executionClientArgs := &executionClient.ExecutionClientComponentArgs{
+		Connection:                       connection,
+		Client:                           el.Client,
+		Network:                          node.Network,
+		DeploymentType:                   el.DeploymentType,
+		DataDir:                          el.DataDir,
+		SourceRepoUrl:                    el.Source.RepoUrl,
+		SourceRef:                        el.Source.Ref,
+		ExecutionClientConfigPath:        resolvePath(dir, el.ConfigPath),
+		ExecutionClientImage:             el.Image,
+		ExecutionClientContainerCommands: el.Commands,
+		Name:                             el.Name,
+		PodStorageClass:                  el.Storage.Class,
+		PodStorageSize:                   el.Storage.Size,
+		RethSnapshotName:                 el.Storage.Snapshot,
+		CpuLimit:                         el.Resources.CpuLimit,
+		MemoryLimit:                      el.Resources.MemoryLimit,
+		CpuRequest:                       el.Resources.CpuRequest,
+		MemoryRequest:                    el.Resources.MemoryRequest,
+		EnableCaplin:                     el.Caplin,
+		BesuStorageFormat:                el.Storage.Format,
+	}
+	if el.DeploymentType == executionClient.Binary {
+		executionClientArgs.BinaryVersion = el.Version
+		executionClientArgs.BinarySha256 = el.Release.Sha256
+		executionClientArgs.BinaryPgpKey = el.Release.PgpKey
+		executionClientArgs.BinaryArch = el.Release.Arch
+		executionClientArgs.BinaryUrl = el.Release.Url
+	} else if executionClientArgs.ExecutionClientImage == "" && el.Version != "" {
+		if driver, ok := executionClient.LookupExecutionClient(el.Client); ok {
+			executionClientArgs.ExecutionClientImage = imageWithTag(driver.DefaultImage(), el.Version)
+		}
+	}
+
+	// the jwt is provided by the node, validate copies that already have one
+	executionCheck := *executionClientArgs
+	executionCheck.ExecutionJwt = pulumi.String("")
+	if err := executionCheck.Validate(); err != nil {
+		return nil, err
+	}
+
+	// nodes running erigon's Caplin consensus client have no consensus section
+	var consensusClientArgs *consensusClient.ConsensusClientComponentArgs
+	if cl := node.Consensus; cl != nil {
+		if cl.Caplin {
+			return nil, errors.New("consensus: caplin is only supported by the execution client")
+		}
+		consensusClientArgs = &consensusClient.ConsensusClientComponentArgs{
+			Connection:                       connection,
+			Client:                           cl.Client,
+			Network:                          node.Network,
+			DeploymentType:                   cl.DeploymentType,
+			DataDir:                          cl.DataDir,
+			SourceRepoUrl:                    cl.Source.RepoUrl,
+			SourceRef:                        cl.Source.Ref,
+			ConsensusClientConfigPath:        resolvePath(dir, cl.ConfigPath),
+			ConsensusClientImage:             cl.Image,
+			ConsensusClientContainerCommands: cl.Commands,
+			Name:                             cl.Name,
+			PodStorageClass:                  cl.Storage.Class,
+			PodStorageSize:                   cl.Storage.Size,
+			SnapshotName:                     cl.Storage.Snapshot,
+			CpuLimit:                         cl.Resources.CpuLimit,
+			MemoryLimit:                      cl.Resources.MemoryLimit,
+			CpuRequest:                       cl.Resources.CpuRequest,
+			MemoryRequest:                    cl.Resources.MemoryRequest,
+		}
+		if cl.DeploymentType == consensusClient.Binary {
+			consensusClientArgs.BinaryVersion = cl.Version
+			consensusClientArgs.BinarySha256 = cl.Release.Sha256
+			consensusClientArgs.BinaryPgpKey = cl.Release.PgpKey
+			consensusClientArgs.BinaryArch = cl.Release.Arch
+			consensusClientArgs.BinaryUrl = cl.Release.Url
+		} else if consensusClientArgs.ConsensusClientImage == "" && cl.Version != "" {
+			if driver, ok := consensusClient.LookupConsensusClient(cl.Client); ok {
+				consensusClientArgs.ConsensusClientImage = imageWithTag(driver.DefaultImage(), cl.Version)
+			}
+		}
+
+		consensusCheck := *consensusClientArgs
+		consensusCheck.ExecutionJwt = pulumi.String("")
+		if err := consensusCheck.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	replicas := node.Replicas
+	if replicas == 0 {
+		replicas = 1
+	}
+
+	var cluster *FleetClusterSpec
+	if node.Cluster != nil {
+		cluster = &FleetClusterSpec{
+			Kubeconfig: resolvePath(dir, node.Cluster.Kubeconfig),
+			Context:    node.Cluster.Context,
+			Namespace:  node.Cluster.Namespace,
+		}
+	}
+
+	args := &EthereumNodeArgs{
+		ExecutionClientArgs: executionClientArgs,
+		ConsensusClientArgs: consensusClientArgs,
+		Replicas:            replicas,
+	}
+	if cluster != nil {
+		args.Namespace = cluster.Namespace
+		// node identity queries run in the cluster the provider deploys to
+		if executionClientArgs.DeploymentType == executionClient.Kubernetes {
+			executionClientArgs.Kubeconfig = cluster.Kubeconfig
+			executionClientArgs.KubeContext = cluster.Context
+		}
+		if consensusClientArgs != nil && consensusClientArgs.DeploymentType == consensusClient.Kubernetes {
+			consensusClientArgs.Kubeconfig = cluster.Kubeconfig
+			consensusClientArgs.KubeContext = cluster.Context
+		}
+	}
+	if node.Manifests != nil {
+		args.RenderManifestsTo = resolvePath(dir, node.Manifests.Dir)
+		args.RenderSecrets = node.Manifests.Secrets
+		args.SealedSecretsCert = resolvePath(dir, node.Manifests.SealedSecretsCert)
+	}
+
+	return &FleetNodeSpec{
+		Name:    node.Name,
+		Args:    args,
+		Cluster: cluster,
+	}, nil
+}
+
+// resolvePath resolves a relative path against dir, empty paths stay empty.
+func resolvePath(dir string, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// imageWithTag replaces the tag of image with tag.
+func imageWithTag(image string, tag string) string {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image + ":" + tag
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// ReleaseDir is where binary deployments install client releases. Every
+// version of a client gets its own directory next to a current symlink
+// pointing at the installed one, so older versions stay around for rollbacks.
+const ReleaseDir = "/data/releases"
+
+// DefaultArch is the architecture releases are downloaded for unless set.
+const DefaultArch = "amd64"
+
+// ReleaseArchs are the architectures client releases can be downloaded for.
+var ReleaseArchs = []string{"amd64", "arm64"}
+
+var sha256Pattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// BinaryRelease is a client release downloaded by a binary deployment.
+type BinaryRelease struct {
+	// Client names the directory under ReleaseDir the release is installed in.
+	Client  string
+	Version string
+	Url     string
+	// Sha256 is the expected hex sha256 digest of the download.
+	Sha256 string
+	// PgpKey is an armored public key the detached signature at Url + ".asc"
+	// is verified with, no signature is checked when empty.
+	PgpKey string
+	// Executable is the path of the client executable inside the extracted
+	// release, empty when Url is the executable itself.
+	Executable string
+	// Link is where the executable of the current release is linked, the
+	// path the client's start script runs.
+	Link string
+}
+
+// ValidateRelease records problems with the release settings of a binary
+// deployment in errs, the fields are named after prefix, e.g. BinaryVersion.
+func ValidateRelease(errs *ValidationErrors, prefix string, version string, arch string, sha256 string) {
+	if version == "" {
+		errs.Add(prefix+"Version", "required for binary deployments")
+	}
+	if arch != "" && !slices.Contains(ReleaseArchs, arch) {
+		errs.Add(prefix+"Arch", "unknown architecture %q, expected one of %s", arch, strings.Join(ReleaseArchs, ", "))
+	}
+	if sha256 == "" {
+		errs.Add(prefix+"Sha256", "required for binary deployments")
+	} else if !sha256Pattern.MatchString(sha256) {
+		errs.Add(prefix+"Sha256", "expected a hex encoded sha256 digest")
+	}
+}
+
+// Dir returns the directory the release is extracted to.
+func (release BinaryRelease) Dir() string {
+	return path.Join(ReleaseDir, release.Client, release.Version)
+}
+
+// CurrentDir returns the symlink pointing at the installed release.
+func (release BinaryRelease) CurrentDir() string {
+	return path.Join(ReleaseDir, release.Client, "current")
+}
+
+// InstallCommand returns the shell command downloading and verifying the
+// release, extracting it to Dir and pointing CurrentDir and Link at it.
+// Nothing is installed when the checksum or the signature don't match.
+func (release BinaryRelease) InstallCommand() string {
+	executable := release.Executable
+	if executable == "" {
+		executable = path.Base(release.Link)
+	}
+
+	steps := []string{
+		`tmp=$(mktemp -d)`,
+		fmt.Sprintf(`curl -fsSL -o "$tmp/release" %s`, shellQuote(release.Url)),
+		fmt.Sprintf(`echo %s | sha256sum -c -`, shellQuote(strings.ToLower(release.Sha256)+`  `)+`"$tmp/release"`),
+	}
+	if release.PgpKey != "" {
+		steps = append(steps,
+			`mkdir -m 700 "$tmp/gnupg"`,
+			fmt.Sprintf(`curl -fsSL -o "$tmp/release.asc" %s`, shellQuote(release.Url+".asc")),
+			fmt.Sprintf(`echo %s | GNUPGHOME="$tmp/gnupg" gpg --batch --import`, shellQuote(release.PgpKey)),
+			`GNUPGHOME="$tmp/gnupg" gpg --batch --verify "$tmp/release.asc" "$tmp/release"`,
+		)
+	}
+	steps = append(steps, fmt.Sprintf("mkdir -p %s", release.Dir()))
+	switch {
+	case release.Executable == "":
+		steps = append(steps, fmt.Sprintf(`install -m 0755 "$tmp/release" %s`, path.Join(release.Dir(), executable)))
+	case strings.HasSuffix(release.Url, ".zip"):
+		steps = append(steps, fmt.Sprintf(`unzip -o -q "$tmp/release" -d %s`, release.Dir()))
+	default:
+		steps = append(steps, fmt.Sprintf(`tar -xzf "$tmp/release" -C %s`, release.Dir()))
+	}
+	steps = append(steps,
+		fmt.Sprintf("ln -sfn %s %s", release.Dir(), release.CurrentDir()),
+		fmt.Sprintf("mkdir -p %s", path.Dir(release.Link)),
+		fmt.Sprintf("ln -sfn %s %s", path.Join(release.CurrentDir(), executable), release.Link),
+	)
+
+	return fmt.Sprintf(`%s; status=$?; rm -rf "$tmp"; exit $status`, strings.Join(steps, " && "))
+}
+
+// NewBinaryRelease installs release on the remote host. Changing the version
+// installs the new release next to the previous ones and moves the current
+// symlink, installed releases are left in place when the resource is deleted.
+func NewBinaryRelease(ctx *pulumi.Context, name string, connection *remote.ConnectionArgs, release BinaryRelease, opts ...pulumi.ResourceOption) (*remote.Command, error) {
+	return remote.NewCommand(ctx, name, &remote.CommandArgs{
+		Create:     pulumi.String(release.InstallCommand()),
+		Connection: connection,
+	}, opts...)
+}
+
+// NewServiceRestart restarts the running systemd service of serviceType on
+// network whenever release changes, e.g. after installing a new version.
+func NewServiceRestart(ctx *pulumi.Context, name string, connection *remote.ConnectionArgs, serviceType string, network string, release BinaryRelease, opts ...pulumi.ResourceOption) (*remote.Command, error) {
+	return remote.NewCommand(ctx, name, &remote.CommandArgs{
+		Create:     pulumi.Sprintf("systemctl try-restart %s.%s", serviceType, network),
+		Triggers:   pulumi.Array{pulumi.String(release.Url), pulumi.String(release.Sha256)},
+		Connection: connection,
+	}, opts...)
+}
+
+// RustTarget returns the rust target triple release archives of rust clients
+// like reth and lighthouse are named after for arch.
+func RustTarget(arch string) string {
+	if arch == "arm64" {
+		return "aarch64-unknown-linux-gnu"
+	}
+	return "x86_64-unknown-linux-gnu"
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// DockerRestartPolicy is the restart policy of containers that set none.
+const DockerRestartPolicy = "unless-stopped"
+
+// DockerNetworkName returns the user-defined docker network the clients of
+// network share on a host, containers on it reach each other by name.
+func DockerNetworkName(network string) string {
+	return "node-deployer-" + network
+}
+
+// DockerVolume is a host directory bind mounted into a container.
+type DockerVolume struct {
+	HostPath      string
+	ContainerPath string
+	ReadOnly      bool
+}
+
+// DockerPort is a container port published on the host.
+type DockerPort struct {
+	Port int
+	// Protocol is tcp unless set to udp.
+	Protocol string
+	// HostIp limits the published port to an address of the host, e.g.
+	// 127.0.0.1 for ports only local processes may use. Empty for all addresses.
+	HostIp string
+}
+
+type DockerContainerArgs struct {
+	Connection *remote.ConnectionArgs
+	// Name is the container name, other containers on Network reach it by it.
+	Name  string
+	Image string
+	// Command replaces the image entrypoint, its first element becomes the
+	// entrypoint and the rest are passed as arguments before Args, like the
+	// command of a kubernetes container.
+	Command []string
+	Args    pulumi.StringArrayInput
+	// Network is the user-defined docker network the container is attached to.
+	Network string
+	// Groups are host groups, by name, the container's user is added to,
+	// e.g. JwtSecretGroup for containers mounting the jwt secret. Groups
+	// missing on the host are skipped.
+	Groups        []string
+	Volumes       []DockerVolume
+	Ports         []DockerPort
+	Environment   pulumi.StringMap
+	RestartPolicy string
+}
+
+// NewDockerNetwork creates the user-defined docker network named network on
+// the remote host unless it exists. The network is shared by all containers
+// attached to it and is left in place when the resource is deleted.
+func NewDockerNetwork(ctx *pulumi.Context, name string, connection *remote.ConnectionArgs, network string, opts ...pulumi.ResourceOption) (*remote.Command, error) {
+	return remote.NewCommand(ctx, name, &remote.CommandArgs{
+		Create:     pulumi.Sprintf("docker network inspect %s >/dev/null 2>&1 || docker network create %s", network, network),
+		Connection: connection,
+	}, opts...)
+}
+
+// NewDockerContainer runs a container on the remote host with docker run.
+// The host directories of the volumes are created first and a container of
+// the same name is replaced, so changing the args restarts the container.
+// The container is removed when the resource is deleted.
+func NewDockerContainer(ctx *pulumi.Context, name string, args *DockerContainerArgs, opts ...pulumi.ResourceOption) (*remote.Command, error) {
+	containerArgs := args.Args
+	if containerArgs == nil {
+		containerArgs = pulumi.StringArray{}
+	}
+	environment := args.Environment
+	if environment == nil {
+		environment = pulumi.StringMap{}
+	}
+
+	create := pulumi.All(containerArgs, environment.ToStringMapOutput()).ApplyT(func(inputs []interface{}) string {
+		return dockerRunCommand(args, inputs[0].([]string), inputs[1].(map[string]string))
+	}).(pulumi.StringOutput)
+
+	return remote.NewCommand(ctx, name, &remote.CommandArgs{
+		Create:     create,
+		Delete:     pulumi.Sprintf("docker rm -f %s", args.Name),
+		Connection: args.Connection,
+	}, opts...)
+}
+
+// dockerRunCommand renders the shell command starting the container of args.
+func dockerRunCommand(args *DockerContainerArgs, containerArgs []string, env map[string]string) string {
+	restartPolicy := args.RestartPolicy
+	if restartPolicy == "" {
+		restartPolicy = DockerRestartPolicy
+	}
+
+	var dirs []string
+	for _, volume := range args.Volumes {
+		dirs = append(dirs, shellQuote(volume.HostPath))
+	}
+
+	run := []string{"docker", "run", "-d", "--name", args.Name, "--restart", restartPolicy}
+	if args.Network != "" {
+		run = append(run, "--network", args.Network)
+	}
+	for _, group := range args.Groups {
+		run = append(run, fmt.Sprintf("$(getent group %s | cut -d: -f3 | sed 's/^/--group-add /')", group))
+	}
+	for _, volume := range args.Volumes {
+		mount := volume.HostPath + ":" + volume.ContainerPath
+		if volume.ReadOnly {
+			mount += ":ro"
+		}
+		run = append(run, "-v", shellQuote(mount))
+	}
+	for _, port := range args.Ports {
+		publish := fmt.Sprintf("%d:%d", port.Port, port.Port)
+		if port.HostIp != "" {
+			publish = port.HostIp + ":" + publish
+		}
+		if port.Protocol != "" && port.Protocol != "tcp" {
+			publish += "/" + port.Protocol
+		}
+		run = append(run, "-p", publish)
+	}
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		run = append(run, "-e", shellQuote(key+"="+env[key]))
+	}
+	if len(args.Command) > 0 {
+		run = append(run, "--entrypoint", shellQuote(args.Command[0]))
+	}
+	run = append(run, shellQuote(args.Image))
+	if len(args.Command) > 1 {
+		for _, arg := range args.Command[1:] {
+			run = append(run, shellQuote(arg))
+		}
+	}
+	for _, arg := range containerArgs {
+		run = append(run, shellQuote(arg))
+	}
+
+	command := fmt.Sprintf("docker rm -f %s >/dev/null 2>&1; %s", args.Name, strings.Join(run, " "))
+	if len(dirs) > 0 {
+		command = fmt.Sprintf("mkdir -p %s && %s", strings.Join(dirs, " "), command)
+	}
+	return command
+}
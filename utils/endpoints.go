@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/local"
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// ClusterServiceHost returns the in-cluster DNS name of a kubernetes service,
+// e.g. geth-rpc-service.default.svc.cluster.local
+func ClusterServiceHost(service *corev1.Service) pulumi.StringOutput {
+	return pulumi.All(service.Metadata.Name(), service.Metadata.Namespace()).ApplyT(func(values []interface{}) string {
+		name, namespace := "", "default"
+		if v, ok := values[0].(*string); ok && v != nil {
+			name = *v
+		}
+		if v, ok := values[1].(*string); ok && v != nil && *v != "" {
+			namespace = *v
+		}
+		return fmt.Sprintf("%s.%s.svc.cluster.local", name, namespace)
+	}).(pulumi.StringOutput)
+}
+
+// NodeIdentityQueryArgs describes how to read the identity of a deployed node,
+// such as its enode or ENR, from the node's own API.
+type NodeIdentityQueryArgs struct {
+	// Connection runs the query on the node's host over ssh. When nil the query
+	// runs from a temporary curl pod started with the local kubectl.
+	Connection *remote.ConnectionArgs
+	// Namespace is the kubernetes namespace the curl pod is started in.
+	Namespace pulumi.StringInput
+	// Kubeconfig and Context select the cluster the curl pod is started in,
+	// the local kubectl's current context when empty.
+	Kubeconfig string
+	Context    string
+	// Url is the endpoint to query.
+	Url pulumi.StringInput
+	// Request is an optional JSON body that is POSTed to Url.
+	Request string
+	// Field is the JSON field holding the identity in the response.
+	Field string
+}
+
+// NewNodeIdentityQuery polls a node's API until it reports its identity and
+// returns the identity. A node that does not report it in time fails the
+// query and with it the update.
+func NewNodeIdentityQuery(ctx *pulumi.Context, name string, args *NodeIdentityQueryArgs, opts ...pulumi.ResourceOption) (pulumi.StringOutput, error) {
+	script := pulumi.All(args.Url).ApplyT(func(values []interface{}) string {
+		return identityScript(values[0].(string), args.Request, args.Field)
+	}).(pulumi.StringOutput)
+
+	if args.Connection != nil {
+		query, err := remote.NewCommand(ctx, name, &remote.CommandArgs{
+			Create:     script,
+			Connection: args.Connection,
+		}, opts...)
+		if err != nil {
+			return pulumi.StringOutput{}, err
+		}
+		return query.Stdout, nil
+	}
+
+	namespace := pulumi.StringInput(pulumi.String(""))
+	if args.Namespace != nil {
+		namespace = args.Namespace
+	}
+	command := pulumi.All(script, namespace).ApplyT(func(values []interface{}) string {
+		kubectl := []string{"kubectl", "run", strings.ToLower(name), "--rm", "-i", "--quiet", "--restart=Never", "--image=curlimages/curl"}
+		if args.Kubeconfig != "" {
+			kubectl = append(kubectl, "--kubeconfig", shellQuote(args.Kubeconfig))
+		}
+		if args.Context != "" {
+			kubectl = append(kubectl, "--context", shellQuote(args.Context))
+		}
+		if ns := values[1].(string); ns != "" {
+			kubectl = append(kubectl, "--namespace", ns)
+		}
+		kubectl = append(kubectl, "--command", "--", "sh", "-c", shellQuote(values[0].(string)))
+		return strings.Join(kubectl, " ")
+	}).(pulumi.StringOutput)
+	query, err := local.NewCommand(ctx, name, &local.CommandArgs{
+		Create: command,
+	}, opts...)
+	if err != nil {
+		return pulumi.StringOutput{}, err
+	}
+	return query.Stdout, nil
+}
+
+// identityScript polls url with curl for up to five minutes and prints the
+// value of field from the first response that contains it, it exits non-zero
+// when no response does.
+func identityScript(url string, request string, field string) string {
+	curl := "curl -s"
+	if request != "" {
+		curl += " -X POST -H 'Content-Type: application/json' --data " + shellQuote(request)
+	}
+	return fmt.Sprintf(`for i in $(seq 1 30); do id=$(%s %s | grep -o '"%s":"[^"]*"' | cut -d'"' -f4); [ -n "$id" ] && break; sleep 10; done; [ -n "$id" ] || { echo "no %s reported by %s" >&2; exit 1; }; printf '%%s' "$id"`, curl, url, field, field, url)
+}
+
+// shellQuote quotes s as a single sh word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
@@ -0,0 +1,314 @@
+package utils
+
+import (
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes"
+	"github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/apiextensions"
+	networkingv1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/networking/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+const (
+	// IngressKindIngress exposes the rpc with networking.k8s.io Ingress
+	// objects, the annotations for basic auth and the IP allowlist are the
+	// ones of ingress-nginx.
+	IngressKindIngress = "ingress"
+	// IngressKindHTTPRoute exposes the rpc with a Gateway API HTTPRoute
+	// attached to an existing Gateway, which terminates TLS.
+	IngressKindHTTPRoute = "httproute"
+)
+
+// IngressKinds are the kinds of objects the rpc of a component can be exposed with.
+var IngressKinds = []string{IngressKindIngress, IngressKindHTTPRoute}
+
+// Ingress describes how the rpc of a component is exposed outside the cluster.
+type Ingress struct {
+	// Kind is IngressKindIngress (the default) or IngressKindHTTPRoute.
+	Kind string
+	// Host is the hostname the rpc is served on, e.g. rpc.example.com.
+	Host string
+	// ClassName is the ingress class of Ingress objects, the cluster's
+	// default class when empty.
+	ClassName string
+	// Gateway is the Gateway HTTPRoutes attach to, "name" or "namespace/name".
+	Gateway string
+	// TlsIssuer is the cert-manager ClusterIssuer the certificate of Host is
+	// requested from, the rpc is served without TLS when empty.
+	TlsIssuer string
+	// BasicAuthSecret is a Secret with an htpasswd file in its auth key
+	// requests have to authenticate against.
+	BasicAuthSecret string
+	// AllowedSourceRanges are the CIDRs requests are accepted from, any
+	// address when empty.
+	AllowedSourceRanges []string
+	// Annotations are added to the Ingress or HTTPRoute objects, e.g. for
+	// the middlewares of other ingress controllers.
+	Annotations map[string]string
+}
+
+// IngressArgs are the ingress fields components embed in their args, each
+// sets the field of Ingress it is named after without the prefix.
+type IngressArgs struct {
+	// IngressKind is "ingress" (the default) for Ingress objects of
+	// IngressClassName or "httproute" for a Gateway API HTTPRoute attached to
+	// IngressGateway, given as name or namespace/name.
+	IngressKind      string
+	IngressHost      string
+	IngressClassName string
+	IngressGateway   string
+	// IngressTlsIssuer is the cert-manager ClusterIssuer a certificate of the
+	// host is requested from.
+	IngressTlsIssuer string
+	// IngressBasicAuthSecret, a Secret with an htpasswd file in its auth key,
+	// and IngressAllowedSourceRanges restrict access through ingress-nginx
+	// annotations. IngressAnnotations are added to the objects as is.
+	IngressBasicAuthSecret     string
+	IngressAllowedSourceRanges []string
+	IngressAnnotations         map[string]string
+}
+
+// Ingress returns the ingress settings of args.
+func (args IngressArgs) Ingress() Ingress {
+	return Ingress{
+		Kind:                args.IngressKind,
+		Host:                args.IngressHost,
+		ClassName:           args.IngressClassName,
+		Gateway:             args.IngressGateway,
+		TlsIssuer:           args.IngressTlsIssuer,
+		BasicAuthSecret:     args.IngressBasicAuthSecret,
+		AllowedSourceRanges: args.IngressAllowedSourceRanges,
+		Annotations:         args.IngressAnnotations,
+	}
+}
+
+// IngressBackend is a service port the rpc routes a path prefix to.
+type IngressBackend struct {
+	// Name is appended to the component's name to name the backend's Ingress.
+	Name string
+	// Path is the prefix routed to the backend, it is stripped before the
+	// request is forwarded so clients only serving / can be reached below it.
+	Path    string
+	Service string
+	Port    int
+}
+
+// EnabledIngress returns ingress with its kind defaulted to
+// IngressKindIngress, nil when enabled is not set.
+func EnabledIngress(enabled bool, ingress Ingress) *Ingress {
+	if !enabled {
+		return nil
+	}
+	if ingress.Kind == "" {
+		ingress.Kind = IngressKindIngress
+	}
+	return &ingress
+}
+
+// ValidateIngress records problems with the ingress settings of a
+// component's args in errs, enableField is the name of the flag enabling them.
+func ValidateIngress(errs *ValidationErrors, enableField string, enabled bool, deploymentType string, kubernetesType string, ingress Ingress) {
+	if !enabled {
+		if ingress.Kind != "" || ingress.Host != "" || ingress.ClassName != "" || ingress.Gateway != "" || ingress.TlsIssuer != "" ||
+			ingress.BasicAuthSecret != "" || len(ingress.AllowedSourceRanges) > 0 || len(ingress.Annotations) > 0 {
+			errs.Add("Ingress", "settings are only supported when %s is set", enableField)
+		}
+		return
+	}
+	if deploymentType != kubernetesType {
+		errs.Add(enableField, "only supported for %s deployments", kubernetesType)
+	}
+	if ingress.Host == "" {
+		errs.Add("IngressHost", "required when %s is set", enableField)
+	}
+	if ingress.Kind != "" && !slices.Contains(IngressKinds, ingress.Kind) {
+		errs.Add("IngressKind", "unknown ingress kind %q, expected one of %s", ingress.Kind, strings.Join(IngressKinds, ", "))
+	}
+	for _, cidr := range ingress.AllowedSourceRanges {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs.Add("IngressAllowedSourceRanges", "%q is not a CIDR", cidr)
+		}
+	}
+
+	if ingress.Kind == IngressKindHTTPRoute {
+		if ingress.Gateway == "" {
+			errs.Add("IngressGateway", "required for %s ingresses", IngressKindHTTPRoute)
+		}
+		// the gateway terminates TLS and gateway implementations differ in
+		// how they authenticate, so these only work with ingress-nginx
+		unsupported := []struct {
+			field string
+			set   bool
+		}{
+			{"IngressClassName", ingress.ClassName != ""},
+			{"IngressTlsIssuer", ingress.TlsIssuer != ""},
+			{"IngressBasicAuthSecret", ingress.BasicAuthSecret != ""},
+			{"IngressAllowedSourceRanges", len(ingress.AllowedSourceRanges) > 0},
+		}
+		for _, setting := range unsupported {
+			if setting.set {
+				errs.Add(setting.field, "not supported for %s ingresses", IngressKindHTTPRoute)
+			}
+		}
+	} else if ingress.Gateway != "" {
+		errs.Add("IngressGateway", "only supported for %s ingresses", IngressKindHTTPRoute)
+	}
+}
+
+// NewIngress exposes backends on ingress.Host with an Ingress object per
+// backend or a single HTTPRoute, and requests the TLS certificate of the host
+// from cert-manager when ingress.TlsIssuer is set.
+func NewIngress(ctx *pulumi.Context, names KubernetesNames, ingress *Ingress, backends []IngressBackend, opts ...pulumi.ResourceOption) error {
+	if ingress.Kind == IngressKindHTTPRoute {
+		return newHTTPRoute(ctx, names, ingress, backends, opts...)
+	}
+
+	var tls networkingv1.IngressTLSArray
+	if ingress.TlsIssuer != "" {
+		_, err := apiextensions.NewCustomResource(ctx, names.Resource("rpc-tls"), &apiextensions.CustomResourceArgs{
+			ApiVersion: pulumi.String("cert-manager.io/v1"),
+			Kind:       pulumi.String("Certificate"),
+			Metadata:   names.Metadata("rpc-tls"),
+			OtherFields: kubernetes.UntypedArgs{
+				"spec": pulumi.Map{
+					"secretName": pulumi.String(names.Object("rpc-tls")),
+					"dnsNames":   pulumi.StringArray{pulumi.String(ingress.Host)},
+					"issuerRef": pulumi.Map{
+						"name":  pulumi.String(ingress.TlsIssuer),
+						"kind":  pulumi.String("ClusterIssuer"),
+						"group": pulumi.String("cert-manager.io"),
+					},
+				},
+			},
+		}, opts...)
+		if err != nil {
+			return err
+		}
+		tls = networkingv1.IngressTLSArray{
+			networkingv1.IngressTLSArgs{
+				Hosts:      pulumi.StringArray{pulumi.String(ingress.Host)},
+				SecretName: pulumi.String(names.Object("rpc-tls")),
+			},
+		}
+	}
+
+	for _, backend := range backends {
+		annotations := pulumi.StringMap{}
+		if backend.Path != "/" {
+			annotations["nginx.ingress.kubernetes.io/rewrite-target"] = pulumi.String("/")
+			// keep websocket subscriptions open
+			annotations["nginx.ingress.kubernetes.io/proxy-read-timeout"] = pulumi.String("3600")
+			annotations["nginx.ingress.kubernetes.io/proxy-send-timeout"] = pulumi.String("3600")
+		}
+		if ingress.BasicAuthSecret != "" {
+			annotations["nginx.ingress.kubernetes.io/auth-type"] = pulumi.String("basic")
+			annotations["nginx.ingress.kubernetes.io/auth-secret"] = pulumi.String(ingress.BasicAuthSecret)
+			annotations["nginx.ingress.kubernetes.io/auth-realm"] = pulumi.String("Authentication Required")
+		}
+		if len(ingress.AllowedSourceRanges) > 0 {
+			annotations["nginx.ingress.kubernetes.io/whitelist-source-range"] = pulumi.String(strings.Join(ingress.AllowedSourceRanges, ","))
+		}
+		for key, value := range ingress.Annotations {
+			annotations[key] = pulumi.String(value)
+		}
+
+		suffix := backend.Name + "-ingress"
+		metadata := names.Metadata(suffix)
+		metadata.Annotations = annotations
+		spec := &networkingv1.IngressSpecArgs{
+			Rules: networkingv1.IngressRuleArray{
+				networkingv1.IngressRuleArgs{
+					Host: pulumi.String(ingress.Host),
+					Http: &networkingv1.HTTPIngressRuleValueArgs{
+						Paths: networkingv1.HTTPIngressPathArray{
+							networkingv1.HTTPIngressPathArgs{
+								Path:     pulumi.String(backend.Path),
+								PathType: pulumi.String("Prefix"),
+								Backend: &networkingv1.IngressBackendArgs{
+									Service: &networkingv1.IngressServiceBackendArgs{
+										Name: pulumi.String(backend.Service),
+										Port: &networkingv1.ServiceBackendPortArgs{
+											Number: pulumi.Int(backend.Port),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Tls: tls,
+		}
+		if ingress.ClassName != "" {
+			spec.IngressClassName = pulumi.String(ingress.ClassName)
+		}
+		_, err := networkingv1.NewIngress(ctx, names.Resource(suffix), &networkingv1.IngressArgs{
+			Metadata: metadata,
+			Spec:     spec,
+		}, opts...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newHTTPRoute attaches a route with a rule per backend to ingress.Gateway.
+func newHTTPRoute(ctx *pulumi.Context, names KubernetesNames, ingress *Ingress, backends []IngressBackend, opts ...pulumi.ResourceOption) error {
+	parent := pulumi.Map{"name": pulumi.String(ingress.Gateway)}
+	if namespace, name, ok := strings.Cut(ingress.Gateway, "/"); ok {
+		parent = pulumi.Map{"name": pulumi.String(name), "namespace": pulumi.String(namespace)}
+	}
+
+	var rules pulumi.Array
+	for _, backend := range backends {
+		rule := pulumi.Map{
+			"matches": pulumi.Array{
+				pulumi.Map{
+					"path": pulumi.Map{
+						"type":  pulumi.String("PathPrefix"),
+						"value": pulumi.String(backend.Path),
+					},
+				},
+			},
+			"backendRefs": pulumi.Array{
+				pulumi.Map{
+					"name": pulumi.String(backend.Service),
+					"port": pulumi.Int(backend.Port),
+				},
+			},
+		}
+		if backend.Path != "/" {
+			rule["filters"] = pulumi.Array{
+				pulumi.Map{
+					"type": pulumi.String("URLRewrite"),
+					"urlRewrite": pulumi.Map{
+						"path": pulumi.Map{
+							"type":               pulumi.String("ReplacePrefixMatch"),
+							"replacePrefixMatch": pulumi.String("/"),
+						},
+					},
+				},
+			}
+		}
+		rules = append(rules, rule)
+	}
+
+	metadata := names.Metadata("rpc-route")
+	metadata.Annotations = pulumi.ToStringMap(ingress.Annotations)
+	_, err := apiextensions.NewCustomResource(ctx, names.Resource("rpc-route"), &apiextensions.CustomResourceArgs{
+		ApiVersion: pulumi.String("gateway.networking.k8s.io/v1"),
+		Kind:       pulumi.String("HTTPRoute"),
+		Metadata:   metadata,
+		OtherFields: kubernetes.UntypedArgs{
+			"spec": pulumi.Map{
+				"parentRefs": pulumi.Array{parent},
+				"hostnames":  pulumi.StringArray{pulumi.String(ingress.Host)},
+				"rules":      rules,
+			},
+		},
+	}, opts...)
+	return err
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"regexp"
+
+	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// kubernetesNamePattern matches RFC 1123 labels, the names services and
+// namespaces need to have.
+var kubernetesNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// ValidateKubernetesName records a problem with field in errs when value is
+// set but can't be used in kubernetes object names.
+func ValidateKubernetesName(errs *ValidationErrors, field string, value string) {
+	if value != "" && (len(value) > 63 || !kubernetesNamePattern.MatchString(value)) {
+		errs.Add(field, "%q is not a valid kubernetes name, expected lowercase alphanumerics and '-'", value)
+	}
+}
+
+// KubernetesNames derives the names of the kubernetes objects of a component
+// from the component's name, e.g. reth-data and reth-rpc-service, so several
+// components can share a namespace as long as their names differ.
+type KubernetesNames struct {
+	// Name starts every object name, usually args.Name or the client.
+	Name string
+	// Namespace the objects are created in, the provider's namespace when empty.
+	Namespace string
+	// PartOf is the app.kubernetes.io/part-of label of the objects.
+	PartOf string
+	// Legacy holds the names objects had before they were derived from Name
+	// by suffix. They are kept so existing objects, like the data volumes,
+	// aren't replaced.
+	Legacy map[string]string
+}
+
+// Object returns the name of the object with suffix, Name itself for an
+// empty suffix.
+func (names KubernetesNames) Object(suffix string) string {
+	if legacy, ok := names.Legacy[suffix]; ok {
+		return legacy
+	}
+	return names.derived(suffix)
+}
+
+// derived returns the name of the object with suffix derived from Name.
+func (names KubernetesNames) derived(suffix string) string {
+	if suffix == "" {
+		return names.Name
+	}
+	return names.Name + "-" + suffix
+}
+
+// Resource returns the pulumi resource name of the object with suffix. It is
+// qualified with the namespace so components of the same name in different
+// namespaces can be deployed from one stack.
+func (names KubernetesNames) Resource(suffix string) string {
+	if names.Namespace == "" {
+		return names.derived(suffix)
+	}
+	return names.Namespace + "-" + names.derived(suffix)
+}
+
+// Aliases returns the option adopting the resource of the object with suffix
+// from stacks that named it like its legacy object.
+func (names KubernetesNames) Aliases(suffix string) pulumi.ResourceOption {
+	legacy, ok := names.Legacy[suffix]
+	if !ok || legacy == names.Resource(suffix) {
+		return pulumi.Aliases(nil)
+	}
+	return pulumi.Aliases([]pulumi.Alias{{Name: pulumi.String(legacy)}})
+}
+
+// Labels returns the labels of the object with suffix.
+func (names KubernetesNames) Labels(suffix string) pulumi.StringMap {
+	return pulumi.StringMap{
+		"app.kubernetes.io/name":     pulumi.String(names.Object(suffix)),
+		"app.kubernetes.io/instance": pulumi.String(names.Name),
+		"app.kubernetes.io/part-of":  pulumi.String(names.PartOf),
+	}
+}
+
+// Metadata returns the name, namespace and labels of the object with suffix.
+func (names KubernetesNames) Metadata(suffix string) *metav1.ObjectMetaArgs {
+	metadata := &metav1.ObjectMetaArgs{
+		Name:   pulumi.String(names.Object(suffix)),
+		Labels: names.Labels(suffix),
+	}
+	if names.Namespace != "" {
+		metadata.Namespace = pulumi.String(names.Namespace)
+	}
+	return metadata
+}
+
+// Selector returns the labels selecting the pods of the component.
+func (names KubernetesNames) Selector() pulumi.StringMap {
+	return pulumi.StringMap{"app": pulumi.String(names.Name)}
+}
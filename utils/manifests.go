@@ -0,0 +1,211 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/local"
+	"github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes"
+	"github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/apiextensions"
+	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
+	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+const (
+	// SecretsReference renders no Secret objects, the objects referring to
+	// them expect the secrets to be provisioned out of band, e.g. by an
+	// external secrets operator.
+	SecretsReference = "reference"
+	// SecretsSealed renders SealedSecret objects encrypted with kubeseal for
+	// the sealed-secrets controller of the cluster.
+	SecretsSealed = "sealed"
+)
+
+// SecretsModes are the ways secrets are written when rendering manifests.
+var SecretsModes = []string{SecretsReference, SecretsSealed}
+
+// DefaultNamespace is the namespace rendered objects are written to when
+// their component sets none.
+const DefaultNamespace = "default"
+
+// ManifestRendering describes how the kubernetes objects of a component are
+// written to a directory as YAML instead of being applied to a cluster, e.g.
+// for a GitOps repository synced by Argo CD.
+type ManifestRendering struct {
+	// Dir is the directory the manifests are written to.
+	Dir string
+	// Secrets is SecretsReference (the default) or SecretsSealed.
+	Secrets string
+	// SealedSecretsCert is the public certificate of the sealed-secrets
+	// controller secrets are sealed with, required for SecretsSealed.
+	SealedSecretsCert string
+	// Namespace is the namespace of the component, DefaultNamespace when
+	// empty. Objects without one are written with it and secrets are sealed
+	// for it, so they decrypt where they are applied.
+	Namespace string
+}
+
+// ValidateManifestRendering records problems with the manifest rendering
+// settings of a component's args in errs. The identity of a node can't be
+// queried when its objects are only rendered, so queryNodeIdentity is
+// rejected with dir.
+func ValidateManifestRendering(errs *ValidationErrors, deploymentType string, kubernetesType string, dir string, secrets string, sealedSecretsCert string, queryNodeIdentity bool) {
+	if dir == "" {
+		if secrets != "" {
+			errs.Add("RenderSecrets", "only supported when RenderManifestsTo is set")
+		}
+		return
+	}
+	if deploymentType != kubernetesType {
+		errs.Add("RenderManifestsTo", "only supported for %s deployments", kubernetesType)
+	}
+	if queryNodeIdentity {
+		errs.Add("QueryNodeIdentity", "not supported when RenderManifestsTo is set")
+	}
+	if secrets != "" && !slices.Contains(SecretsModes, secrets) {
+		errs.Add("RenderSecrets", "unknown secrets mode %q, expected one of %s", secrets, strings.Join(SecretsModes, ", "))
+	}
+	if secrets == SecretsSealed && sealedSecretsCert == "" {
+		errs.Add("SealedSecretsCert", "required for %s secrets", SecretsSealed)
+	}
+}
+
+// NewManifestRendering returns how the kubernetes objects of the component
+// called name are rendered to a directory of its own below dir, nil when dir
+// is empty and they are applied to the cluster. secrets defaults to
+// SecretsReference.
+func NewManifestRendering(dir string, name string, secrets string, sealedSecretsCert string, namespace string) *ManifestRendering {
+	if dir == "" {
+		return nil
+	}
+	if secrets == "" {
+		secrets = SecretsReference
+	}
+	return &ManifestRendering{
+		Dir:               path.Join(dir, name),
+		Secrets:           secrets,
+		SealedSecretsCert: sealedSecretsCert,
+		Namespace:         namespace,
+	}
+}
+
+// NewManifestProvider creates a kubernetes provider writing the objects of
+// the resources using it to rendering.Dir instead of applying them.
+func NewManifestProvider(ctx *pulumi.Context, name string, rendering *ManifestRendering, opts ...pulumi.ResourceOption) (*kubernetes.Provider, error) {
+	return kubernetes.NewProvider(ctx, name, &kubernetes.ProviderArgs{
+		RenderYamlToDirectory: pulumi.String(rendering.Dir),
+		Namespace:             pulumi.String(rendering.namespace()),
+	}, opts...)
+}
+
+// namespace returns the namespace the rendered objects are applied to.
+func (rendering *ManifestRendering) namespace() string {
+	if rendering.Namespace == "" {
+		return DefaultNamespace
+	}
+	return rendering.Namespace
+}
+
+type KubernetesSecretArgs struct {
+	Name       string
+	Namespace  string
+	Labels     pulumi.StringMap
+	StringData pulumi.StringMap
+	// Rendering is set when the manifests of the component are rendered,
+	// it decides how the secret is written.
+	Rendering *ManifestRendering
+}
+
+// NewKubernetesSecret creates the Secret described by args and returns its
+// name for the objects referring to it. When manifests are rendered the
+// secret is either left out or sealed, so no plain secret values end up in
+// the rendered directory.
+func NewKubernetesSecret(ctx *pulumi.Context, name string, args *KubernetesSecretArgs, opts ...pulumi.ResourceOption) (pulumi.StringOutput, error) {
+	metadata := &metav1.ObjectMetaArgs{
+		Name:   pulumi.String(args.Name),
+		Labels: args.Labels,
+	}
+	if args.Namespace != "" {
+		metadata.Namespace = pulumi.String(args.Namespace)
+	}
+
+	switch {
+	case args.Rendering == nil:
+		secret, err := corev1.NewSecret(ctx, name, &corev1.SecretArgs{
+			StringData: args.StringData,
+			Metadata:   metadata,
+		}, opts...)
+		if err != nil {
+			return pulumi.StringOutput{}, err
+		}
+		return secret.Metadata.Name().Elem(), nil
+	case args.Rendering.Secrets == SecretsSealed:
+		sealed, err := sealSecret(ctx, name, args, opts...)
+		if err != nil {
+			return pulumi.StringOutput{}, err
+		}
+		_, err = apiextensions.NewCustomResource(ctx, name, &apiextensions.CustomResourceArgs{
+			ApiVersion: pulumi.String("bitnami.com/v1alpha1"),
+			Kind:       pulumi.String("SealedSecret"),
+			Metadata:   metadata,
+			OtherFields: kubernetes.UntypedArgs{
+				"spec": pulumi.Map{
+					"encryptedData": sealed,
+					"template": pulumi.Map{
+						"metadata": pulumi.Map{
+							"name":   pulumi.String(args.Name),
+							"labels": args.Labels,
+						},
+					},
+				},
+			},
+		}, opts...)
+		if err != nil {
+			return pulumi.StringOutput{}, err
+		}
+	}
+	return pulumi.String(args.Name).ToStringOutput(), nil
+}
+
+// sealSecret encrypts the data of the secret with kubeseal and the
+// certificate of the rendering for the namespace it is applied to, it
+// returns the encrypted data by key.
+func sealSecret(ctx *pulumi.Context, name string, args *KubernetesSecretArgs, opts ...pulumi.ResourceOption) (pulumi.StringMapOutput, error) {
+	namespace := args.Namespace
+	if namespace == "" {
+		namespace = args.Rendering.namespace()
+	}
+	manifest := args.StringData.ToStringMapOutput().ApplyT(func(data map[string]string) (string, error) {
+		secret, err := json.Marshal(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]string{"name": args.Name, "namespace": namespace},
+			"stringData": data,
+		})
+		return string(secret), err
+	}).(pulumi.StringOutput)
+
+	seal, err := local.NewCommand(ctx, fmt.Sprintf("%s-seal", name), &local.CommandArgs{
+		Create: pulumi.Sprintf("kubeseal --cert %s --format json", shellQuote(args.Rendering.SealedSecretsCert)),
+		Stdin:  manifest,
+	}, opts...)
+	if err != nil {
+		return pulumi.StringMapOutput{}, err
+	}
+
+	return seal.Stdout.ApplyT(func(stdout string) (map[string]string, error) {
+		var sealed struct {
+			Spec struct {
+				EncryptedData map[string]string `json:"encryptedData"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal([]byte(stdout), &sealed); err != nil {
+			return nil, fmt.Errorf("reading sealed secret %s: %w", args.Name, err)
+		}
+		return sealed.Spec.EncryptedData, nil
+	}).(pulumi.StringMapOutput), nil
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+const (
+	// DefaultProbePeriodSeconds is how often the probes run unless configured.
+	DefaultProbePeriodSeconds = 10
+	// DefaultProbeFailureThreshold is how many liveness or readiness checks
+	// have to fail in a row before the container is restarted or taken out
+	// of its services.
+	DefaultProbeFailureThreshold = 6
+	// DefaultStartupProbeFailureThreshold gives clients two hours to open
+	// their database or initialize their state before liveness applies.
+	DefaultStartupProbeFailureThreshold = 720
+)
+
+// Probes are the probe settings of a component's client container.
+type Probes struct {
+	// Disabled leaves the container without probes.
+	Disabled bool
+	// PeriodSeconds, FailureThreshold and StartupFailureThreshold fall back
+	// to the defaults above when zero.
+	PeriodSeconds           int
+	FailureThreshold        int
+	StartupFailureThreshold int
+}
+
+// ProbeArgs are the probe fields components embed in their args, each sets
+// the field of Probes it is named after, DisableProbes sets Disabled.
+type ProbeArgs struct {
+	DisableProbes                bool
+	ProbePeriodSeconds           int
+	ProbeFailureThreshold        int
+	StartupProbeFailureThreshold int
+}
+
+// Probes returns the probe settings of args.
+func (args ProbeArgs) Probes() Probes {
+	return Probes{
+		Disabled:                args.DisableProbes,
+		PeriodSeconds:           args.ProbePeriodSeconds,
+		FailureThreshold:        args.ProbeFailureThreshold,
+		StartupFailureThreshold: args.StartupProbeFailureThreshold,
+	}
+}
+
+// ContainerProbes are the startup, liveness and readiness probes of a container.
+type ContainerProbes struct {
+	Startup   *corev1.ProbeArgs
+	Liveness  *corev1.ProbeArgs
+	Readiness *corev1.ProbeArgs
+}
+
+// ValidateProbes records problems with the probe settings of a component's
+// args in errs.
+func ValidateProbes(errs *ValidationErrors, deploymentType string, kubernetesType string, probes Probes) {
+	for _, setting := range []struct {
+		field string
+		value int
+	}{
+		{"ProbePeriodSeconds", probes.PeriodSeconds},
+		{"ProbeFailureThreshold", probes.FailureThreshold},
+		{"StartupProbeFailureThreshold", probes.StartupFailureThreshold},
+	} {
+		if setting.value < 0 {
+			errs.Add(setting.field, "must not be negative")
+		} else if setting.value > 0 && deploymentType != kubernetesType {
+			errs.Add(setting.field, "only supported for %s deployments", kubernetesType)
+		}
+	}
+}
+
+// HttpProbes returns probes getting paths on port: the startup and liveness
+// probes get livePath, the readiness probe readyPath. The startup probe runs
+// at the same period but allows StartupFailureThreshold failures.
+func (probes Probes) HttpProbes(port int, livePath string, readyPath string) ContainerProbes {
+	if probes.Disabled {
+		return ContainerProbes{}
+	}
+	period := probes.PeriodSeconds
+	if period == 0 {
+		period = DefaultProbePeriodSeconds
+	}
+	failures := probes.FailureThreshold
+	if failures == 0 {
+		failures = DefaultProbeFailureThreshold
+	}
+	startupFailures := probes.StartupFailureThreshold
+	if startupFailures == 0 {
+		startupFailures = DefaultStartupProbeFailureThreshold
+	}
+
+	probe := func(path string, failureThreshold int) *corev1.ProbeArgs {
+		return &corev1.ProbeArgs{
+			HttpGet: &corev1.HTTPGetActionArgs{
+				Path: pulumi.String(path),
+				Port: pulumi.Int(port),
+			},
+			PeriodSeconds:    pulumi.Int(period),
+			TimeoutSeconds:   pulumi.Int(5),
+			FailureThreshold: pulumi.Int(failureThreshold),
+		}
+	}
+	return ContainerProbes{
+		Startup:   probe(livePath, startupFailures),
+		Liveness:  probe(livePath, failures),
+		Readiness: probe(readyPath, failures),
+	}
+}
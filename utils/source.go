@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
+)
+
+// SourceRepo is the git repository a client is built from and the branch,
+// tag or commit that is checked out.
+type SourceRepo struct {
+	Url string
+	Ref string
+}
+
+// WithDefaults returns repo with its empty fields taken from defaults.
+func (repo SourceRepo) WithDefaults(defaults SourceRepo) SourceRepo {
+	if repo.Url == "" {
+		repo.Url = defaults.Url
+	}
+	if repo.Ref == "" {
+		repo.Ref = defaults.Ref
+	}
+	return repo
+}
+
+// CloneCommand returns the shell command cloning repo into dir and checking out its ref.
+func (repo SourceRepo) CloneCommand(dir string) string {
+	return fmt.Sprintf("git clone %s %s && cd %s && git checkout %s", repo.Url, dir, dir, repo.Ref)
+}
+
+// CheckoutCommand returns the shell command moving an existing clone in dir to
+// repo's ref, branches are fast forwarded to their latest commit.
+func (repo SourceRepo) CheckoutCommand(dir string) string {
+	return fmt.Sprintf("cd %s && git fetch --tags origin && git checkout %s && if git symbolic-ref -q HEAD > /dev/null; then git pull --ff-only; fi", dir, repo.Ref)
+}
+
+// SourceRepoFromConfig reads a SourceRepo from the Pulumi config keys urlKey
+// and refKey of the current project. Keys that are not set are left empty so
+// the client defaults apply.
+//
+// Example usage:
+//
+//	repo := utils.SourceRepoFromConfig(ctx, "rethRepoURL", "rethGitBranch")
+//	args.SourceRepoUrl, args.SourceRef = repo.Url, repo.Ref
+func SourceRepoFromConfig(ctx *pulumi.Context, urlKey string, refKey string) SourceRepo {
+	cfg := config.New(ctx, "")
+	return SourceRepo{
+		Url: cfg.Get(urlKey),
+		Ref: cfg.Get(refKey),
+	}
+}
@@ -2,6 +2,9 @@ package utils
 
 import (
 	"fmt"
+	"path"
+	"sort"
+	"strings"
 
 	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
@@ -15,6 +18,9 @@ type ServiceComponentArgs struct {
 	Connection  *remote.ConnectionArgs
 	Network     string
 	ServiceType string
+	// Environment is written to a systemd drop-in for the service, start
+	// scripts can use it to pick up settings that are only known at deploy time.
+	Environment pulumi.StringMap
 }
 
 func NewServiceDefinitionComponent(ctx *pulumi.Context, name string, args *ServiceComponentArgs, opts ...pulumi.ResourceOption) (*ServiceDefinitionComponent, error) {
@@ -28,7 +34,7 @@ func NewServiceDefinitionComponent(ctx *pulumi.Context, name string, args *Servi
 		return nil, err
 	}
 
-	serviceDefinition, err := remote.NewCopyFile(ctx, fmt.Sprintf("createServiceDefinition-%s-%s", args.ServiceType, args.Network), &remote.CopyFileArgs{
+	serviceDefinition, err := remote.NewCopyFile(ctx, fmt.Sprintf("createServiceDefinition-%s", name), &remote.CopyFileArgs{
 		LocalPath:  pulumi.Sprintf("config/%s.%s.service", args.ServiceType, args.Network),
 		RemotePath: pulumi.Sprintf("/etc/systemd/system/%s.%s.service", args.ServiceType, args.Network),
 		Connection: args.Connection,
@@ -38,17 +44,33 @@ func NewServiceDefinitionComponent(ctx *pulumi.Context, name string, args *Servi
 		return nil, err
 	}
 
-	enableService, err := remote.NewCommand(ctx, fmt.Sprintf("enableService-%s-%s", args.ServiceType, args.Network), &remote.CommandArgs{
+	serviceDeps := []pulumi.Resource{serviceDefinition}
+	if len(args.Environment) > 0 {
+		dropInDir := fmt.Sprintf("/etc/systemd/system/%s.%s.service.d", args.ServiceType, args.Network)
+		environment, err := remote.NewCommand(ctx, fmt.Sprintf("serviceEnvironment-%s", name), &remote.CommandArgs{
+			Create:     pulumi.Sprintf("mkdir -p %s && cat > %s/environment.conf && systemctl daemon-reload", dropInDir, dropInDir),
+			Delete:     pulumi.Sprintf("rm -f %s/environment.conf && systemctl daemon-reload", dropInDir),
+			Stdin:      args.Environment.ToStringMapOutput().ApplyT(environmentDropIn).(pulumi.StringOutput),
+			Connection: args.Connection,
+		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{serviceDefinition}))
+		if err != nil {
+			ctx.Log.Error("Error writing "+args.ServiceType+" service environment", nil)
+			return nil, err
+		}
+		serviceDeps = append(serviceDeps, environment)
+	}
+
+	enableService, err := remote.NewCommand(ctx, fmt.Sprintf("enableService-%s", name), &remote.CommandArgs{
 		Create:     pulumi.Sprintf("systemctl enable %s", fmt.Sprintf("%s.%s", args.ServiceType, args.Network)),
 		Delete:     pulumi.Sprintf("systemctl disable %s", fmt.Sprintf("%s.%s", args.ServiceType, args.Network)),
 		Connection: args.Connection,
-	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{serviceDefinition}))
+	}, pulumi.Parent(component), pulumi.DependsOn(serviceDeps))
 	if err != nil {
 		ctx.Log.Error("Error enabling "+args.ServiceType+" service", nil)
 		return nil, err
 	}
 
-	_, err = remote.NewCommand(ctx, fmt.Sprintf("startService-%s-%s", args.ServiceType, args.Network), &remote.CommandArgs{
+	_, err = remote.NewCommand(ctx, fmt.Sprintf("startService-%s", name), &remote.CommandArgs{
 		Create:     pulumi.Sprintf("systemctl start %s", fmt.Sprintf("%s.%s", args.ServiceType, args.Network)),
 		Delete:     pulumi.Sprintf("systemctl stop %s", fmt.Sprintf("%s.%s", args.ServiceType, args.Network)),
 		Connection: args.Connection,
@@ -60,3 +82,43 @@ func NewServiceDefinitionComponent(ctx *pulumi.Context, name string, args *Servi
 
 	return component, nil
 }
+
+// dropInEscaper escapes an assignment for a quoted Environment line, systemd
+// unquotes backslashes and quotes and expands % specifiers in it.
+var dropInEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%")
+
+// environmentDropIn renders env as a systemd drop-in with one Environment line per variable.
+func environmentDropIn(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var dropIn strings.Builder
+	dropIn.WriteString("[Service]\n")
+	for _, key := range keys {
+		fmt.Fprintf(&dropIn, "Environment=\"%s\"\n", dropInEscaper.Replace(key+"="+env[key]))
+	}
+	return dropIn.String()
+}
+
+// JwtSecretPath is where source deployments expect the engine api jwt secret.
+const JwtSecretPath = "/data/shared/jwt.hex"
+
+// JwtSecretGroup is the group JwtSecretPath is readable by. The services of
+// the clients using it run with it as a supplementary group and their
+// containers are added to it.
+const JwtSecretGroup = "jwt"
+
+// NewJwtSecretFile writes jwt to JwtSecretPath on the remote host so the
+// execution and consensus client share the same secret. The file is only
+// readable by its owner and JwtSecretGroup, which is created unless it exists.
+func NewJwtSecretFile(ctx *pulumi.Context, name string, connection *remote.ConnectionArgs, jwt pulumi.StringInput, opts ...pulumi.ResourceOption) (*remote.Command, error) {
+	return remote.NewCommand(ctx, name, &remote.CommandArgs{
+		Create: pulumi.Sprintf("(getent group %s >/dev/null || groupadd --system %s) && mkdir -p %s && (umask 027 && cat > %s) && chgrp %s %s && chmod 640 %s",
+			JwtSecretGroup, JwtSecretGroup, path.Dir(JwtSecretPath), JwtSecretPath, JwtSecretGroup, JwtSecretPath, JwtSecretPath),
+		Stdin:      jwt,
+		Connection: connection,
+	}, opts...)
+}
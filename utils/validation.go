@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError describes a single problem with a field of a component's args.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationErrors collects every problem found while validating a component's
+// args so they can be reported together.
+type ValidationErrors []*ValidationError
+
+// Add records a problem with field.
+func (e *ValidationErrors) Add(field string, format string, a ...any) {
+	*e = append(*e, &ValidationError{Field: field, Message: fmt.Sprintf(format, a...)})
+}
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return "invalid args: " + strings.Join(messages, "; ")
+}
+
+// Unwrap allows errors.As to match individual ValidationError values.
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Err returns nil if no problems were recorded, otherwise e.
+func (e ValidationErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,135 @@
+package validatorClient
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// ValidatorClientSettings holds the settings a driver renders into client CLI flags.
+type ValidatorClientSettings struct {
+	Network string
+	// DataDir holds the client's slashing protection database and imported keys.
+	DataDir string
+	// KeystoresDir holds the EIP-2335 keystores, every keystore-x.json comes
+	// with a keystore-x.txt holding its password.
+	KeystoresDir string
+	// PasswordFile is the password shared by all keystores.
+	PasswordFile  string
+	BeaconNodeUrl string
+	FeeRecipient  string
+	MetricsPort   int
+	// Web3SignerUrl is the remote signer holding the keys, when set there are
+	// no keystores to import or arrange.
+	Web3SignerUrl string
+	// Web3SignerPublicKeys are the public keys of the validators held by the
+	// remote signer.
+	Web3SignerPublicKeys []string
+}
+
+// ValidatorClientSourceBuild describes how to build a validator client from source.
+type ValidatorClientSourceBuild struct {
+	// RepoDir is the directory the client repository is cloned into.
+	RepoDir string
+	// Repo is the default repository and ref to build, SourceRepoUrl and
+	// SourceRef of the args take precedence.
+	Repo utils.SourceRepo
+	// Steps are shell commands run in order after the repository has been cloned.
+	Steps []string
+	// Binary is the path of the built client binary on the host.
+	Binary string
+}
+
+// ValidatorClientDriver describes how to run a specific validator client.
+// Drivers for lighthouse, teku, prysm, lodestar and nimbus are registered by
+// default, additional clients can be added with RegisterValidatorClient.
+type ValidatorClientDriver interface {
+	// Client returns the name used to select this driver in ValidatorClientComponentArgs.Client.
+	Client() string
+	// SourceBuild returns how to build the client from source.
+	SourceBuild(args *ValidatorClientComponentArgs) *ValidatorClientSourceBuild
+	// DefaultImage returns the container image used when ValidatorClientImage is empty.
+	DefaultImage() string
+	// MetricsPort returns the port the client serves prometheus metrics on.
+	MetricsPort() int
+	// ContainerArgs returns the arguments put in front of RunArgs in a
+	// container, e.g. the binary for images without an entrypoint.
+	ContainerArgs() []string
+	// RunArgs renders the arguments of the binary running the validator client.
+	RunArgs(settings ValidatorClientSettings) []string
+	// ImportArgs renders the arguments of the binary importing the keystores
+	// before the client starts, nil when the client reads them directly.
+	ImportArgs(settings ValidatorClientSettings) []string
+	// LayoutScript renders a shell script arranging the keystores in the
+	// layout the client expects, empty when they are used as they are.
+	LayoutScript(settings ValidatorClientSettings) string
+	// GraffitiFlag returns the flag setting the block graffiti.
+	GraffitiFlag() string
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]ValidatorClientDriver)
+)
+
+// RegisterValidatorClient makes a validator client driver available to
+// NewValidatorClientComponent under driver.Client(). It panics if driver is
+// nil or a driver is already registered for the same client.
+func RegisterValidatorClient(driver ValidatorClientDriver) {
+	if driver == nil {
+		panic("validatorClient: RegisterValidatorClient driver is nil")
+	}
+
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if _, dup := drivers[driver.Client()]; dup {
+		panic("validatorClient: RegisterValidatorClient called twice for client " + driver.Client())
+	}
+	drivers[driver.Client()] = driver
+}
+
+// LookupValidatorClient returns the driver registered for client.
+func LookupValidatorClient(client string) (ValidatorClientDriver, bool) {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	driver, ok := drivers[client]
+	return driver, ok
+}
+
+// ValidatorClients returns the sorted names of all registered validator clients.
+func ValidatorClients() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	clients := make([]string, 0, len(drivers))
+	for client := range drivers {
+		clients = append(clients, client)
+	}
+	sort.Strings(clients)
+	return clients
+}
+
+// image returns the configured container image or the driver default.
+func image(driver ValidatorClientDriver, args *ValidatorClientComponentArgs) string {
+	if args.ValidatorClientImage != "" {
+		return args.ValidatorClientImage
+	}
+	return driver.DefaultImage()
+}
+
+// resourcePrefix returns the prefix of the client's kubernetes objects and
+// remote resources.
+func resourcePrefix(driver ValidatorClientDriver, args *ValidatorClientComponentArgs) string {
+	if args.Name != "" {
+		return args.Name
+	}
+	return driver.Client() + "-validator"
+}
+
+// graffitiArgs returns the graffiti flag when args set a graffiti.
+func graffitiArgs(driver ValidatorClientDriver, args *ValidatorClientComponentArgs) []string {
+	if args.Graffiti == "" {
+		return nil
+	}
+	return []string{driver.GraffitiFlag() + "=" + args.Graffiti}
+}
@@ -0,0 +1,239 @@
+package validatorClient
+
+import (
+	"fmt"
+	"path"
+
+	appsv1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/apps/v1"
+	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
+	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+const (
+	// keystoresMountPath is where the keystores secret is mounted in the pods.
+	keystoresMountPath = "/keystores"
+	// dataMountPath is where the data volume is mounted in the pods.
+	dataMountPath = "/data"
+	// layoutImage runs the drivers' keystore layout scripts.
+	layoutImage = "busybox:stable"
+)
+
+// deployKubernetes runs a validator client as a StatefulSet with the keystores
+// secret, a data volume for the slashing protection database and a metrics
+// service. Keystores are imported or arranged by init containers, with a
+// remote signer no keystores are mounted.
+func deployKubernetes(ctx *pulumi.Context, component *ValidatorClientComponent, driver ValidatorClientDriver, args *ValidatorClientComponentArgs) error {
+	prefix := resourcePrefix(driver, args)
+	metricsPort := driver.MetricsPort()
+
+	// Create a secret with the keystores and their passwords unless an existing one is used
+	keystoresSecretName := pulumi.String(args.KeystoresSecretName).ToStringOutput()
+	if args.KeystoresSecretName == "" && args.Web3SignerUrl == nil {
+		secret, err := corev1.NewSecret(ctx, fmt.Sprintf("%s-keystores", prefix), &corev1.SecretArgs{
+			StringData: keystoreFiles(args),
+			Metadata: &metav1.ObjectMetaArgs{
+				Name: pulumi.Sprintf("%s-keystores", prefix),
+				Labels: pulumi.StringMap{
+					"app.kubernetes.io/name":    pulumi.Sprintf("%s-keystores", prefix),
+					"app.kubernetes.io/part-of": pulumi.String(driver.Client()),
+				},
+			},
+		}, pulumi.Parent(component), pulumi.AdditionalSecretOutputs([]string{"stringData"}))
+		if err != nil {
+			return err
+		}
+		keystoresSecretName = secret.Metadata.Name().Elem()
+	}
+
+	_, err := corev1.NewPersistentVolumeClaim(ctx, fmt.Sprintf("%s-data", prefix), &corev1.PersistentVolumeClaimArgs{
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.Sprintf("%s-data", prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name":    pulumi.Sprintf("%s-data", prefix),
+				"app.kubernetes.io/part-of": pulumi.String(driver.Client()),
+			},
+		},
+		Spec: &corev1.PersistentVolumeClaimSpecArgs{
+			AccessModes: pulumi.StringArray{pulumi.String("ReadWriteOnce")},
+			Resources: &corev1.VolumeResourceRequirementsArgs{
+				Requests: pulumi.StringMap{
+					"storage": pulumi.String(args.PodStorageSize),
+				},
+			},
+			StorageClassName: pulumi.String(args.PodStorageClass),
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	volumeMounts := corev1.VolumeMountArray{
+		corev1.VolumeMountArgs{
+			Name:      pulumi.Sprintf("%s-data", prefix),
+			MountPath: pulumi.String(dataMountPath),
+		},
+	}
+	volumes := corev1.VolumeArray{
+		corev1.VolumeArgs{
+			Name: pulumi.Sprintf("%s-data", prefix),
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSourceArgs{
+				ClaimName: pulumi.Sprintf("%s-data", prefix),
+			},
+		},
+	}
+	if args.Web3SignerUrl == nil {
+		volumeMounts = append(volumeMounts, corev1.VolumeMountArgs{
+			Name:      pulumi.Sprintf("%s-keystores", prefix),
+			MountPath: pulumi.String(keystoresMountPath),
+			ReadOnly:  pulumi.Bool(true),
+		})
+		volumes = append(volumes, corev1.VolumeArgs{
+			Name: pulumi.Sprintf("%s-keystores", prefix),
+			Secret: &corev1.SecretVolumeSourceArgs{
+				SecretName: keystoresSecretName,
+			},
+		})
+	}
+	settings := ValidatorClientSettings{
+		Network:              args.Network,
+		DataDir:              dataMountPath,
+		KeystoresDir:         keystoresMountPath,
+		PasswordFile:         path.Join(keystoresMountPath, "password.txt"),
+		FeeRecipient:         args.FeeRecipient,
+		MetricsPort:          metricsPort,
+		Web3SignerPublicKeys: args.Web3SignerPublicKeys,
+	}
+	// which init containers are needed only depends on whether there is a
+	// remote signer, their arguments on its url
+	planned := settings
+	if args.Web3SignerUrl != nil {
+		planned.Web3SignerUrl = "web3signer"
+	}
+
+	// keystores are arranged and imported into the data volume before the client starts
+	var initContainers corev1.ContainerArray
+	if driver.LayoutScript(planned) != "" {
+		initContainers = append(initContainers, corev1.ContainerArgs{
+			Name:  pulumi.String("keystore-layout"),
+			Image: pulumi.String(layoutImage),
+			Command: web3SignerUrl(args).ApplyT(func(url string) []string {
+				layout := settings
+				layout.Web3SignerUrl = url
+				return []string{"sh", "-c", driver.LayoutScript(layout)}
+			}).(pulumi.StringArrayOutput),
+			VolumeMounts: volumeMounts,
+		})
+	}
+	if importArgs := driver.ImportArgs(planned); importArgs != nil {
+		initContainers = append(initContainers, corev1.ContainerArgs{
+			Name:         pulumi.String("keystore-import"),
+			Image:        pulumi.String(image(driver, args)),
+			Args:         pulumi.ToStringArray(append(driver.ContainerArgs(), importArgs...)),
+			VolumeMounts: volumeMounts,
+		})
+	}
+
+	container := corev1.ContainerArgs{
+		Name:    pulumi.String(prefix),
+		Image:   pulumi.String(image(driver, args)),
+		Command: pulumi.ToStringArray(args.ValidatorClientContainerCommands),
+		// explicit commands can refer to the beacon node as $(BEACON_NODE_URL)
+		Env: corev1.EnvVarArray{
+			corev1.EnvVarArgs{
+				Name:  pulumi.String("BEACON_NODE_URL"),
+				Value: beaconNodeUrl(args),
+			},
+		},
+		Ports: corev1.ContainerPortArray{
+			corev1.ContainerPortArgs{
+				ContainerPort: pulumi.Int(metricsPort),
+			},
+		},
+		VolumeMounts: volumeMounts,
+		Resources: &corev1.ResourceRequirementsArgs{
+			Limits: pulumi.StringMap{
+				"cpu":    pulumi.String(args.CpuLimit),
+				"memory": pulumi.String(args.MemoryLimit),
+			},
+			Requests: pulumi.StringMap{
+				"cpu":    pulumi.String(args.CpuRequest),
+				"memory": pulumi.String(args.MemoryRequest),
+			},
+		},
+	}
+	// without explicit commands the image entrypoint is run with the driver's default flags
+	if len(args.ValidatorClientContainerCommands) == 0 {
+		container.Args = pulumi.All(beaconNodeUrl(args), web3SignerUrl(args)).ApplyT(func(urls []interface{}) []string {
+			settings.BeaconNodeUrl = urls[0].(string)
+			settings.Web3SignerUrl = urls[1].(string)
+			runArgs := append(driver.ContainerArgs(), driver.RunArgs(settings)...)
+			return append(runArgs, graffitiArgs(driver, args)...)
+		}).(pulumi.StringArrayOutput)
+	}
+
+	_, err = appsv1.NewStatefulSet(ctx, fmt.Sprintf("%s-set", prefix), &appsv1.StatefulSetArgs{
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.String(prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name":    pulumi.Sprintf("%s-set", prefix),
+				"app.kubernetes.io/part-of": pulumi.String(driver.Client()),
+			},
+		},
+		Spec: &appsv1.StatefulSetSpecArgs{
+			// a second replica would sign with the same keys and get slashed
+			Replicas: pulumi.Int(1),
+			Selector: &metav1.LabelSelectorArgs{
+				MatchLabels: pulumi.StringMap{
+					"app": pulumi.String(prefix),
+				},
+			},
+			Template: &corev1.PodTemplateSpecArgs{
+				Metadata: &metav1.ObjectMetaArgs{
+					Labels: pulumi.StringMap{
+						"app":                       pulumi.String(prefix),
+						"app.kubernetes.io/name":    pulumi.String(prefix),
+						"app.kubernetes.io/part-of": pulumi.String(driver.Client()),
+					},
+				},
+				Spec: &corev1.PodSpecArgs{
+					InitContainers: initContainers,
+					Containers:     corev1.ContainerArray{container},
+					DnsPolicy:      pulumi.String("ClusterFirst"),
+					Volumes:        volumes,
+				},
+			},
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	// create the metrics service
+	metricsService, err := corev1.NewService(ctx, fmt.Sprintf("%s-metrics-service", prefix), &corev1.ServiceArgs{
+		Spec: &corev1.ServiceSpecArgs{
+			Selector: pulumi.StringMap{"app": pulumi.String(prefix)},
+			Type:     pulumi.String("ClusterIP"),
+			Ports: corev1.ServicePortArray{
+				corev1.ServicePortArgs{
+					Port: pulumi.Int(metricsPort),
+					Name: pulumi.String("metrics"),
+				},
+			},
+		},
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.Sprintf("%s-metrics-service", prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name":    pulumi.Sprintf("%s-metrics-service", prefix),
+				"app.kubernetes.io/part-of": pulumi.String(driver.Client()),
+			},
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	component.MetricsUrl = pulumi.Sprintf("http://%s:%d", utils.ClusterServiceHost(metricsService), metricsPort)
+	return nil
+}
@@ -0,0 +1,89 @@
+package validatorClient
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/rswanson/node_deployer/utils"
+)
+
+func init() {
+	RegisterValidatorClient(lighthouseDriver{})
+}
+
+// lighthouseDriver is the built-in ValidatorClientDriver for lighthouse vc.
+type lighthouseDriver struct{}
+
+func (lighthouseDriver) Client() string { return Lighthouse }
+
+func (lighthouseDriver) SourceBuild(args *ValidatorClientComponentArgs) *ValidatorClientSourceBuild {
+	repoDir := fmt.Sprintf("/data/repos/%s/lighthouse-validator", args.Network)
+	return &ValidatorClientSourceBuild{
+		RepoDir: repoDir,
+		Repo:    utils.SourceRepo{Url: "https://github.com/sigp/lighthouse.git", Ref: "stable"},
+		Steps: []string{
+			"curl --proto '=https' --tlsv1.2 -sSf https://sh.rustup.rs | sh -s -- -y",
+			fmt.Sprintf("$HOME/.cargo/bin/cargo install --locked --path %s/lighthouse --bin lighthouse --root /data", repoDir),
+		},
+		Binary: "/data/bin/lighthouse",
+	}
+}
+
+func (lighthouseDriver) DefaultImage() string { return "sigp/lighthouse:latest" }
+
+func (lighthouseDriver) MetricsPort() int { return 5064 }
+
+// ContainerArgs names the binary, the lighthouse image has no entrypoint.
+func (lighthouseDriver) ContainerArgs() []string { return []string{"lighthouse"} }
+
+func (lighthouseDriver) RunArgs(settings ValidatorClientSettings) []string {
+	flags := []string{
+		"vc",
+		"--network", settings.Network,
+		"--datadir", settings.DataDir,
+		"--beacon-nodes", settings.BeaconNodeUrl,
+		"--metrics",
+		"--metrics-address", "0.0.0.0",
+		"--metrics-port", strconv.Itoa(settings.MetricsPort),
+	}
+	if settings.FeeRecipient != "" {
+		flags = append(flags, "--suggested-fee-recipient", settings.FeeRecipient)
+	}
+	if settings.Web3SignerUrl != "" {
+		// the slashing protection database is otherwise created by the import
+		flags = append(flags, "--init-slashing-protection")
+	}
+	return flags
+}
+
+func (lighthouseDriver) ImportArgs(settings ValidatorClientSettings) []string {
+	if settings.Web3SignerUrl != "" {
+		return nil
+	}
+	return []string{
+		"account", "validator", "import",
+		"--network", settings.Network,
+		"--datadir", settings.DataDir,
+		"--directory", settings.KeystoresDir,
+		"--password-file", settings.PasswordFile,
+		"--reuse-password",
+	}
+}
+
+// LayoutScript writes a validator definition per public key pointing at the
+// remote signer, lighthouse cannot fetch the keys from it.
+func (lighthouseDriver) LayoutScript(settings ValidatorClientSettings) string {
+	if settings.Web3SignerUrl == "" {
+		return ""
+	}
+	var definitions strings.Builder
+	for _, publicKey := range settings.Web3SignerPublicKeys {
+		fmt.Fprintf(&definitions, "- enabled: true\n  voting_public_key: \"%s\"\n  type: web3signer\n  url: \"%s\"\n", publicKey, settings.Web3SignerUrl)
+	}
+	file := path.Join(settings.DataDir, "validators", "validator_definitions.yml")
+	return fmt.Sprintf("set -e\nmkdir -p %s\ncat > %s <<'EOF'\n%sEOF\n", path.Dir(file), file, definitions.String())
+}
+
+func (lighthouseDriver) GraffitiFlag() string { return "--graffiti" }
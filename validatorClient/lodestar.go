@@ -0,0 +1,65 @@
+package validatorClient
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rswanson/node_deployer/utils"
+)
+
+func init() {
+	RegisterValidatorClient(lodestarDriver{})
+}
+
+// lodestarDriver is the built-in ValidatorClientDriver for lodestar validator.
+type lodestarDriver struct{}
+
+func (lodestarDriver) Client() string { return Lodestar }
+
+func (lodestarDriver) SourceBuild(args *ValidatorClientComponentArgs) *ValidatorClientSourceBuild {
+	repoDir := fmt.Sprintf("/data/repos/%s/lodestar-validator", args.Network)
+	return &ValidatorClientSourceBuild{
+		RepoDir: repoDir,
+		Repo:    utils.SourceRepo{Url: "https://github.com/ChainSafe/lodestar.git", Ref: "unstable"},
+		Steps: []string{
+			"sudo apt update && sudo apt install -y nodejs npm",
+			fmt.Sprintf("cd %s && npm install && npm run build", repoDir),
+		},
+		Binary: fmt.Sprintf("%s/lodestar", repoDir),
+	}
+}
+
+func (lodestarDriver) DefaultImage() string { return "chainsafe/lodestar:latest" }
+
+func (lodestarDriver) MetricsPort() int { return 5064 }
+
+func (lodestarDriver) ContainerArgs() []string { return nil }
+
+// RunArgs imports the keystores on every start, lodestar keeps them in memory.
+// With a remote signer the public keys are fetched from it.
+func (lodestarDriver) RunArgs(settings ValidatorClientSettings) []string {
+	flags := []string{
+		"validator",
+		"--network=" + settings.Network,
+		"--dataDir=" + settings.DataDir,
+		"--beaconNodes=" + settings.BeaconNodeUrl,
+		"--metrics",
+		"--metrics.address=0.0.0.0",
+		"--metrics.port=" + strconv.Itoa(settings.MetricsPort),
+	}
+	if settings.Web3SignerUrl != "" {
+		flags = append(flags, "--externalSigner.url="+settings.Web3SignerUrl, "--externalSigner.fetch")
+	} else {
+		flags = append(flags, "--importKeystores="+settings.KeystoresDir, "--importKeystoresPassword="+settings.PasswordFile)
+	}
+	if settings.FeeRecipient != "" {
+		flags = append(flags, "--suggestedFeeRecipient="+settings.FeeRecipient)
+	}
+	return flags
+}
+
+func (lodestarDriver) ImportArgs(settings ValidatorClientSettings) []string { return nil }
+
+func (lodestarDriver) LayoutScript(settings ValidatorClientSettings) string { return "" }
+
+func (lodestarDriver) GraffitiFlag() string { return "--graffiti" }
@@ -0,0 +1,82 @@
+package validatorClient
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rswanson/node_deployer/utils"
+)
+
+func init() {
+	RegisterValidatorClient(nimbusDriver{})
+}
+
+// nimbusDriver is the built-in ValidatorClientDriver for nimbus_validator_client.
+type nimbusDriver struct{}
+
+func (nimbusDriver) Client() string { return Nimbus }
+
+func (nimbusDriver) SourceBuild(args *ValidatorClientComponentArgs) *ValidatorClientSourceBuild {
+	repoDir := fmt.Sprintf("/data/repos/%s/nimbus-validator", args.Network)
+	return &ValidatorClientSourceBuild{
+		RepoDir: repoDir,
+		Repo:    utils.SourceRepo{Url: "https://github.com/status-im/nimbus-eth2.git", Ref: "stable"},
+		Steps: []string{
+			"sudo apt install -y git cmake build-essential",
+			fmt.Sprintf("cd %s && make -j4 nimbus_validator_client", repoDir),
+		},
+		Binary: fmt.Sprintf("%s/build/nimbus_validator_client", repoDir),
+	}
+}
+
+func (nimbusDriver) DefaultImage() string { return "statusim/nimbus-validator-client:multiarch-latest" }
+
+func (nimbusDriver) MetricsPort() int { return 8108 }
+
+func (nimbusDriver) ContainerArgs() []string { return nil }
+
+// RunArgs reads the keystores from the validators and secrets directories
+// in the data dir, see LayoutScript, or fetches them from the remote signer.
+// The network is taken from the beacon node.
+func (nimbusDriver) RunArgs(settings ValidatorClientSettings) []string {
+	flags := []string{
+		"--data-dir=" + settings.DataDir,
+		"--beacon-node=" + settings.BeaconNodeUrl,
+		"--metrics",
+		"--metrics-address=0.0.0.0",
+		"--metrics-port=" + strconv.Itoa(settings.MetricsPort),
+	}
+	if settings.Web3SignerUrl != "" {
+		flags = append(flags, "--web3-signer-url="+settings.Web3SignerUrl)
+	}
+	if settings.FeeRecipient != "" {
+		flags = append(flags, "--suggested-fee-recipient="+settings.FeeRecipient)
+	}
+	return flags
+}
+
+func (nimbusDriver) ImportArgs(settings ValidatorClientSettings) []string { return nil }
+
+// LayoutScript copies every keystore to validators/<pubkey>/keystore.json and
+// its password to secrets/<pubkey>, nimbus finds keystores by public key.
+func (nimbusDriver) LayoutScript(settings ValidatorClientSettings) string {
+	if settings.Web3SignerUrl != "" {
+		return ""
+	}
+	script := []string{
+		"set -e",
+		"mkdir -p DATA/validators DATA/secrets",
+		"for keystore in KEYS/*.json; do",
+		`  pubkey=$(sed -n 's/.*"pubkey" *: *"\(0x\)\{0,1\}\([0-9a-fA-F]*\)".*/\2/p' "$keystore" | head -n 1)`,
+		"  mkdir -p DATA/validators/0x$pubkey",
+		`  cp "$keystore" DATA/validators/0x$pubkey/keystore.json`,
+		`  cp "${keystore%.json}.txt" DATA/secrets/0x$pubkey`,
+		"done",
+		"chmod 700 DATA/secrets",
+		"chmod 600 DATA/secrets/*",
+	}
+	return strings.NewReplacer("DATA", settings.DataDir, "KEYS", settings.KeystoresDir).Replace(strings.Join(script, "\n"))
+}
+
+func (nimbusDriver) GraffitiFlag() string { return "--graffiti" }
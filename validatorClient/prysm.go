@@ -0,0 +1,84 @@
+package validatorClient
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/rswanson/node_deployer/utils"
+)
+
+func init() {
+	RegisterValidatorClient(prysmDriver{})
+}
+
+// prysmDriver is the built-in ValidatorClientDriver for prysm's validator.
+type prysmDriver struct{}
+
+func (prysmDriver) Client() string { return Prysm }
+
+func (prysmDriver) SourceBuild(args *ValidatorClientComponentArgs) *ValidatorClientSourceBuild {
+	repoDir := fmt.Sprintf("/data/repos/%s/prysm-validator", args.Network)
+	return &ValidatorClientSourceBuild{
+		RepoDir: repoDir,
+		Repo:    utils.SourceRepo{Url: "https://github.com/prysmaticlabs/prysm.git", Ref: "develop"},
+		Steps: []string{
+			"sudo apt update && sudo apt install -y golang-go",
+			fmt.Sprintf("cd %s && go build -o /data/bin/prysm-validator ./cmd/validator", repoDir),
+		},
+		Binary: "/data/bin/prysm-validator",
+	}
+}
+
+func (prysmDriver) DefaultImage() string { return "gcr.io/prysmaticlabs/prysm/validator:stable" }
+
+func (prysmDriver) MetricsPort() int { return 8081 }
+
+func (prysmDriver) ContainerArgs() []string { return nil }
+
+// RunArgs connects to the beacon node's REST api, the wallet is protected
+// with the keystore password. With a remote signer the public keys are
+// fetched from it.
+func (prysmDriver) RunArgs(settings ValidatorClientSettings) []string {
+	flags := []string{
+		"--" + settings.Network,
+		"--accept-terms-of-use",
+		"--datadir=" + settings.DataDir,
+		"--enable-beacon-rest-api",
+		"--beacon-rest-api-provider=" + settings.BeaconNodeUrl,
+		"--monitoring-host=0.0.0.0",
+		"--monitoring-port=" + strconv.Itoa(settings.MetricsPort),
+	}
+	if settings.Web3SignerUrl != "" {
+		flags = append(flags,
+			"--validators-external-signer-url="+settings.Web3SignerUrl,
+			"--validators-external-signer-public-keys="+strings.TrimSuffix(settings.Web3SignerUrl, "/")+"/api/v1/eth2/publicKeys",
+		)
+	} else {
+		flags = append(flags, "--wallet-dir="+path.Join(settings.DataDir, "wallet"), "--wallet-password-file="+settings.PasswordFile)
+	}
+	if settings.FeeRecipient != "" {
+		flags = append(flags, "--suggested-fee-recipient="+settings.FeeRecipient)
+	}
+	return flags
+}
+
+func (prysmDriver) ImportArgs(settings ValidatorClientSettings) []string {
+	if settings.Web3SignerUrl != "" {
+		return nil
+	}
+	return []string{
+		"accounts", "import",
+		"--" + settings.Network,
+		"--accept-terms-of-use",
+		"--keys-dir=" + settings.KeystoresDir,
+		"--wallet-dir=" + path.Join(settings.DataDir, "wallet"),
+		"--wallet-password-file=" + settings.PasswordFile,
+		"--account-password-file=" + settings.PasswordFile,
+	}
+}
+
+func (prysmDriver) LayoutScript(settings ValidatorClientSettings) string { return "" }
+
+func (prysmDriver) GraffitiFlag() string { return "--graffiti" }
@@ -0,0 +1,199 @@
+package validatorClient
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// startScript is the start script shared by all validator clients, it runs
+// VALIDATOR_COMMAND from the service environment.
+const startScript = "start_validator.sh"
+
+// deploySource clones and builds a validator client on the remote host, writes
+// the keystores readable only by the client user, unless a remote signer holds
+// them, and runs it as a systemd service.
+func deploySource(ctx *pulumi.Context, component *ValidatorClientComponent, driver ValidatorClientDriver, args *ValidatorClientComponentArgs) error {
+	prefix := resourcePrefix(driver, args)
+	build := driver.SourceBuild(args)
+	user := driver.Client()
+	dataDir := args.DataDir
+	if dataDir == "" {
+		dataDir = fmt.Sprintf("/data/%s/%s-validator", args.Network, driver.Client())
+	}
+	keystoresDir := path.Join(dataDir, "keystores")
+	settings := ValidatorClientSettings{
+		Network:              args.Network,
+		DataDir:              dataDir,
+		KeystoresDir:         keystoresDir,
+		PasswordFile:         path.Join(keystoresDir, "password.txt"),
+		FeeRecipient:         args.FeeRecipient,
+		MetricsPort:          driver.MetricsPort(),
+		Web3SignerPublicKeys: args.Web3SignerPublicKeys,
+	}
+	// which steps are needed only depends on whether there is a remote signer
+	planned := settings
+	if args.Web3SignerUrl != nil {
+		planned.Web3SignerUrl = "web3signer"
+	}
+
+	dataDirs, err := remote.NewCommand(ctx, fmt.Sprintf("createDataDir-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("mkdir -p %s && chmod 700 %s", keystoresDir, keystoresDir),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error creating data directory", nil)
+		return err
+	}
+
+	// clone repo
+	repo, err := remote.NewCommand(ctx, fmt.Sprintf("cloneRepo-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.String(utils.SourceRepo{Url: args.SourceRepoUrl, Ref: args.SourceRef}.WithDefaults(build.Repo).CloneCommand(build.RepoDir)),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error cloning repo", nil)
+		return err
+	}
+
+	// run the client specific build steps in order
+	var buildClient pulumi.Resource = repo
+	for i, step := range build.Steps {
+		buildClient, err = remote.NewCommand(ctx, fmt.Sprintf("buildStep%d-%s", i, prefix), &remote.CommandArgs{
+			Create:     pulumi.String(step),
+			Connection: args.Connection,
+		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{repo, buildClient}))
+		if err != nil {
+			ctx.Log.Error("Error building "+driver.Client(), nil)
+			return err
+		}
+	}
+
+	// write every keystore and password file through stdin so they never show up in a command line
+	files := pulumi.StringMap{}
+	if args.Web3SignerUrl == nil {
+		files = keystoreFiles(args)
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	keystoreDeps := []pulumi.Resource{dataDirs}
+	for _, name := range names {
+		file := path.Join(keystoresDir, name)
+		keystore, err := remote.NewCommand(ctx, fmt.Sprintf("writeKeystore-%s-%s", prefix, name), &remote.CommandArgs{
+			Create:     pulumi.Sprintf("cat > %s && chmod 600 %s", file, file),
+			Delete:     pulumi.Sprintf("rm -f %s", file),
+			Stdin:      files[name],
+			Connection: args.Connection,
+		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{dataDirs}))
+		if err != nil {
+			ctx.Log.Error("Error writing keystore "+name, nil)
+			return err
+		}
+		keystoreDeps = append(keystoreDeps, keystore)
+	}
+
+	owned, err := remote.NewCommand(ctx, fmt.Sprintf("setDataDirGroupPermissions-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chown -R %s:%s %s", user, user, dataDir),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn(keystoreDeps))
+	if err != nil {
+		ctx.Log.Error("Error setting group permissions", nil)
+		return err
+	}
+
+	// arrange and import the keystores as the client user before the service starts
+	serviceDeps := []pulumi.Resource{buildClient, owned}
+	if driver.LayoutScript(planned) != "" {
+		layout, err := remote.NewCommand(ctx, fmt.Sprintf("keystoreLayout-%s", prefix), &remote.CommandArgs{
+			Create: pulumi.Sprintf("sudo -u %s sh", user),
+			Stdin: web3SignerUrl(args).ApplyT(func(url string) string {
+				layout := settings
+				layout.Web3SignerUrl = url
+				return driver.LayoutScript(layout)
+			}).(pulumi.StringOutput),
+			Connection: args.Connection,
+		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{owned}))
+		if err != nil {
+			ctx.Log.Error("Error arranging keystores", nil)
+			return err
+		}
+		serviceDeps = append(serviceDeps, layout)
+	}
+	if importArgs := driver.ImportArgs(planned); importArgs != nil {
+		// import again whenever keystores are added or removed
+		triggers := pulumi.Array{}
+		for _, name := range names {
+			if _, ok := args.Keystores[name]; ok {
+				triggers = append(triggers, pulumi.String(name))
+			}
+		}
+		keystoreImport, err := remote.NewCommand(ctx, fmt.Sprintf("keystoreImport-%s", prefix), &remote.CommandArgs{
+			Create:     pulumi.Sprintf("sudo -u %s %s %s", user, build.Binary, strings.Join(importArgs, " ")),
+			Triggers:   triggers,
+			Connection: args.Connection,
+		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{buildClient, owned}))
+		if err != nil {
+			ctx.Log.Error("Error importing keystores", nil)
+			return err
+		}
+		serviceDeps = append(serviceDeps, keystoreImport)
+	}
+
+	// copy start script
+	copyStartScript, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyStartScript-%s", prefix), &remote.CopyFileArgs{
+		LocalPath:  pulumi.Sprintf("scripts/%s", startScript),
+		RemotePath: pulumi.Sprintf("/data/scripts/%s", startScript),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error copying start script", nil)
+		return err
+	}
+
+	// script permissions
+	scriptPerms, err := remote.NewCommand(ctx, fmt.Sprintf("scriptPermissions-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chmod 755 /data/scripts/%s", startScript),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{copyStartScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting script permissions", nil)
+		return err
+	}
+	serviceDeps = append(serviceDeps, scriptPerms)
+
+	// the start script runs the command from the service environment
+	environment := pulumi.StringMap{
+		"VALIDATOR_COMMAND": pulumi.All(beaconNodeUrl(args), web3SignerUrl(args)).ApplyT(func(urls []interface{}) string {
+			settings.BeaconNodeUrl = urls[0].(string)
+			settings.Web3SignerUrl = urls[1].(string)
+			return strings.Join(append([]string{build.Binary}, driver.RunArgs(settings)...), " ")
+		}).(pulumi.StringOutput),
+	}
+	if args.Graffiti != "" {
+		environment["GRAFFITI_FLAG"] = pulumi.String(driver.GraffitiFlag())
+		environment["GRAFFITI"] = pulumi.String(args.Graffiti)
+	}
+
+	// create service
+	_, err = utils.NewServiceDefinitionComponent(ctx, fmt.Sprintf("validatorService-%s", prefix), &utils.ServiceComponentArgs{
+		Connection:  args.Connection,
+		ServiceType: fmt.Sprintf("%s-validator", driver.Client()),
+		Network:     args.Network,
+		Environment: environment,
+	}, pulumi.Parent(component), pulumi.DependsOn(serviceDeps))
+	if err != nil {
+		ctx.Log.Error("Error creating validator service", nil)
+		return err
+	}
+
+	component.MetricsUrl = pulumi.Sprintf("http://%s:%d", args.Connection.Host, driver.MetricsPort())
+	return nil
+}
@@ -0,0 +1,67 @@
+package validatorClient
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rswanson/node_deployer/utils"
+)
+
+func init() {
+	RegisterValidatorClient(tekuDriver{})
+}
+
+// tekuDriver is the built-in ValidatorClientDriver for teku validator-client.
+type tekuDriver struct{}
+
+func (tekuDriver) Client() string { return Teku }
+
+func (tekuDriver) SourceBuild(args *ValidatorClientComponentArgs) *ValidatorClientSourceBuild {
+	repoDir := fmt.Sprintf("/data/repos/%s/teku-validator", args.Network)
+	return &ValidatorClientSourceBuild{
+		RepoDir: repoDir,
+		Repo:    utils.SourceRepo{Url: "https://github.com/Consensys/teku.git", Ref: "master"},
+		Steps: []string{
+			"sudo apt update && sudo apt install -y openjdk-21-jdk",
+			fmt.Sprintf("cd %s && ./gradlew installDist", repoDir),
+		},
+		Binary: fmt.Sprintf("%s/build/install/teku/bin/teku", repoDir),
+	}
+}
+
+func (tekuDriver) DefaultImage() string { return "consensys/teku:latest" }
+
+func (tekuDriver) MetricsPort() int { return 8008 }
+
+func (tekuDriver) ContainerArgs() []string { return nil }
+
+// RunArgs loads the keystores directly, teku finds the password of
+// keystore-x.json in keystore-x.txt. With a remote signer the public keys are
+// fetched from it.
+func (tekuDriver) RunArgs(settings ValidatorClientSettings) []string {
+	flags := []string{
+		"validator-client",
+		"--network=" + settings.Network,
+		"--data-path=" + settings.DataDir,
+		"--beacon-node-api-endpoint=" + settings.BeaconNodeUrl,
+		"--metrics-enabled=true",
+		"--metrics-interface=0.0.0.0",
+		"--metrics-port=" + strconv.Itoa(settings.MetricsPort),
+		"--metrics-host-allowlist=*",
+	}
+	if settings.Web3SignerUrl != "" {
+		flags = append(flags, "--validators-external-signer-url="+settings.Web3SignerUrl, "--validators-external-signer-public-keys=external-signer")
+	} else {
+		flags = append(flags, "--validator-keys="+settings.KeystoresDir+":"+settings.KeystoresDir)
+	}
+	if settings.FeeRecipient != "" {
+		flags = append(flags, "--validators-proposer-default-fee-recipient="+settings.FeeRecipient)
+	}
+	return flags
+}
+
+func (tekuDriver) ImportArgs(settings ValidatorClientSettings) []string { return nil }
+
+func (tekuDriver) LayoutScript(settings ValidatorClientSettings) string { return "" }
+
+func (tekuDriver) GraffitiFlag() string { return "--validators-graffiti" }
@@ -0,0 +1,246 @@
+package validatorClient
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/consensusClient"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+type ValidatorClientComponent struct {
+	pulumi.ResourceState
+
+	// MetricsUrl is the prometheus metrics endpoint.
+	MetricsUrl pulumi.StringOutput `pulumi:"metricsUrl"`
+}
+
+type ValidatorClientComponentArgs struct {
+	Connection     *remote.ConnectionArgs
+	Client         string
+	Network        string
+	DeploymentType string
+	DataDir        string
+	Name           string
+	// BeaconNodeUrl is the beacon api the validator client connects to. When
+	// nil the BeaconApiUrl of BeaconNode is used and the validator client is
+	// created after it.
+	BeaconNodeUrl pulumi.StringInput
+	BeaconNode    *consensusClient.ConsensusClientComponent
+	// Keystores maps file names like keystore-m_12381_3600_0_0_0.json to the
+	// EIP-2335 keystores, all encrypted with KeystorePassword. Both should be
+	// pulumi secrets, they end up in a kubernetes Secret or in files only
+	// readable by the client user on the host.
+	Keystores        pulumi.StringMap
+	KeystorePassword pulumi.StringInput
+	// KeystoresSecretName names an existing kubernetes Secret to mount instead
+	// of Keystores. It has to hold the keystores, a password.txt and for every
+	// keystore-x.json a keystore-x.txt with its password.
+	KeystoresSecretName              string
+	FeeRecipient                     string
+	Graffiti                         string
+	ValidatorClientImage             string
+	ValidatorClientContainerCommands []string
+	PodStorageClass                  string
+	PodStorageSize                   string
+	CpuLimit                         string
+	MemoryLimit                      string
+	CpuRequest                       string
+	MemoryRequest                    string
+	SourceRepoUrl                    string
+	SourceRef                        string
+	// Web3SignerUrl points the validator client at a remote signer, like the
+	// Url of a web3signer component, instead of loading Keystores.
+	Web3SignerUrl pulumi.StringInput
+	// Web3SignerPublicKeys lists the public keys held by the remote signer.
+	// Lighthouse cannot fetch them from the signer and requires them.
+	Web3SignerPublicKeys []string
+}
+
+const (
+	Lighthouse = consensusClient.Lighthouse
+	Teku       = consensusClient.Teku
+	Prysm      = consensusClient.Prysm
+	Lodestar   = consensusClient.Lodestar
+	Nimbus     = consensusClient.Nimbus
+	Source     = "source"
+	Kubernetes = "kubernetes"
+	Mainnet    = "mainnet"
+	Sepolia    = "sepolia"
+	Holesky    = "holesky"
+	Hoodi      = "hoodi"
+)
+
+var (
+	networks        = []string{Mainnet, Sepolia, Holesky, Hoodi}
+	deploymentTypes = []string{Source, Kubernetes}
+	feeRecipient    = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	publicKey       = regexp.MustCompile(`^0x[0-9a-fA-F]{96}$`)
+)
+
+// Validate checks the args for unknown clients, networks and deployment types
+// as well as the beacon node, keystores and settings required by the chosen
+// deployment type. All problems found are returned together as
+// utils.ValidationErrors.
+func (args *ValidatorClientComponentArgs) Validate() error {
+	var errs utils.ValidationErrors
+
+	if _, ok := LookupValidatorClient(args.Client); !ok {
+		errs.Add("Client", "unknown validator client %q, expected one of %s", args.Client, strings.Join(ValidatorClients(), ", "))
+	}
+	if !slices.Contains(networks, args.Network) {
+		errs.Add("Network", "unknown network %q, expected one of %s", args.Network, strings.Join(networks, ", "))
+	}
+	if !slices.Contains(deploymentTypes, args.DeploymentType) {
+		errs.Add("DeploymentType", "unknown deployment type %q, expected one of %s", args.DeploymentType, strings.Join(deploymentTypes, ", "))
+	}
+	if args.BeaconNodeUrl == nil && args.BeaconNode == nil {
+		errs.Add("BeaconNodeUrl", "required when BeaconNode is not set")
+	}
+	if args.FeeRecipient != "" && !feeRecipient.MatchString(args.FeeRecipient) {
+		errs.Add("FeeRecipient", "%q is not a 0x prefixed address", args.FeeRecipient)
+	}
+
+	if args.Web3SignerUrl != nil {
+		if len(args.Keystores) > 0 || args.KeystorePassword != nil || args.KeystoresSecretName != "" {
+			errs.Add("Web3SignerUrl", "cannot be combined with Keystores, KeystorePassword or KeystoresSecretName")
+		}
+		if args.Client == Lighthouse && len(args.Web3SignerPublicKeys) == 0 {
+			errs.Add("Web3SignerPublicKeys", "required for %s with a remote signer", Lighthouse)
+		}
+	} else if args.KeystoresSecretName != "" {
+		if len(args.Keystores) > 0 || args.KeystorePassword != nil {
+			errs.Add("KeystoresSecretName", "cannot be combined with Keystores or KeystorePassword")
+		}
+	} else {
+		if len(args.Keystores) == 0 {
+			errs.Add("Keystores", "required when KeystoresSecretName is not set")
+		}
+		if args.KeystorePassword == nil {
+			errs.Add("KeystorePassword", "required when KeystoresSecretName is not set")
+		}
+	}
+	for name := range args.Keystores {
+		if !strings.HasSuffix(name, ".json") || strings.ContainsAny(name, "/ ") {
+			errs.Add("Keystores", "%q is not a keystore file name", name)
+		}
+	}
+	for _, key := range args.Web3SignerPublicKeys {
+		if !publicKey.MatchString(key) {
+			errs.Add("Web3SignerPublicKeys", "%q is not a 0x prefixed public key", key)
+		}
+	}
+
+	switch args.DeploymentType {
+	case Source:
+		if args.Connection == nil {
+			errs.Add("Connection", "required for %s deployments", args.DeploymentType)
+		}
+		if args.KeystoresSecretName != "" {
+			errs.Add("KeystoresSecretName", "only supported for %s deployments", Kubernetes)
+		}
+	case Kubernetes:
+		if args.CpuLimit == "" {
+			errs.Add("CpuLimit", "required for %s deployments", args.DeploymentType)
+		}
+		if args.MemoryLimit == "" {
+			errs.Add("MemoryLimit", "required for %s deployments", args.DeploymentType)
+		}
+	}
+
+	return errs.Err()
+}
+
+// NewValidatorClientComponent creates a validator client connected to a beacon
+// node and loaded with the given keystores, or signing through the remote
+// signer at Web3SignerUrl. Source deployments build the
+// client on the ssh host and run it as a systemd service, kubernetes
+// deployments run it as a StatefulSet whose slashing protection database is
+// kept on a persistent volume. The args are validated before any resources
+// are registered.
+//
+// Example usage:
+//
+//	validator, err := validatorClient.NewValidatorClientComponent(ctx, "holeskyValidator", &validatorClient.ValidatorClientComponentArgs{
+//		Client:           "lighthouse",
+//		Network:          "holesky",
+//		DeploymentType:   "kubernetes",
+//		BeaconNode:       node.ConsensusClient,
+//		Keystores:        pulumi.StringMap{"keystore-m_12381_3600_0_0_0.json": cfg.RequireSecret("keystore0")},
+//		KeystorePassword: cfg.RequireSecret("keystorePassword"),
+//		FeeRecipient:     "0x0000000000000000000000000000000000000000",
+//		Graffiti:         "node_deployer",
+//		CpuLimit:         "1",
+//		MemoryLimit:      "2Gi",
+//	})
+func NewValidatorClientComponent(ctx *pulumi.Context, name string, args *ValidatorClientComponentArgs, opts ...pulumi.ResourceOption) (*ValidatorClientComponent, error) {
+	if args == nil {
+		args = &ValidatorClientComponentArgs{}
+	}
+
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+
+	if args.BeaconNodeUrl == nil {
+		opts = append(opts, pulumi.DependsOn([]pulumi.Resource{args.BeaconNode}))
+	}
+
+	driver, _ := LookupValidatorClient(args.Client)
+	component := &ValidatorClientComponent{}
+	err := ctx.RegisterComponentResource(fmt.Sprintf("custom:component:ValidatorClient:%s", driver.Client()), name, component, opts...)
+	if err != nil {
+		return nil, err
+	}
+	component.MetricsUrl = pulumi.String("").ToStringOutput()
+
+	switch args.DeploymentType {
+	case Source:
+		err = deploySource(ctx, component, driver, args)
+	case Kubernetes:
+		err = deployKubernetes(ctx, component, driver, args)
+	}
+	if err != nil {
+		ctx.Log.Error(fmt.Sprintf("Error creating %s validator client", driver.Client()), nil)
+		return nil, err
+	}
+
+	if err := ctx.RegisterResourceOutputs(component, pulumi.Map{
+		"metricsUrl": component.MetricsUrl,
+	}); err != nil {
+		return nil, err
+	}
+
+	return component, nil
+}
+
+// beaconNodeUrl returns the beacon api the validator client connects to.
+func beaconNodeUrl(args *ValidatorClientComponentArgs) pulumi.StringOutput {
+	if args.BeaconNodeUrl != nil {
+		return args.BeaconNodeUrl.ToStringOutput()
+	}
+	return args.BeaconNode.BeaconApiUrl
+}
+
+// web3SignerUrl returns the remote signer, or an empty string without one.
+func web3SignerUrl(args *ValidatorClientComponentArgs) pulumi.StringOutput {
+	if args.Web3SignerUrl != nil {
+		return args.Web3SignerUrl.ToStringOutput()
+	}
+	return pulumi.String("").ToStringOutput()
+}
+
+// keystoreFiles returns the keystore files with a password file per keystore
+// and a shared password.txt, the layout every driver reads from.
+func keystoreFiles(args *ValidatorClientComponentArgs) pulumi.StringMap {
+	files := pulumi.StringMap{"password.txt": args.KeystorePassword}
+	for name, keystore := range args.Keystores {
+		files[name] = keystore
+		files[strings.TrimSuffix(name, ".json")+".txt"] = args.KeystorePassword
+	}
+	return files
+}
@@ -0,0 +1,202 @@
+package validatorClient_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rswanson/node_deployer/internal/testutil"
+	"github.com/rswanson/node_deployer/validatorClient"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+)
+
+// kubernetesArgs returns valid args to deploy client to kubernetes.
+func kubernetesArgs(client string) *validatorClient.ValidatorClientComponentArgs {
+	return &validatorClient.ValidatorClientComponentArgs{
+		Client:           client,
+		Network:          "holesky",
+		DeploymentType:   "kubernetes",
+		BeaconNodeUrl:    pulumi.String("http://beacon:5052"),
+		Keystores:        pulumi.StringMap{"keystore-m_12381_3600_0_0_0.json": pulumi.String("{}")},
+		KeystorePassword: pulumi.String("testPassword"),
+		FeeRecipient:     "0x0000000000000000000000000000000000000001",
+		Graffiti:         "node_deployer",
+		CpuLimit:         "1",
+		MemoryLimit:      "1Gi",
+	}
+}
+
+func TestValidatorClientComponent(t *testing.T) {
+	for _, test := range []struct {
+		client string
+		// beaconFlag connects the client to the beacon node, signerFlag to the remote signer
+		beaconFlag   string
+		signerFlag   string
+		graffitiFlag string
+		// initContainers arrange or import the keystores, signerInitContainers the remote keys
+		initContainers       []string
+		signerInitContainers []string
+	}{
+		{"lighthouse", "http://beacon:5052", "--init-slashing-protection", "--graffiti", []string{"keystore-import"}, []string{"keystore-layout"}},
+		{"teku", "--beacon-node-api-endpoint=http://beacon:5052", "--validators-external-signer-url=http://web3signer:9000", "--validators-graffiti", nil, nil},
+		{"prysm", "--beacon-rest-api-provider=http://beacon:5052", "--validators-external-signer-url=http://web3signer:9000", "--graffiti", []string{"keystore-import"}, nil},
+		{"lodestar", "--beaconNodes=http://beacon:5052", "--externalSigner.url=http://web3signer:9000", "--graffiti", nil, nil},
+		{"nimbus", "--beacon-node=http://beacon:5052", "--web3-signer-url=http://web3signer:9000", "--graffiti", []string{"keystore-layout"}, nil},
+	} {
+		t.Run(test.client, func(t *testing.T) {
+			m := testutil.NewRecordingMocks()
+			err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+				validator, err := validatorClient.NewValidatorClientComponent(ctx, "testValidatorClient", kubernetesArgs(test.client))
+				assert.NoError(t, err, "Expected to not receive an error")
+
+				done := make(chan struct{})
+				validator.MetricsUrl.ApplyT(func(url string) error {
+					assert.Contains(t, url, test.client+"-validator-metrics-service.default.svc.cluster.local")
+					close(done)
+					return nil
+				})
+				<-done
+
+				return nil
+			}, pulumi.WithMocks("project", "stack", m))
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			prefix := test.client + "-validator"
+			podSpec := testutil.PodSpec(m.Inputs[prefix+"-set"])
+			container := testutil.Containers(podSpec)[0]
+			flags := testutil.Flags(container)
+			assert.Contains(t, flags, test.beaconFlag)
+			assert.NotContains(t, flags, test.signerFlag)
+			assert.Contains(t, strings.Join(flags, " "), "0x0000000000000000000000000000000000000001", "Expected the fee recipient to be set")
+			assert.Contains(t, flags, test.graffitiFlag+"=node_deployer")
+
+			// the keystores secret is mounted read only next to the data volume
+			assert.Equal(t, []string{"/data", "/keystores"}, testutil.MountPaths(container))
+			assert.True(t, testutil.VolumeMounts(container)[1]["readOnly"].BoolValue(), "Expected the keystores to be read only")
+			keystores := testutil.UnwrapSecret(m.Inputs[prefix+"-keystores"]["stringData"]).ObjectValue()
+			assert.Contains(t, keystores, resource.PropertyKey("keystore-m_12381_3600_0_0_0.json"))
+			assert.Contains(t, keystores, resource.PropertyKey("keystore-m_12381_3600_0_0_0.txt"))
+			assert.Contains(t, keystores, resource.PropertyKey("password.txt"))
+
+			assert.Equal(t, test.initContainers, testutil.Names(testutil.InitContainers(podSpec)))
+		})
+
+		t.Run(test.client+"Web3Signer", func(t *testing.T) {
+			m := testutil.NewRecordingMocks()
+			err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+				args := kubernetesArgs(test.client)
+				args.Keystores, args.KeystorePassword = nil, nil
+				args.Web3SignerUrl = pulumi.String("http://web3signer:9000")
+				args.Web3SignerPublicKeys = []string{"0x" + strings.Repeat("ab", 48)}
+
+				_, err := validatorClient.NewValidatorClientComponent(ctx, "testValidatorClient", args)
+				assert.NoError(t, err, "Expected to not receive an error")
+
+				return nil
+			}, pulumi.WithMocks("project", "stack", m))
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			// the remote signer holds the keys, no keystores are created or mounted
+			prefix := test.client + "-validator"
+			assert.NotContains(t, m.Inputs, prefix+"-keystores")
+			podSpec := testutil.PodSpec(m.Inputs[prefix+"-set"])
+			container := testutil.Containers(podSpec)[0]
+			assert.Contains(t, testutil.Flags(container), test.signerFlag)
+			assert.Len(t, testutil.VolumeMounts(container), 1, "Expected only the data volume to be mounted")
+
+			assert.Equal(t, test.signerInitContainers, testutil.Names(testutil.InitContainers(podSpec)))
+			if test.signerInitContainers != nil {
+				// lighthouse is pointed at the signer per public key
+				layout := strings.Join(testutil.StringValues(testutil.InitContainers(podSpec)[0]["command"]), " ")
+				assert.Contains(t, layout, `voting_public_key: "0x`+strings.Repeat("ab", 48)+`"`)
+				assert.Contains(t, layout, `url: "http://web3signer:9000"`)
+			}
+		})
+	}
+
+	t.Run("SourceComponent", func(t *testing.T) {
+		m := testutil.NewRecordingMocks()
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			args := kubernetesArgs("lighthouse")
+			args.DeploymentType = "source"
+			args.Connection = &remote.ConnectionArgs{Host: pulumi.String("127.0.0.1")}
+
+			validator, err := validatorClient.NewValidatorClientComponent(ctx, "testValidatorClient", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			done := make(chan struct{})
+			validator.MetricsUrl.ApplyT(func(url string) error {
+				assert.Equal(t, "http://127.0.0.1:5064", url)
+				close(done)
+				return nil
+			})
+			<-done
+
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		// keystores are written through stdin, readable only by the client user
+		keystore := m.Inputs["writeKeystore-lighthouse-validator-keystore-m_12381_3600_0_0_0.json"]
+		assert.Equal(t, "cat > /data/holesky/lighthouse-validator/keystores/keystore-m_12381_3600_0_0_0.json && chmod 600 /data/holesky/lighthouse-validator/keystores/keystore-m_12381_3600_0_0_0.json", keystore["create"].StringValue())
+		assert.Equal(t, "{}", keystore["stdin"].StringValue())
+		assert.Equal(t, "sudo -u lighthouse /data/bin/lighthouse account validator import --network holesky --datadir /data/holesky/lighthouse-validator --directory /data/holesky/lighthouse-validator/keystores --password-file /data/holesky/lighthouse-validator/keystores/password.txt --reuse-password", m.Inputs["keystoreImport-lighthouse-validator"]["create"].StringValue())
+		// added keystores are imported again
+		triggers := m.Inputs["keystoreImport-lighthouse-validator"]["triggers"]
+		assert.Equal(t, []string{"keystore-m_12381_3600_0_0_0.json"}, testutil.StringValues(triggers))
+
+		// the start script runs the command and graffiti from the service environment
+		environment := m.Inputs["serviceEnvironment-validatorService-lighthouse-validator"]["stdin"].StringValue()
+		assert.Contains(t, environment, `Environment="VALIDATOR_COMMAND=/data/bin/lighthouse vc --network holesky --datadir /data/holesky/lighthouse-validator --beacon-nodes http://beacon:5052`)
+		assert.Contains(t, environment, `Environment="GRAFFITI_FLAG=--graffiti"`)
+		assert.Contains(t, environment, `Environment="GRAFFITI=node_deployer"`)
+	})
+}
+
+func TestValidatorClientComponentArgsValidate(t *testing.T) {
+	assert.NoError(t, kubernetesArgs("lighthouse").Validate(), "Expected valid args to pass validation")
+
+	err := (&validatorClient.ValidatorClientComponentArgs{
+		Client:         "grandine",
+		Network:        "testNetwork",
+		DeploymentType: "testDeploymentType",
+	}).Validate()
+	assert.ErrorContains(t, err, `Client: unknown validator client "grandine"`)
+	assert.ErrorContains(t, err, `Network: unknown network "testNetwork"`)
+	assert.ErrorContains(t, err, `DeploymentType: unknown deployment type "testDeploymentType"`)
+	assert.ErrorContains(t, err, "BeaconNodeUrl: required when BeaconNode is not set")
+	assert.ErrorContains(t, err, "Keystores: required when KeystoresSecretName is not set")
+	assert.ErrorContains(t, err, "KeystorePassword: required when KeystoresSecretName is not set")
+
+	args := kubernetesArgs("teku")
+	args.FeeRecipient = "0x1234"
+	args.Keystores["../keystore.json"] = pulumi.String("{}")
+	err = args.Validate()
+	assert.ErrorContains(t, err, `FeeRecipient: "0x1234" is not a 0x prefixed address`)
+	assert.ErrorContains(t, err, `Keystores: "../keystore.json" is not a keystore file name`)
+
+	args = kubernetesArgs("prysm")
+	args.Keystores, args.KeystorePassword = nil, nil
+	args.KeystoresSecretName = "validator-keys"
+	assert.NoError(t, args.Validate(), "Expected an existing secret to replace the keystores")
+	args.DeploymentType = "source"
+	args.Connection = &remote.ConnectionArgs{}
+	assert.ErrorContains(t, args.Validate(), "KeystoresSecretName: only supported for kubernetes deployments")
+
+	// the mounted secret would silently replace the keystores
+	args = kubernetesArgs("prysm")
+	args.KeystoresSecretName = "validator-keys"
+	assert.ErrorContains(t, args.Validate(), "KeystoresSecretName: cannot be combined with Keystores or KeystorePassword")
+
+	args = kubernetesArgs("lighthouse")
+	args.Web3SignerUrl = pulumi.String("http://web3signer:9000")
+	args.Web3SignerPublicKeys = []string{"0x1234"}
+	err = args.Validate()
+	assert.ErrorContains(t, err, "Web3SignerUrl: cannot be combined with Keystores, KeystorePassword or KeystoresSecretName")
+	assert.ErrorContains(t, err, `Web3SignerPublicKeys: "0x1234" is not a 0x prefixed public key`)
+	args.Keystores, args.KeystorePassword, args.Web3SignerPublicKeys = nil, nil, nil
+	assert.ErrorContains(t, args.Validate(), "Web3SignerPublicKeys: required for lighthouse with a remote signer")
+}
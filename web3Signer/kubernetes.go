@@ -0,0 +1,338 @@
+package web3Signer
+
+import (
+	"fmt"
+
+	appsv1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/apps/v1"
+	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
+	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+const (
+	defaultImage         = "consensys/web3signer:latest"
+	defaultPostgresImage = "postgres:16"
+	// flywayImage applies the migrations shipped with web3signer to the database.
+	flywayImage = "flyway/flyway:10"
+	// keystoresMountPath is where the keystores secret is mounted in the signer pod.
+	keystoresMountPath = "/keystores"
+	// migrationsMountPath is where the migrations are shared between the init containers.
+	migrationsMountPath = "/migrations"
+)
+
+// deployKubernetes runs web3signer as a Deployment next to a postgres
+// StatefulSet holding the slashing protection database. Init containers apply
+// the database migrations before web3signer starts.
+func deployKubernetes(ctx *pulumi.Context, component *Web3SignerComponent, args *Web3SignerComponentArgs, password pulumi.StringOutput) error {
+	prefix := resourcePrefix(args)
+	image, postgresImage := args.Image, args.PostgresImage
+	if image == "" {
+		image = defaultImage
+	}
+	if postgresImage == "" {
+		postgresImage = defaultPostgresImage
+	}
+
+	// Create a secret with the keystores and their passwords unless an existing one is used
+	keystoresSecretName := pulumi.String(args.KeystoresSecretName).ToStringOutput()
+	if args.KeystoresSecretName == "" {
+		secret, err := corev1.NewSecret(ctx, fmt.Sprintf("%s-keystores", prefix), &corev1.SecretArgs{
+			StringData: keystoreFiles(args),
+			Metadata: &metav1.ObjectMetaArgs{
+				Name: pulumi.Sprintf("%s-keystores", prefix),
+				Labels: pulumi.StringMap{
+					"app.kubernetes.io/name":    pulumi.Sprintf("%s-keystores", prefix),
+					"app.kubernetes.io/part-of": pulumi.String("web3signer"),
+				},
+			},
+		}, pulumi.Parent(component), pulumi.AdditionalSecretOutputs([]string{"stringData"}))
+		if err != nil {
+			return err
+		}
+		keystoresSecretName = secret.Metadata.Name().Elem()
+	}
+
+	databaseSecret, err := corev1.NewSecret(ctx, fmt.Sprintf("%s-database", prefix), &corev1.SecretArgs{
+		StringData: pulumi.StringMap{
+			"password": password,
+		},
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.Sprintf("%s-database", prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name":    pulumi.Sprintf("%s-database", prefix),
+				"app.kubernetes.io/part-of": pulumi.String("web3signer"),
+			},
+		},
+	}, pulumi.Parent(component), pulumi.AdditionalSecretOutputs([]string{"stringData"}))
+	if err != nil {
+		return err
+	}
+	passwordEnv := func(name string) corev1.EnvVarArgs {
+		return corev1.EnvVarArgs{
+			Name: pulumi.String(name),
+			ValueFrom: &corev1.EnvVarSourceArgs{
+				SecretKeyRef: &corev1.SecretKeySelectorArgs{
+					Name: databaseSecret.Metadata.Name(),
+					Key:  pulumi.String("password"),
+				},
+			},
+		}
+	}
+
+	_, err = corev1.NewPersistentVolumeClaim(ctx, fmt.Sprintf("%s-postgres-data", prefix), &corev1.PersistentVolumeClaimArgs{
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.Sprintf("%s-postgres-data", prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name":    pulumi.Sprintf("%s-postgres-data", prefix),
+				"app.kubernetes.io/part-of": pulumi.String("web3signer"),
+			},
+		},
+		Spec: &corev1.PersistentVolumeClaimSpecArgs{
+			AccessModes: pulumi.StringArray{pulumi.String("ReadWriteOnce")},
+			Resources: &corev1.VolumeResourceRequirementsArgs{
+				Requests: pulumi.StringMap{
+					"storage": pulumi.String(args.PodStorageSize),
+				},
+			},
+			StorageClassName: pulumi.String(args.PodStorageClass),
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	postgres, err := appsv1.NewStatefulSet(ctx, fmt.Sprintf("%s-postgres-set", prefix), &appsv1.StatefulSetArgs{
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.Sprintf("%s-postgres", prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name":    pulumi.Sprintf("%s-postgres-set", prefix),
+				"app.kubernetes.io/part-of": pulumi.String("web3signer"),
+			},
+		},
+		Spec: &appsv1.StatefulSetSpecArgs{
+			Replicas: pulumi.Int(1),
+			Selector: &metav1.LabelSelectorArgs{
+				MatchLabels: pulumi.StringMap{
+					"app": pulumi.Sprintf("%s-postgres", prefix),
+				},
+			},
+			Template: &corev1.PodTemplateSpecArgs{
+				Metadata: &metav1.ObjectMetaArgs{
+					Labels: pulumi.StringMap{
+						"app":                       pulumi.Sprintf("%s-postgres", prefix),
+						"app.kubernetes.io/name":    pulumi.Sprintf("%s-postgres", prefix),
+						"app.kubernetes.io/part-of": pulumi.String("web3signer"),
+					},
+				},
+				Spec: &corev1.PodSpecArgs{
+					Containers: corev1.ContainerArray{
+						corev1.ContainerArgs{
+							Name:  pulumi.String("postgres"),
+							Image: pulumi.String(postgresImage),
+							Env: corev1.EnvVarArray{
+								corev1.EnvVarArgs{Name: pulumi.String("POSTGRES_DB"), Value: pulumi.String(databaseName)},
+								corev1.EnvVarArgs{Name: pulumi.String("POSTGRES_USER"), Value: pulumi.String(databaseUser)},
+								corev1.EnvVarArgs{Name: pulumi.String("PGDATA"), Value: pulumi.String("/var/lib/postgresql/data/pgdata")},
+								passwordEnv("POSTGRES_PASSWORD"),
+							},
+							Ports: corev1.ContainerPortArray{
+								corev1.ContainerPortArgs{
+									ContainerPort: pulumi.Int(postgresPort),
+								},
+							},
+							VolumeMounts: corev1.VolumeMountArray{
+								corev1.VolumeMountArgs{
+									Name:      pulumi.Sprintf("%s-postgres-data", prefix),
+									MountPath: pulumi.String("/var/lib/postgresql/data"),
+								},
+							},
+						},
+					},
+					Volumes: corev1.VolumeArray{
+						corev1.VolumeArgs{
+							Name: pulumi.Sprintf("%s-postgres-data", prefix),
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSourceArgs{
+								ClaimName: pulumi.Sprintf("%s-postgres-data", prefix),
+							},
+						},
+					},
+				},
+			},
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	postgresService, err := corev1.NewService(ctx, fmt.Sprintf("%s-postgres-service", prefix), &corev1.ServiceArgs{
+		Spec: &corev1.ServiceSpecArgs{
+			Selector: pulumi.StringMap{"app": pulumi.Sprintf("%s-postgres", prefix)},
+			Type:     pulumi.String("ClusterIP"),
+			Ports: corev1.ServicePortArray{
+				corev1.ServicePortArgs{
+					Port: pulumi.Int(postgresPort),
+					Name: pulumi.String("postgres"),
+				},
+			},
+		},
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.Sprintf("%s-postgres-service", prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name":    pulumi.Sprintf("%s-postgres-service", prefix),
+				"app.kubernetes.io/part-of": pulumi.String("web3signer"),
+			},
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+	databaseHost := utils.ClusterServiceHost(postgresService)
+
+	// the migrations ship with the web3signer image and are applied with flyway
+	initContainers := corev1.ContainerArray{
+		corev1.ContainerArgs{
+			Name:    pulumi.String("copy-migrations"),
+			Image:   pulumi.String(image),
+			Command: pulumi.ToStringArray([]string{"sh", "-c", "cp -r /opt/web3signer/migrations/postgresql/. " + migrationsMountPath}),
+			VolumeMounts: corev1.VolumeMountArray{
+				corev1.VolumeMountArgs{
+					Name:      pulumi.String("migrations"),
+					MountPath: pulumi.String(migrationsMountPath),
+				},
+			},
+		},
+		corev1.ContainerArgs{
+			Name:  pulumi.String("migrate-database"),
+			Image: pulumi.String(flywayImage),
+			Args: databaseHost.ApplyT(func(host string) []string {
+				return []string{
+					"migrate",
+					fmt.Sprintf("-url=jdbc:postgresql://%s:%d/%s", host, postgresPort, databaseName),
+					"-user=" + databaseUser,
+					"-connectRetries=60",
+					"-locations=filesystem:" + migrationsMountPath,
+				}
+			}).(pulumi.StringArrayOutput),
+			Env: corev1.EnvVarArray{passwordEnv("FLYWAY_PASSWORD")},
+			VolumeMounts: corev1.VolumeMountArray{
+				corev1.VolumeMountArgs{
+					Name:      pulumi.String("migrations"),
+					MountPath: pulumi.String(migrationsMountPath),
+				},
+			},
+		},
+	}
+
+	_, err = appsv1.NewDeployment(ctx, fmt.Sprintf("%s-deployment", prefix), &appsv1.DeploymentArgs{
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.String(prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name":    pulumi.Sprintf("%s-deployment", prefix),
+				"app.kubernetes.io/part-of": pulumi.String("web3signer"),
+			},
+		},
+		Spec: &appsv1.DeploymentSpecArgs{
+			Replicas: pulumi.Int(1),
+			Selector: &metav1.LabelSelectorArgs{
+				MatchLabels: pulumi.StringMap{
+					"app": pulumi.String(prefix),
+				},
+			},
+			Template: &corev1.PodTemplateSpecArgs{
+				Metadata: &metav1.ObjectMetaArgs{
+					Labels: pulumi.StringMap{
+						"app":                       pulumi.String(prefix),
+						"app.kubernetes.io/name":    pulumi.String(prefix),
+						"app.kubernetes.io/part-of": pulumi.String("web3signer"),
+					},
+				},
+				Spec: &corev1.PodSpecArgs{
+					InitContainers: initContainers,
+					Containers: corev1.ContainerArray{
+						corev1.ContainerArgs{
+							Name:  pulumi.String(prefix),
+							Image: pulumi.String(image),
+							Args: databaseHost.ApplyT(func(host string) []string {
+								return Flags(args.Network, keystoresMountPath, host)
+							}).(pulumi.StringArrayOutput),
+							Env: corev1.EnvVarArray{passwordEnv("WEB3SIGNER_ETH2_SLASHING_PROTECTION_DB_PASSWORD")},
+							Ports: corev1.ContainerPortArray{
+								corev1.ContainerPortArgs{
+									ContainerPort: pulumi.Int(HttpPort),
+								},
+								corev1.ContainerPortArgs{
+									ContainerPort: pulumi.Int(MetricsPort),
+								},
+							},
+							VolumeMounts: corev1.VolumeMountArray{
+								corev1.VolumeMountArgs{
+									Name:      pulumi.Sprintf("%s-keystores", prefix),
+									MountPath: pulumi.String(keystoresMountPath),
+									ReadOnly:  pulumi.Bool(true),
+								},
+							},
+							Resources: &corev1.ResourceRequirementsArgs{
+								Limits: pulumi.StringMap{
+									"cpu":    pulumi.String(args.CpuLimit),
+									"memory": pulumi.String(args.MemoryLimit),
+								},
+								Requests: pulumi.StringMap{
+									"cpu":    pulumi.String(args.CpuRequest),
+									"memory": pulumi.String(args.MemoryRequest),
+								},
+							},
+						},
+					},
+					DnsPolicy: pulumi.String("ClusterFirst"),
+					Volumes: corev1.VolumeArray{
+						corev1.VolumeArgs{
+							Name: pulumi.Sprintf("%s-keystores", prefix),
+							Secret: &corev1.SecretVolumeSourceArgs{
+								SecretName: keystoresSecretName,
+							},
+						},
+						corev1.VolumeArgs{
+							Name:     pulumi.String("migrations"),
+							EmptyDir: &corev1.EmptyDirVolumeSourceArgs{},
+						},
+					},
+				},
+			},
+		},
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{postgres}))
+	if err != nil {
+		return err
+	}
+
+	service, err := corev1.NewService(ctx, fmt.Sprintf("%s-service", prefix), &corev1.ServiceArgs{
+		Spec: &corev1.ServiceSpecArgs{
+			Selector: pulumi.StringMap{"app": pulumi.String(prefix)},
+			Type:     pulumi.String("ClusterIP"),
+			Ports: corev1.ServicePortArray{
+				corev1.ServicePortArgs{
+					Port: pulumi.Int(HttpPort),
+					Name: pulumi.String("http"),
+				},
+				corev1.ServicePortArgs{
+					Port: pulumi.Int(MetricsPort),
+					Name: pulumi.String("metrics"),
+				},
+			},
+		},
+		Metadata: &metav1.ObjectMetaArgs{
+			Name: pulumi.Sprintf("%s-service", prefix),
+			Labels: pulumi.StringMap{
+				"app.kubernetes.io/name":    pulumi.Sprintf("%s-service", prefix),
+				"app.kubernetes.io/part-of": pulumi.String("web3signer"),
+			},
+		},
+	}, pulumi.Parent(component))
+	if err != nil {
+		return err
+	}
+
+	component.Url = pulumi.Sprintf("http://%s:%d", utils.ClusterServiceHost(service), HttpPort)
+	component.MetricsUrl = pulumi.Sprintf("http://%s:%d", utils.ClusterServiceHost(service), MetricsPort)
+	return nil
+}
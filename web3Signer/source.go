@@ -0,0 +1,166 @@
+package web3Signer
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+// sourceRepo is the repository and ref built when the args set none.
+var sourceRepo = utils.SourceRepo{Url: "https://github.com/Consensys/web3signer.git", Ref: "master"}
+
+// deploySource builds web3signer on the remote host next to a local postgres
+// for slashing protection, writes the keystores readable only by the
+// web3signer user and runs it as a systemd service.
+func deploySource(ctx *pulumi.Context, component *Web3SignerComponent, args *Web3SignerComponentArgs, password pulumi.StringOutput) error {
+	prefix := resourcePrefix(args)
+	repoDir := fmt.Sprintf("/data/repos/%s/web3signer", args.Network)
+	dataDir := args.DataDir
+	if dataDir == "" {
+		dataDir = fmt.Sprintf("/data/%s/web3signer", args.Network)
+	}
+	keystoresDir := path.Join(dataDir, "keystores")
+
+	dataDirs, err := remote.NewCommand(ctx, fmt.Sprintf("createDataDir-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("mkdir -p %s && chmod 700 %s", keystoresDir, keystoresDir),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error creating data directory", nil)
+		return err
+	}
+
+	installDeps, err := remote.NewCommand(ctx, fmt.Sprintf("installDependencies-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.String("sudo apt update && sudo apt install -y openjdk-21-jdk postgresql && sudo systemctl enable --now postgresql"),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error installing dependencies", nil)
+		return err
+	}
+
+	// the password is passed through stdin so it never shows up in a command line,
+	// quotes in it are doubled to keep it a single sql string literal
+	database, err := remote.NewCommand(ctx, fmt.Sprintf("createDatabase-%s", prefix), &remote.CommandArgs{
+		Create: pulumi.String("sudo -u postgres psql -v ON_ERROR_STOP=1"),
+		Stdin: password.ApplyT(func(password string) string {
+			literal := strings.ReplaceAll(password, "'", "''")
+			return fmt.Sprintf("CREATE USER %s WITH PASSWORD '%s';\nCREATE DATABASE %s OWNER %s;\n", databaseUser, literal, databaseName, databaseUser)
+		}).(pulumi.StringOutput),
+		Delete:     pulumi.Sprintf("sudo -u postgres psql -c 'DROP DATABASE IF EXISTS %s' -c 'DROP USER IF EXISTS %s'", databaseName, databaseUser),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{installDeps}))
+	if err != nil {
+		ctx.Log.Error("Error creating database", nil)
+		return err
+	}
+
+	// clone repo
+	repo, err := remote.NewCommand(ctx, fmt.Sprintf("cloneRepo-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.String(utils.SourceRepo{Url: args.SourceRepoUrl, Ref: args.SourceRef}.WithDefaults(sourceRepo).CloneCommand(repoDir)),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error cloning repo", nil)
+		return err
+	}
+
+	build, err := remote.NewCommand(ctx, fmt.Sprintf("buildWeb3Signer-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("cd %s && ./gradlew installDist", repoDir),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{repo, installDeps}))
+	if err != nil {
+		ctx.Log.Error("Error building web3signer", nil)
+		return err
+	}
+
+	// apply the migrations in version order as the database owner
+	migrations := path.Join(repoDir, "slashing-protection/src/main/resources/migrations/postgresql")
+	migrate, err := remote.NewCommand(ctx, fmt.Sprintf("migrateDatabase-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("for migration in $(ls %s/*.sql | sort -V); do sudo -u postgres psql -v ON_ERROR_STOP=1 -d %s -c 'SET ROLE %s' -f $migration || exit 1; done", migrations, databaseName, databaseUser),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{repo, database}))
+	if err != nil {
+		ctx.Log.Error("Error migrating database", nil)
+		return err
+	}
+
+	// write every keystore and password file through stdin so they never show up in a command line
+	files := keystoreFiles(args)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	keystoreDeps := []pulumi.Resource{dataDirs}
+	for _, name := range names {
+		file := path.Join(keystoresDir, name)
+		keystore, err := remote.NewCommand(ctx, fmt.Sprintf("writeKeystore-%s-%s", prefix, name), &remote.CommandArgs{
+			Create:     pulumi.Sprintf("cat > %s && chmod 600 %s", file, file),
+			Delete:     pulumi.Sprintf("rm -f %s", file),
+			Stdin:      files[name],
+			Connection: args.Connection,
+		}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{dataDirs}))
+		if err != nil {
+			ctx.Log.Error("Error writing keystore "+name, nil)
+			return err
+		}
+		keystoreDeps = append(keystoreDeps, keystore)
+	}
+
+	owned, err := remote.NewCommand(ctx, fmt.Sprintf("setDataDirGroupPermissions-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.Sprintf("chown -R web3signer:web3signer %s", dataDir),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn(keystoreDeps))
+	if err != nil {
+		ctx.Log.Error("Error setting group permissions", nil)
+		return err
+	}
+
+	// copy start script
+	startScript, err := remote.NewCopyFile(ctx, fmt.Sprintf("copyStartScript-%s", prefix), &remote.CopyFileArgs{
+		LocalPath:  pulumi.String("scripts/start_web3signer.sh"),
+		RemotePath: pulumi.String("/data/scripts/start_web3signer.sh"),
+		Connection: args.Connection,
+	}, pulumi.Parent(component))
+	if err != nil {
+		ctx.Log.Error("Error copying start script", nil)
+		return err
+	}
+
+	// script permissions
+	scriptPerms, err := remote.NewCommand(ctx, fmt.Sprintf("scriptPermissions-%s", prefix), &remote.CommandArgs{
+		Create:     pulumi.String("chmod +x /data/scripts/start_web3signer.sh && chown web3signer:web3signer /data/scripts/start_web3signer.sh"),
+		Connection: args.Connection,
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{startScript}))
+	if err != nil {
+		ctx.Log.Error("Error setting script permissions", nil)
+		return err
+	}
+
+	// create service, the start script reads its flags and the database password from the service environment
+	_, err = utils.NewServiceDefinitionComponent(ctx, fmt.Sprintf("web3SignerService-%s", prefix), &utils.ServiceComponentArgs{
+		Connection:  args.Connection,
+		ServiceType: "web3signer",
+		Network:     args.Network,
+		Environment: pulumi.StringMap{
+			"WEB3SIGNER_BINARY": pulumi.Sprintf("%s/build/install/web3signer/bin/web3signer", repoDir),
+			"WEB3SIGNER_FLAGS":  pulumi.String(strings.Join(Flags(args.Network, keystoresDir, "127.0.0.1"), " ")),
+			"WEB3SIGNER_ETH2_SLASHING_PROTECTION_DB_PASSWORD": password,
+		},
+	}, pulumi.Parent(component), pulumi.DependsOn([]pulumi.Resource{build, migrate, owned, scriptPerms}))
+	if err != nil {
+		ctx.Log.Error("Error creating web3signer service", nil)
+		return err
+	}
+
+	host := args.Connection.Host
+	component.Url = pulumi.Sprintf("http://%s:%d", host, HttpPort)
+	component.MetricsUrl = pulumi.Sprintf("http://%s:%d", host, MetricsPort)
+	return nil
+}
@@ -0,0 +1,255 @@
+package web3Signer
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi-random/sdk/v4/go/random"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/rswanson/node_deployer/utils"
+)
+
+type Web3SignerComponent struct {
+	pulumi.ResourceState
+
+	// Url is the signing api validator clients use as their remote signer.
+	Url pulumi.StringOutput `pulumi:"url"`
+	// MetricsUrl is the prometheus metrics endpoint.
+	MetricsUrl pulumi.StringOutput `pulumi:"metricsUrl"`
+}
+
+type Web3SignerComponentArgs struct {
+	Connection     *remote.ConnectionArgs
+	Network        string
+	DeploymentType string
+	DataDir        string
+	Name           string
+	// Keystores maps file names like keystore-m_12381_3600_0_0_0.json to the
+	// EIP-2335 keystores, all encrypted with KeystorePassword. Both should be
+	// pulumi secrets, they end up in a kubernetes Secret or in files only
+	// readable by the web3signer user on the host.
+	Keystores        pulumi.StringMap
+	KeystorePassword pulumi.StringInput
+	// KeystoresSecretName names an existing kubernetes Secret to mount instead
+	// of Keystores. For every keystore-x.json it has to hold a keystore-x.txt
+	// with its password.
+	KeystoresSecretName string
+	// DatabasePassword is the password of the slashing protection database.
+	// When nil a password is generated and kept in the stack state.
+	DatabasePassword pulumi.StringInput
+	Image            string
+	PostgresImage    string
+	PodStorageClass  string
+	PodStorageSize   string
+	CpuLimit         string
+	MemoryLimit      string
+	CpuRequest       string
+	MemoryRequest    string
+	// SourceRepoUrl and SourceRef select the repository and the branch, tag or
+	// commit source deployments are built from, Consensys/web3signer's master
+	// branch by default.
+	SourceRepoUrl string
+	SourceRef     string
+}
+
+const (
+	Source      = "source"
+	Kubernetes  = "kubernetes"
+	Mainnet     = "mainnet"
+	Sepolia     = "sepolia"
+	Holesky     = "holesky"
+	Hoodi       = "hoodi"
+	HttpPort    = 9000
+	MetricsPort = 9001
+)
+
+const (
+	// databaseName and databaseUser name the slashing protection database and its owner.
+	databaseName = "web3signer"
+	databaseUser = "web3signer"
+	postgresPort = 5432
+)
+
+var (
+	networks        = []string{Mainnet, Sepolia, Holesky, Hoodi}
+	deploymentTypes = []string{Source, Kubernetes}
+)
+
+// Validate checks the args for unknown networks and deployment types as well
+// as the keystores and settings required by the chosen deployment type. All
+// problems found are returned together as utils.ValidationErrors.
+func (args *Web3SignerComponentArgs) Validate() error {
+	var errs utils.ValidationErrors
+
+	if !slices.Contains(networks, args.Network) {
+		errs.Add("Network", "unknown network %q, expected one of %s", args.Network, strings.Join(networks, ", "))
+	}
+	if !slices.Contains(deploymentTypes, args.DeploymentType) {
+		errs.Add("DeploymentType", "unknown deployment type %q, expected one of %s", args.DeploymentType, strings.Join(deploymentTypes, ", "))
+	}
+
+	if args.KeystoresSecretName != "" {
+		if len(args.Keystores) > 0 || args.KeystorePassword != nil {
+			errs.Add("KeystoresSecretName", "cannot be combined with Keystores or KeystorePassword")
+		}
+	} else {
+		if len(args.Keystores) == 0 {
+			errs.Add("Keystores", "required when KeystoresSecretName is not set")
+		}
+		if args.KeystorePassword == nil {
+			errs.Add("KeystorePassword", "required when KeystoresSecretName is not set")
+		}
+	}
+	for name := range args.Keystores {
+		if !strings.HasSuffix(name, ".json") || strings.ContainsAny(name, "/ ") {
+			errs.Add("Keystores", "%q is not a keystore file name", name)
+		}
+	}
+
+	switch args.DeploymentType {
+	case Source:
+		if args.Connection == nil {
+			errs.Add("Connection", "required for %s deployments", args.DeploymentType)
+		}
+		if args.KeystoresSecretName != "" {
+			errs.Add("KeystoresSecretName", "only supported for %s deployments", Kubernetes)
+		}
+	case Kubernetes:
+		if args.CpuLimit == "" {
+			errs.Add("CpuLimit", "required for %s deployments", args.DeploymentType)
+		}
+		if args.MemoryLimit == "" {
+			errs.Add("MemoryLimit", "required for %s deployments", args.DeploymentType)
+		}
+	}
+
+	return errs.Err()
+}
+
+// NewWeb3SignerComponent runs Web3Signer with the given keystores and a
+// postgres slashing protection database, so validator clients can sign
+// through it instead of holding the keys. Source deployments build Web3Signer
+// on the ssh host next to a local postgres, kubernetes deployments run it as
+// a Deployment next to a postgres StatefulSet. The database schema is migrated
+// before Web3Signer starts. The args are validated before any resources are
+// registered.
+//
+// Example usage:
+//
+//	signer, err := web3Signer.NewWeb3SignerComponent(ctx, "holeskySigner", &web3Signer.Web3SignerComponentArgs{
+//		Network:          "holesky",
+//		DeploymentType:   "kubernetes",
+//		Keystores:        pulumi.StringMap{"keystore-m_12381_3600_0_0_0.json": cfg.RequireSecret("keystore0")},
+//		KeystorePassword: cfg.RequireSecret("keystorePassword"),
+//		PodStorageSize:   "10Gi",
+//		CpuLimit:         "1",
+//		MemoryLimit:      "2Gi",
+//	})
+func NewWeb3SignerComponent(ctx *pulumi.Context, name string, args *Web3SignerComponentArgs, opts ...pulumi.ResourceOption) (*Web3SignerComponent, error) {
+	if args == nil {
+		args = &Web3SignerComponentArgs{}
+	}
+
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+
+	component := &Web3SignerComponent{}
+	err := ctx.RegisterComponentResource("custom:component:Web3Signer", name, component, opts...)
+	if err != nil {
+		return nil, err
+	}
+	component.Url = pulumi.String("").ToStringOutput()
+	component.MetricsUrl = pulumi.String("").ToStringOutput()
+
+	password, err := databasePassword(ctx, component, args)
+	if err != nil {
+		ctx.Log.Error("Error generating database password", nil)
+		return nil, err
+	}
+
+	switch args.DeploymentType {
+	case Source:
+		err = deploySource(ctx, component, args, password)
+	case Kubernetes:
+		err = deployKubernetes(ctx, component, args, password)
+	}
+	if err != nil {
+		ctx.Log.Error("Error creating web3signer", nil)
+		return nil, err
+	}
+
+	if err := ctx.RegisterResourceOutputs(component, pulumi.Map{
+		"url":        component.Url,
+		"metricsUrl": component.MetricsUrl,
+	}); err != nil {
+		return nil, err
+	}
+
+	return component, nil
+}
+
+// Flags renders the web3signer arguments loading the keystores and their
+// passwords from keystoresDir and keeping slashing protection data in the
+// postgres database at databaseHost. The database password is read from
+// WEB3SIGNER_ETH2_SLASHING_PROTECTION_DB_PASSWORD.
+func Flags(network string, keystoresDir string, databaseHost string) []string {
+	return []string{
+		"--http-listen-host=0.0.0.0",
+		"--http-listen-port=" + strconv.Itoa(HttpPort),
+		"--http-host-allowlist=*",
+		"--metrics-enabled=true",
+		"--metrics-host=0.0.0.0",
+		"--metrics-port=" + strconv.Itoa(MetricsPort),
+		"--metrics-host-allowlist=*",
+		"eth2",
+		"--network=" + network,
+		"--keystores-path=" + keystoresDir,
+		"--keystores-passwords-path=" + keystoresDir,
+		"--key-manager-api-enabled=true",
+		"--slashing-protection-enabled=true",
+		fmt.Sprintf("--slashing-protection-db-url=jdbc:postgresql://%s:%d/%s", databaseHost, postgresPort, databaseName),
+		"--slashing-protection-db-username=" + databaseUser,
+	}
+}
+
+// databasePassword returns the database password of args, generating a new
+// one when none was provided. The generated password is kept in the stack
+// state so it stays the same across updates.
+func databasePassword(ctx *pulumi.Context, component *Web3SignerComponent, args *Web3SignerComponentArgs) (pulumi.StringOutput, error) {
+	if args.DatabasePassword != nil {
+		return pulumi.ToSecret(args.DatabasePassword).(pulumi.StringOutput), nil
+	}
+
+	// alphanumeric only, it ends up in sql, jdbc settings and unit files
+	password, err := random.NewRandomPassword(ctx, fmt.Sprintf("%s-databasePassword", resourcePrefix(args)), &random.RandomPasswordArgs{
+		Length:  pulumi.Int(32),
+		Special: pulumi.Bool(false),
+	}, pulumi.Parent(component), pulumi.AdditionalSecretOutputs([]string{"result"}))
+	if err != nil {
+		return pulumi.StringOutput{}, err
+	}
+	return pulumi.ToSecret(password.Result).(pulumi.StringOutput), nil
+}
+
+// keystoreFiles returns the keystore files with a password file per keystore,
+// the layout web3signer bulk loads keystores from.
+func keystoreFiles(args *Web3SignerComponentArgs) pulumi.StringMap {
+	files := pulumi.StringMap{}
+	for name, keystore := range args.Keystores {
+		files[name] = keystore
+		files[strings.TrimSuffix(name, ".json")+".txt"] = args.KeystorePassword
+	}
+	return files
+}
+
+// resourcePrefix returns the prefix of the kubernetes objects and remote resources.
+func resourcePrefix(args *Web3SignerComponentArgs) string {
+	if args.Name != "" {
+		return args.Name
+	}
+	return "web3signer"
+}
@@ -0,0 +1,167 @@
+package web3Signer_test
+
+import (
+	"testing"
+
+	"github.com/rswanson/node_deployer/internal/testutil"
+	"github.com/rswanson/node_deployer/web3Signer"
+
+	"github.com/pulumi/pulumi-command/sdk/go/command/remote"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/stretchr/testify/assert"
+)
+
+// kubernetesArgs returns valid args to deploy web3signer to kubernetes.
+func kubernetesArgs() *web3Signer.Web3SignerComponentArgs {
+	return &web3Signer.Web3SignerComponentArgs{
+		Network:          "holesky",
+		DeploymentType:   "kubernetes",
+		Keystores:        pulumi.StringMap{"keystore-m_12381_3600_0_0_0.json": pulumi.String("{}")},
+		KeystorePassword: pulumi.String("testPassword"),
+		DatabasePassword: pulumi.String("testDatabasePassword"),
+		PodStorageSize:   "10Gi",
+		CpuLimit:         "1",
+		MemoryLimit:      "1Gi",
+	}
+}
+
+func TestWeb3SignerComponent(t *testing.T) {
+	t.Run("KubernetesComponent", func(t *testing.T) {
+		m := testutil.NewRecordingMocks()
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			signer, err := web3Signer.NewWeb3SignerComponent(ctx, "testWeb3Signer", kubernetesArgs())
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			done := make(chan struct{})
+			pulumi.All(signer.Url, signer.MetricsUrl).ApplyT(func(urls []interface{}) error {
+				assert.Equal(t, "http://web3signer-service.default.svc.cluster.local:9000", urls[0])
+				assert.Equal(t, "http://web3signer-service.default.svc.cluster.local:9001", urls[1])
+				close(done)
+				return nil
+			})
+			<-done
+
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		// the database password is only handed to the pods through a secret
+		database := testutil.UnwrapSecret(m.Inputs["web3signer-database"]["stringData"]).ObjectValue()
+		assert.Equal(t, "testDatabasePassword", testutil.UnwrapSecret(database["password"]).StringValue())
+		databaseUrl := "jdbc:postgresql://web3signer-postgres-service.default.svc.cluster.local:5432/web3signer"
+
+		podSpec := testutil.PodSpec(m.Inputs["web3signer-deployment"])
+		initContainers := testutil.InitContainers(podSpec)
+		assert.Equal(t, "copy-migrations", initContainers[0]["name"].StringValue())
+		migrate := initContainers[1]
+		assert.Equal(t, "flyway/flyway:10", migrate["image"].StringValue())
+		assert.Contains(t, testutil.Flags(migrate), "-url="+databaseUrl)
+		assert.Equal(t, "FLYWAY_PASSWORD", migrate["env"].ArrayValue()[0].ObjectValue()["name"].StringValue())
+
+		container := testutil.Containers(podSpec)[0]
+		flags := testutil.Flags(container)
+		assert.Contains(t, flags, "--keystores-path=/keystores")
+		assert.Contains(t, flags, "--slashing-protection-db-url="+databaseUrl)
+		passwordEnv := container["env"].ArrayValue()[0].ObjectValue()
+		assert.Equal(t, "WEB3SIGNER_ETH2_SLASHING_PROTECTION_DB_PASSWORD", passwordEnv["name"].StringValue())
+		assert.Equal(t, "web3signer-database", passwordEnv["valueFrom"].ObjectValue()["secretKeyRef"].ObjectValue()["name"].StringValue())
+
+		// the keystores secret is mounted read only
+		mount := testutil.VolumeMounts(container)[0]
+		assert.Equal(t, "/keystores", mount["mountPath"].StringValue())
+		assert.True(t, mount["readOnly"].BoolValue(), "Expected the keystores to be read only")
+		keystores := testutil.UnwrapSecret(m.Inputs["web3signer-keystores"]["stringData"]).ObjectValue()
+		assert.Contains(t, keystores, resource.PropertyKey("keystore-m_12381_3600_0_0_0.json"))
+		assert.Contains(t, keystores, resource.PropertyKey("keystore-m_12381_3600_0_0_0.txt"))
+	})
+
+	t.Run("SourceComponent", func(t *testing.T) {
+		m := testutil.NewRecordingMocks()
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			args := kubernetesArgs()
+			args.DeploymentType = "source"
+			args.Connection = &remote.ConnectionArgs{Host: pulumi.String("127.0.0.1")}
+			args.DatabasePassword = nil
+
+			signer, err := web3Signer.NewWeb3SignerComponent(ctx, "testWeb3Signer", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+
+			done := make(chan struct{})
+			signer.Url.ApplyT(func(url string) error {
+				assert.Equal(t, "http://127.0.0.1:9000", url)
+				close(done)
+				return nil
+			})
+			<-done
+
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		// without a password one is generated, and passed to psql through stdin
+		assert.Equal(t, 32.0, m.Inputs["web3signer-databasePassword"]["length"].NumberValue())
+		assert.False(t, m.Inputs["web3signer-databasePassword"]["special"].BoolValue(), "Expected an alphanumeric password")
+		assert.Equal(t, "sudo -u postgres psql -v ON_ERROR_STOP=1", m.Inputs["createDatabase-web3signer"]["create"].StringValue())
+
+		keystore := m.Inputs["writeKeystore-web3signer-keystore-m_12381_3600_0_0_0.json"]
+		assert.Equal(t, "cat > /data/holesky/web3signer/keystores/keystore-m_12381_3600_0_0_0.json && chmod 600 /data/holesky/web3signer/keystores/keystore-m_12381_3600_0_0_0.json", keystore["create"].StringValue())
+		assert.Equal(t, "{}", keystore["stdin"].StringValue())
+	})
+
+	t.Run("SourceEnvironment", func(t *testing.T) {
+		m := testutil.NewRecordingMocks()
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			args := kubernetesArgs()
+			args.DeploymentType = "source"
+			args.Connection = &remote.ConnectionArgs{Host: pulumi.String("127.0.0.1")}
+			args.DatabasePassword = pulumi.String("test'DatabasePassword")
+
+			_, err := web3Signer.NewWeb3SignerComponent(ctx, "testWeb3Signer", args)
+			assert.NoError(t, err, "Expected to not receive an error")
+			return nil
+		}, pulumi.WithMocks("project", "stack", m))
+		assert.NoError(t, err, "Expected to not receive an error")
+
+		database := m.Inputs["createDatabase-web3signer"]["stdin"]
+		assert.True(t, database.IsSecret(), "Expected the database password to stay secret")
+		assert.Contains(t, testutil.UnwrapSecret(database).StringValue(), "CREATE USER web3signer WITH PASSWORD 'test''DatabasePassword';")
+
+		// the start script reads the flags and the database password from the service environment
+		environment := testutil.UnwrapSecret(m.Inputs["serviceEnvironment-web3SignerService-web3signer"]["stdin"]).StringValue()
+		assert.Contains(t, environment, `Environment="WEB3SIGNER_BINARY=/data/repos/holesky/web3signer/build/install/web3signer/bin/web3signer"`)
+		assert.Contains(t, environment, "--keystores-path=/data/holesky/web3signer/keystores")
+		assert.Contains(t, environment, "--slashing-protection-db-url=jdbc:postgresql://127.0.0.1:5432/web3signer")
+		assert.Contains(t, environment, `Environment="WEB3SIGNER_ETH2_SLASHING_PROTECTION_DB_PASSWORD=test'DatabasePassword"`)
+	})
+}
+
+func TestFlags(t *testing.T) {
+	flags := web3Signer.Flags("holesky", "/keystores", "postgres")
+	assert.Contains(t, flags, "--network=holesky")
+	assert.Contains(t, flags, "--keystores-path=/keystores")
+	assert.Contains(t, flags, "--slashing-protection-db-url=jdbc:postgresql://postgres:5432/web3signer")
+}
+
+func TestWeb3SignerComponentArgsValidate(t *testing.T) {
+	assert.NoError(t, kubernetesArgs().Validate(), "Expected valid args to pass validation")
+
+	err := (&web3Signer.Web3SignerComponentArgs{
+		Network:        "testNetwork",
+		DeploymentType: "testDeploymentType",
+	}).Validate()
+	assert.ErrorContains(t, err, `Network: unknown network "testNetwork"`)
+	assert.ErrorContains(t, err, `DeploymentType: unknown deployment type "testDeploymentType"`)
+	assert.ErrorContains(t, err, "Keystores: required when KeystoresSecretName is not set")
+	assert.ErrorContains(t, err, "KeystorePassword: required when KeystoresSecretName is not set")
+
+	args := kubernetesArgs()
+	args.Keystores, args.KeystorePassword = nil, nil
+	args.KeystoresSecretName = "signer-keys"
+	assert.NoError(t, args.Validate(), "Expected an existing secret to replace the keystores")
+	args.Keystores = kubernetesArgs().Keystores
+	assert.ErrorContains(t, args.Validate(), "KeystoresSecretName: cannot be combined with Keystores or KeystorePassword")
+	args.DeploymentType = "source"
+	args.Connection = &remote.ConnectionArgs{}
+	assert.ErrorContains(t, args.Validate(), "KeystoresSecretName: only supported for kubernetes deployments")
+}